@@ -21,3 +21,12 @@ func (arguments CommandLineArguments) Parse(executableName string) Blast {
 func (arguments CommandLineArguments) ParseWithDynamicPayload(executableName string, payloadGenerator payload.PayloadGenerator) Blast {
 	return NewDynamicPayloadArgumentsParser(payloadGenerator).Parse(executableName)
 }
+
+// ParseWithContextualPayload parses command line arguments using DynamicPayloadArgumentsParser, that generates
+// the payload of each request from its payload.RequestContext.
+func (arguments CommandLineArguments) ParseWithContextualPayload(
+	executableName string,
+	payloadGenerator payload.ContextualPayloadGenerator,
+) Blast {
+	return NewContextualPayloadArgumentsParser(payloadGenerator).Parse(executableName)
+}
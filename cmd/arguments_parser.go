@@ -1,35 +1,97 @@
 package blast
 
 import (
+	"encoding/binary"
 	"flag"
 	"fmt"
 	"github.com/SarthakMakhija/blast-core/payload"
+	"github.com/SarthakMakhija/blast-core/report"
 	"github.com/SarthakMakhija/blast-core/workers"
 	"github.com/dimiro1/banner"
+	"net"
 	"os"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 )
 
 var (
-	concurrency             = flag.Uint("c", 50, "")
+	concurrency             = flag.Uint("c", DefaultConcurrency, "")
 	connections             = flag.Uint("conn", 1, "")
 	keepConnectionsAlive    = flag.Bool("kA", false, "")
 	payloadFilePath         = flag.String("f", "", "")
-	requestsPerSecond       = flag.Float64("rps", 50, "")
-	maxDuration             = flag.Duration("z", 20*time.Second, "")
-	connectTimeout          = flag.Duration("t", 3*time.Second, "")
+	payloadFormat           = flag.String("ff", "file", "")
+	payloadSelection        = flag.String("fs", "sequential", "")
+	payloadSeed             = flag.Int64("fseed", 0, "")
+	payloadTemplate         = flag.Bool("ft", false, "")
+	payloadDataFilePath     = flag.String("fcsv", "", "")
+	requestsPerSecond       = flag.Float64("rps", DefaultRequestsPerSecond, "")
+	arrivalRate             = flag.Float64("ar", 0, "")
+	loadProfile             = flag.String("lp", "", "")
+	maxDuration             = flag.Duration("z", DefaultMaxDuration, "")
+	totalRequests           = flag.Uint("n", 0, "")
+	connectTimeout          = flag.Duration("t", DefaultConnectTimeout, "")
+	reconnectStrategy       = flag.String("rc", "none", "")
+	reconnectAttempts       = flag.Uint("rca", 0, "")
+	reconnectBackoff        = flag.Duration("rcb", 100*time.Millisecond, "")
+	reconnectMaxBackoff     = flag.Duration("rcm", 5*time.Second, "")
+	churnRequests           = flag.Uint("chn", 0, "")
+	churnLifetime           = flag.Duration("cht", 0*time.Second, "")
+	churnRate               = flag.Float64("chr", 0, "")
+	useTLS                  = flag.Bool("tls", false, "")
+	tlsCAFile               = flag.String("tlsca", "", "")
+	tlsCertFile             = flag.String("tlscert", "", "")
+	tlsKeyFile              = flag.String("tlskey", "", "")
+	tlsServerName           = flag.String("tlssni", "", "")
+	tlsInsecureSkipVerify   = flag.Bool("tlsk", false, "")
+	tlsMinVersion           = flag.String("tlsmin", "", "")
+	tlsSessionResumption    = flag.Bool("tlsr", false, "")
+	handshakeFilePath       = flag.String("hs", "", "")
+	handshakeAckDelimiter   = flag.String("hsd", "\\n", "")
+	handshakeAckPrefix      = flag.String("hsa", "", "")
+	handshakeTimeout        = flag.Duration("hst", 0*time.Second, "")
+	loadBalancing           = flag.String("lb", "roundrobin", "")
+	hashKeyOffset           = flag.Uint("lbko", 0, "")
+	hashKeyLength           = flag.Uint("lbkl", 0, "")
 	readResponses           = flag.Bool("Rr", false, "")
 	responsePayloadSize     = flag.Int64("Rrs", -1, "")
+	responseFraming         = flag.String("Rf", "fixed", "")
+	responseDelimiter       = flag.String("Rfd", "\\n", "")
 	readResponseDeadline    = flag.Duration("Rrd", 0*time.Second, "")
 	readTotalResponses      = flag.Uint("Rtr", 0, "")
 	readSuccessfulResponses = flag.Uint("Rsr", 0, "")
+	requestIdOffset         = flag.Int("Rid", -1, "")
+	validResponsePrefix     = flag.String("Rvp", "", "")
+	validResponsePattern    = flag.String("Rvr", "", "")
+	validResponseStatusByte = flag.String("Rvs", "", "")
 	cpus                    = flag.Int("cpus", runtime.GOMAXPROCS(-1), "")
+	reportFormat            = flag.String("rf", "text", "")
+	reportFilePath          = flag.String("ro", "", "")
+	reportWindow            = flag.Duration("rw", time.Second, "")
+	progressInterval        = flag.Duration("pi", 0*time.Second, "")
+	configFilePath          = flag.String("config", "", "")
+	metricsAddress          = flag.String("pm", "", "")
+	sloMaxErrorRate         = flag.Float64("Ser", -1, "")
+	sloMinThroughput        = flag.Float64("Stp", 0, "")
+	sloMaxP99Latency        = flag.Duration("Sp99", 0*time.Second, "")
+	sloMinResponseRatio     = flag.Float64("Srr", 0, "")
+	sloMaxErrorCountByType  = errorCountThresholds{}
+	compareReports          = flag.Bool("cmp", false, "")
+	throughputTolerance     = flag.Float64("cmptp", 5, "")
+	latencyTolerance        = flag.Float64("cmplt", 10, "")
+	errorsTolerance         = flag.Float64("cmpet", 0, "")
 )
 
+func init() {
+	flag.Var(sloMaxErrorCountByType, "Set", "")
+}
+
 var exitFunction = usageAndExit
 
+// fixedSizeFraming identifies the framing of the responses of fixed size.
+const fixedSizeFraming = "fixed"
+
 // ArgumentsParser supports parsing command line arguments.
 type ArgumentsParser interface {
 	Parse(executableName string) Blast
@@ -40,7 +102,7 @@ type ConstantPayloadArgumentsParser struct{}
 
 // DynamicPayloadArgumentsParser parses command line arguments with dynamic payload.
 type DynamicPayloadArgumentsParser struct {
-	payloadGenerator payload.PayloadGenerator
+	payloadGenerator payload.ContextualPayloadGenerator
 }
 
 // NewConstantPayloadArgumentsParser creates a new instance of ConstantPayloadArgumentsParser.
@@ -50,6 +112,14 @@ func NewConstantPayloadArgumentsParser() ConstantPayloadArgumentsParser {
 
 // NewDynamicPayloadArgumentsParser creates a new instance of DynamicPayloadArgumentsParser.
 func NewDynamicPayloadArgumentsParser(payloadGenerator payload.PayloadGenerator) DynamicPayloadArgumentsParser {
+	return NewContextualPayloadArgumentsParser(payload.NewPayloadGeneratorAdapter(payloadGenerator))
+}
+
+// NewContextualPayloadArgumentsParser creates a new instance of DynamicPayloadArgumentsParser that generates the
+// payload of each request from its payload.RequestContext.
+func NewContextualPayloadArgumentsParser(
+	payloadGenerator payload.ContextualPayloadGenerator,
+) DynamicPayloadArgumentsParser {
 	return DynamicPayloadArgumentsParser{
 		payloadGenerator: payloadGenerator,
 	}
@@ -58,26 +128,139 @@ func NewDynamicPayloadArgumentsParser(payloadGenerator payload.PayloadGenerator)
 // Parse parses the command line arguments.
 func (parser ConstantPayloadArgumentsParser) Parse(executableName string) Blast {
 	logo := `{{ .Title "%v" "" 0}}`
-	banner.InitString(os.Stdout, true, false, fmt.Sprintf(logo, executableName))
+	banner.InitString(os.Stderr, true, false, fmt.Sprintf(logo, executableName))
 
 	flag.Usage = func() {
 		var usage = `%v is a load generator for TCP servers which maintain persistent connections.
 
-Usage: %v [options...] <url>
+Usage: %v [options...] <url> [<url>...]
+
+The url is of the form host:port, tcp://host:port, unix:///path or udp://host:port.
+Each request is sent as a single datagram and each datagram read is a single response, if the url is udp.
+If multiple urls are specified, the load is distributed across them (-lb) and the report includes the
+metrics of each url. The weight of a url (-lb weighted or hash) is specified as ?weight=N, for example:
+tcp://node1:8080?weight=3. Default weight is 1.
 
 Options:
   -c      Number of workers to run concurrently. Default is 50.
   -f      File path containing the load payload.
+  -ff     Format of the payload file (-f). Supported formats: file, dir, lines, bundle and manifest.
+          file: the content of the file is the only payload.
+          dir: -f is a directory, the content of each file in it is a payload (files ordered by their names).
+          lines: each non-blank line (including its line feed) of the file is a payload.
+          bundle: the file is a sequence of payloads, each prefixed with its length in 4 bytes (big-endian).
+          manifest: each line of the file is of the form "weight path", the content of the file at path
+          (relative to the manifest) is a payload with the given weight. Lines starting with # are ignored.
+          Default is file.
+  -fs     Selection of the payload of each request from the payloads (-ff). Supported selections:
+          sequential (round-robin in the order of request ids), random (uniform) and weighted (in proportion to
+          the weights of the manifest, a weight of 1 for the other formats). Default is sequential.
+  -fseed  Seed of the random and weighted selections (-fs), and of the random values of the template (-ft).
+          A run with the same seed selects the same payload for each request. Default is a time-based seed,
+          different in each run, the selections and the random values are deterministic only when -fseed is
+          specified.
+  -ft     Payload file (-f) is a template, sent as-is except the actions of the form {{name args...}}:
+          {{requestId}}, {{connectionId}}, {{workerId}}, {{sequence}} (of the request on its connection),
+          {{randomInt min max}}, {{randomString length}} or {{randomString min max}}, {{uuid}},
+          {{timestamp [s|ms|us|ns|rfc3339]}}, {{counter [name] [start]}}, {{csv column}} (-fcsv),
+          {{hex bytes}}, {{base64 bytes}} and {{length u8|u16|u32|u64|u16le|u32le|u64le|ascii}}...{{end}}
+          (the length of the region up to {{end}}, followed by the region). The ids take the same
+          encoding, for example: {{requestId u64}}, the default is ascii. {{lbraces}} is a literal {{.
+          Requires -ff file. Default is false.
+  -fcsv   CSV data file (with a header) of the template (-ft), {{csv column}} is the value of the column in a
+          row of the file. The rows are used one after the other in the order of the request ids.
   -rps    Rate limit in requests per second (RPS) per worker. Default is 50.
+  -ar     Arrival rate in requests per second across all the workers. If specified, blast schedules
+          the requests at a constant arrival rate (open model) instead of throttling each worker (-rps).
+          A scheduled request is dropped if no worker is available to send it, and a request that is
+          sent later than its scheduled time is reported as late. Default is 0 (disabled).
+  -lp     Load profile is a comma separated list of stages, each of the form duration:rate[@workers] or
+          duration:startRate-endRate[@workers]. The rate is in requests per second across all the workers,
+          and changes linearly from startRate to endRate over the duration of the stage. workers is the
+          number of workers that send the requests in the stage (default is all the workers, -c).
+          The requests are scheduled like -ar, and the metrics of each stage are reported separately.
+          If specified, -rps, -ar and -z are not used. Default is no load profile.
+          Example usage: -lp 60s:0-5000,10m:5000,2m:8000@100,30s:8000-0.
   -z      Duration of blast to send requests. When duration is reached,
           application stops and exits. Default is 20 seconds.
           Example usage: -z 10s or -z 3m.
+  -n      Total number of requests to send across all the workers. If specified, blast stops once
+          the total requests have been sent and, if "Read responses" (-Rr) is true, once their responses
+          have been read or 5 seconds have elapsed. The duration (-z) still limits the run. With -ar
+          or -lp, the dropped requests do not count towards the total requests.
+          Default is 0 (no limit). Example usage: -n 10000.
   -t      Timeout for establishing connection with the target server. Default is 3 seconds.
           Also called as DialTimeout.
+  -rc     Reconnect strategy for the connections that fail at dial time or are lost during the run.
+          Supported values are:
+          none:      the connections are not re-established.
+          immediate: the connections are re-dialed without any wait between the attempts.
+          backoff:   the connections are re-dialed after an exponential backoff (-rcb, -rcm) with jitter.
+          The workers of a connection do not send requests while it is down, and the report shows the
+          reconnects and the downtime of each connection. Default is none.
+  -rca    Reconnect attempts is the maximum number of attempts to re-establish a connection each time
+          it fails. Default is 0 (unlimited).
+  -rcb    Reconnect backoff is the wait before the first reconnect attempt, if the reconnect strategy (-rc)
+          is "backoff". The wait doubles after each attempt. Default is 100ms.
+  -rcm    Reconnect max backoff is the maximum wait between the reconnect attempts, if the reconnect
+          strategy (-rc) is "backoff". Default is 5 seconds.
+  -chn    Churn the connections after the given number of requests are sent on each connection, to load
+          the connection setup and teardown paths of the target server. A churned connection is re-opened
+          and then closed. Default is 0 (disabled).
+  -cht    Churn the connections after they have been open for the given duration. Default is 0 (disabled).
+          Example usage: -cht 5s.
+  -chr    Churn the connections one after the other at the given rate of new connections per second.
+          Default is 0 (disabled). The latency and the errors of all the dials are reported.
+  -tls    Establish TLS connections with the target server (tcp or unix). The TLS handshake time is
+          reported separately from the TCP connect time. Default is false.
+  -tlsca  Path of the PEM encoded CA bundle that verifies the certificate of the target server.
+          Default is the system CA bundle. This flag is applied only if -tls is true.
+  -tlscert, -tlskey
+          Paths of the PEM encoded client certificate and key for mutual TLS (mTLS). Both must be
+          specified together. This flag is applied only if -tls is true.
+  -tlssni Server name indication (SNI). Default is the host of the target address. It must be
+          specified for a unix target. This flag is applied only if -tls is true.
+  -tlsk   Skip the verification of the certificate of the target server. Default is false.
+  -tlsmin Minimum TLS version. Supported values are 1.0, 1.1, 1.2 and 1.3. Default is 1.2.
+  -tlsr   Resume the TLS sessions when the connections are re-established (-rc) or churned
+          (-chn, -cht, -chr). Default is false.
+  -hs     Handshake file path. If specified, the content of the file is written on each connection after it is
+          established (and after the TLS handshake), and blast reads the ack before sending the requests on
+          the connection. A connection whose handshake fails is closed and reported as a dial error.
+  -hsd    Handshake ack delimiter ends the ack. Escape sequences like \n and \x00 are supported. Default is \n.
+  -hsa    Handshake ack prefix. If specified, the handshake fails if the ack does not start with the prefix.
+          Escape sequences are supported. Example usage: -hsa "+OK".
+  -hst    Handshake timeout is the maximum time for writing the handshake and reading the ack.
+          Default is the connect timeout (-t). These flags are applied only if -hs is specified.
+  -lb     Load balancing strategy, if multiple urls are specified. Supported values are:
+          roundrobin: the connections (-conn) are assigned to the urls one after the other.
+          weighted:   the connections are assigned to the urls in proportion to their weights.
+          random:     each connection is assigned to a random url.
+          hash:       each connection is opened with all the urls, and each request is sent to the url
+                      that owns the key of its payload (-lbko, -lbkl) on a consistent hash ring.
+          Default is roundrobin.
+  -lbko   Offset of the key in the payload, if the load balancing strategy (-lb) is "hash". Default is 0.
+  -lbkl   Length of the key in the payload, if the load balancing strategy (-lb) is "hash".
+          Default is 0 (the key extends till the end of the payload). The key is truncated to the end
+          of a shorter payload, and it is the entire payload if the payload is not longer than -lbko.
   -Rr     Read responses from the target server. Default is false.
-  -Rrs    Read response size is the size of the responses in bytes returned by the target server. 
+  -Rrs    Read response size is the size of the responses in bytes returned by the target server.
+          This flag is applied only if the response framing (-Rf) is "fixed".
+  -Rf     Response framing defines how the responses are separated from each other in the stream.
+          Each response that blast counts is one complete message. Supported values are:
+          fixed:  responses of fixed size (-Rrs).
+          len2be, len2le, len4be, len4le: responses prefixed with their length in 2 or 4 bytes,
+                  big-endian or little-endian. The length does not include the prefix.
+          varint: responses prefixed with their length encoded as an unsigned varint.
+          delim:  responses ending with the delimiter (-Rfd).
+          Default is fixed.
+  -Rfd    Response delimiter ends each response, if the response framing (-Rf) is "delim".
+          Escape sequences like \n and \x00 are supported. Default is \n.
   -Rrd    Read response deadline defines the deadline for the read calls on connection.
           Default is no deadline which means the read calls do not timeout.
+          With -Rid, a request whose response is not read within the deadline (or the duration -z,
+          if there is no deadline) is counted once as a failed response (timeout), the read calls that
+          time out are then not counted, and its response is discarded if it is read later.
           This flag is applied only if "Read responses" (-Rr) is true.
   -Rtr    Read total responses is the total responses to read from the target server. 
           blast will stop if either the duration (-z) has exceeded or the total 
@@ -86,8 +269,23 @@ Options:
   -Rsr    Read successful responses is the total successful responses to read from the target server. 
           blast will stop if either the duration (-z) has exceeded or 
           the total successful responses have been read. Either of "-Rtr"
-          or "-Rsr" must be specified, if -Rr is set. This flag is applied only if 
+          or "-Rsr" must be specified, if -Rr is set without -n. This flag is applied only if 
           "Read responses" (-Rr) is true.
+  -Rid    Request id offset is the offset (in bytes) of the request id in the responses returned
+          by the target server. The request id is expected to be a big-endian 8 byte unsigned integer,
+          the same id that is rendered by {{requestId u64}} in the payload template (-ft). If specified,
+          blast correlates responses with requests and reports the latency percentiles. Default is -1
+          (disabled). This flag is applied only if "Read responses" (-Rr) is true.
+  -Rvp    Response validation prefix. If specified, a response is successful only if it starts with the
+          prefix, else it is reported as a failed response (and is not counted in -Rsr).
+          Escape sequences like \n and \x00 are supported. Example usage: -Rvp "+OK".
+  -Rvr    Response validation regex. If specified, a response is successful only if it matches the regular
+          expression. Example usage: -Rvr "^(OK|STORED)".
+  -Rvs    Response validation status byte, of the form offset:value. If specified, a response is successful
+          only if the byte at the offset (in bytes) is the value. The failed responses are reported by their
+          status byte. Example usage: -Rvs 4:0x00.
+          Only one of -Rvp, -Rvr or -Rvs can be specified. These flags are applied only if "Read responses"
+          (-Rr) is true.
 
   -conn   Number of connections to open with the target URL.
           Total number of connections cannot be greater than the concurrency level.
@@ -96,22 +294,79 @@ Options:
 
   -kA     Keep connections alive. If set, blast will keep running until a termination signal is sent. Default is false.
 
+  -rf     Report format. Supported values are text, json and csv. Default is text.
+  -ro     Report output file path. If specified, the report in the format (-rf) is written to the file
+          and the human-readable report is written to stdout. If not specified, the report in the
+          format (-rf) is written to stdout.
+  -rw     Report window is the size of each window in the time-series of the report. The requests,
+          responses, errors, payload sizes and latency are reported for each window.
+          Default is 1 second. Example usage: -rw 5s.
+  -pi     Progress interval. If specified, blast writes a progress line to stderr every interval,
+          with the elapsed time, requests sent, responses read, current RPS, error rate
+          and connections alive. Default is 0 (disabled). Example usage: -pi 1s.
+  -pm     Prometheus metrics address (host:port). If specified, blast exposes the live metrics of the run
+          (requests sent, bytes written, write errors by class, responses read, read errors by class, active
+          connections and latency) on http://<address>/metrics in the Prometheus text format, till the run
+          completes. Default is no metrics listener. Example usage: -pm localhost:9100.
+
+  -Ser    SLO max error rate is the maximum ratio (0 to 1) of the failed (or dropped) requests and the failed
+          responses to the total requests and responses. Default is -1 (disabled). Example usage: -Ser 0.01.
+  -Stp    SLO min throughput is the minimum rate of the successful requests per second. Default is 0 (disabled).
+  -Sp99   SLO max p99 latency is the maximum p99 latency corrected for the coordinated omission. It requires
+          "Read responses" (-Rr) and the request id offset (-Rid). Default is 0 (disabled). Example usage: -Sp99 50ms.
+  -Srr    SLO min response ratio is the minimum ratio (0 to 1) of the successful responses to the requests sent.
+          It requires "Read responses" (-Rr). Default is 0 (disabled).
+  -Set    SLO max error count of a type (the error message), across the requests and the responses. It is of the
+          form type=count and can be repeated. Example usage: -Set "connection reset by peer=10".
+          If any of the SLO thresholds is specified, blast prints a pass/fail table of the thresholds after the
+          report (on stderr, if the report on stdout is json or csv), and exits with status 2 if any of them
+          is breached.
+
+  -cmp    Compare the candidate report with the baseline report, instead of running blast. The reports are
+          the JSON reports (-rf json) of two runs, specified in place of the urls. Example usage:
+          -cmp baseline.json candidate.json. blast prints the deltas and the percent changes of the throughput,
+          latency percentiles, error counts and payload sizes, and exits with status 3 if the candidate
+          regresses beyond the tolerances.
+  -cmptp  Throughput tolerance is the maximum decrease (in percent) of the throughput. Default is 5.
+  -cmplt  Latency tolerance is the maximum increase (in percent) of each latency percentile. Default is 10.
+  -cmpet  Errors tolerance is the maximum increase (in percent) of each error count, in total and by type.
+          Any error is a regression if the baseline has no errors of the same type. Default is 0.
+
   -cpus   Number of cpu cores to use.
           (default for current machine is %d cores)
+
+  -config Config file path (JSON: .json or YAML: .yaml, .yml) describing the run: the target urls and
+          the values of the options. The options specified on the command line override the values in
+          the config file, and the urls specified on the command line override the urls in the config file.
+          The values in the config file are validated like the options. The relative paths of the input files
+          (-f, -fcsv, -tlsca, -tlscert, -tlskey, -hs) in the config file are relative to its directory.
+          Example usage: -config run.yaml.
 `
 		_, _ = fmt.Fprint(os.Stderr, fmt.Sprintf(usage, executableName, executableName, runtime.NumCPU()))
 	}
 
 	flag.Parse()
-	if flag.NArg() < 1 {
+	if *compareReports {
+		compareAndExit(flag.Args())
+	}
+	urls := urlsWithConfigFile(*configFilePath)
+	if len(urls) < 1 {
 		usageAndExit("")
 	}
 
-	url := flag.Args()[0]
-	assertUrl(url)
+	assertUrls(urls)
+	assertLoadBalancing(*loadBalancing)
 	assertPayloadFilePath(*payloadFilePath)
+	assertPayloadCorpus(*payloadFormat, *payloadSelection)
+	assertPayloadTemplate(*payloadTemplate, *payloadFormat, *payloadDataFilePath)
 	assertConnectTimeout(*connectTimeout)
+	assertReconnectPolicy(*reconnectStrategy, *reconnectBackoff, *reconnectMaxBackoff)
+	assertChurnPolicy(*churnLifetime, *churnRate)
+	assertTLSOptions(*tlsMinVersion, *tlsCertFile, *tlsKeyFile)
+	assertHandshake(*handshakeFilePath, *handshakeAckDelimiter, *handshakeAckPrefix, *handshakeTimeout)
 	assertRequestsPerSecond(*requestsPerSecond)
+	assertArrivalRate(*arrivalRate)
+	assertLoadProfile(*loadProfile, *concurrency)
 	assertMaxDuration(*maxDuration)
 	assertConcurrencyWithClientConnections(
 		*concurrency,
@@ -119,39 +374,151 @@ Options:
 	)
 	assertResponseReading(
 		*readResponses,
-		*responsePayloadSize,
 		*readTotalResponses,
 		*readSuccessfulResponses,
+		*totalRequests,
 	)
-	assertAndSetMaxProcs(*cpus)
-	return setUpBlast(
-		payload.NewConstantPayloadGenerator(getFilePayload(*payloadFilePath)),
-		url,
+	assertResponseFraming(
+		*readResponses,
+		*responseFraming,
+		*responsePayloadSize,
+		*responseDelimiter,
+	)
+	assertResponseValidation(
+		*readResponses,
+		*validResponsePrefix,
+		*validResponsePattern,
+		*validResponseStatusByte,
 	)
+	assertRequestIdOffset(*requestIdOffset)
+	assertReportFormat(*reportFormat)
+	assertReportWindow(*reportWindow)
+	assertProgressInterval(*progressInterval)
+	assertMetricsAddress(*metricsAddress)
+	assertSLOThresholds(*readResponses, *requestIdOffset)
+	assertAndSetMaxProcs(*cpus)
+	var payloadGenerator payload.ContextualPayloadGenerator
+	seed := payloadSeedOf(flag.CommandLine, time.Now())
+	if *payloadTemplate {
+		payloadGenerator = newTemplatePayloadGenerator(*payloadFilePath, *payloadDataFilePath, seed)
+	} else {
+		payloadGenerator = payload.NewPayloadGeneratorAdapter(
+			newFilePayloadGenerator(*payloadFilePath, *payloadFormat, *payloadSelection, seed),
+		)
+	}
+	return setUpBlast(payloadGenerator, urls)
 }
 
 // Parse parses the command line arguments.
 func (parser DynamicPayloadArgumentsParser) Parse(executableName string) Blast {
 	logo := `{{ .Title "%v" "" 0}}`
-	banner.InitString(os.Stdout, true, false, fmt.Sprintf(logo, executableName))
+	banner.InitString(os.Stderr, true, false, fmt.Sprintf(logo, executableName))
 
 	flag.Usage = func() {
 		var usage = `%v is a load generator for TCP servers which maintain persistent connections.
 
-Usage: %v [options...] <url>
+Usage: %v [options...] <url> [<url>...]
+
+The url is of the form host:port, tcp://host:port, unix:///path or udp://host:port.
+Each request is sent as a single datagram and each datagram read is a single response, if the url is udp.
+If multiple urls are specified, the load is distributed across them (-lb) and the report includes the
+metrics of each url. The weight of a url (-lb weighted or hash) is specified as ?weight=N, for example:
+tcp://node1:8080?weight=3. Default weight is 1.
 
 Options:
   -c      Number of workers to run concurrently. Default is 50.
   -rps    Rate limit in requests per second (RPS) per worker. Default is 50.
+  -ar     Arrival rate in requests per second across all the workers. If specified, blast schedules
+          the requests at a constant arrival rate (open model) instead of throttling each worker (-rps).
+          A scheduled request is dropped if no worker is available to send it, and a request that is
+          sent later than its scheduled time is reported as late. Default is 0 (disabled).
+  -lp     Load profile is a comma separated list of stages, each of the form duration:rate[@workers] or
+          duration:startRate-endRate[@workers]. The rate is in requests per second across all the workers,
+          and changes linearly from startRate to endRate over the duration of the stage. workers is the
+          number of workers that send the requests in the stage (default is all the workers, -c).
+          The requests are scheduled like -ar, and the metrics of each stage are reported separately.
+          If specified, -rps, -ar and -z are not used. Default is no load profile.
+          Example usage: -lp 60s:0-5000,10m:5000,2m:8000@100,30s:8000-0.
   -z      Duration of blast to send requests. When duration is reached,
           application stops and exits. Default is 20 seconds.
           Example usage: -z 10s or -z 3m.
+  -n      Total number of requests to send across all the workers. If specified, blast stops once
+          the total requests have been sent and, if "Read responses" (-Rr) is true, once their responses
+          have been read or 5 seconds have elapsed. The duration (-z) still limits the run. With -ar
+          or -lp, the dropped requests do not count towards the total requests.
+          Default is 0 (no limit). Example usage: -n 10000.
   -t      Timeout for establishing connection with the target server. Default is 3 seconds.
           Also called as DialTimeout.
+  -rc     Reconnect strategy for the connections that fail at dial time or are lost during the run.
+          Supported values are:
+          none:      the connections are not re-established.
+          immediate: the connections are re-dialed without any wait between the attempts.
+          backoff:   the connections are re-dialed after an exponential backoff (-rcb, -rcm) with jitter.
+          The workers of a connection do not send requests while it is down, and the report shows the
+          reconnects and the downtime of each connection. Default is none.
+  -rca    Reconnect attempts is the maximum number of attempts to re-establish a connection each time
+          it fails. Default is 0 (unlimited).
+  -rcb    Reconnect backoff is the wait before the first reconnect attempt, if the reconnect strategy (-rc)
+          is "backoff". The wait doubles after each attempt. Default is 100ms.
+  -rcm    Reconnect max backoff is the maximum wait between the reconnect attempts, if the reconnect
+          strategy (-rc) is "backoff". Default is 5 seconds.
+  -chn    Churn the connections after the given number of requests are sent on each connection, to load
+          the connection setup and teardown paths of the target server. A churned connection is re-opened
+          and then closed. Default is 0 (disabled).
+  -cht    Churn the connections after they have been open for the given duration. Default is 0 (disabled).
+          Example usage: -cht 5s.
+  -chr    Churn the connections one after the other at the given rate of new connections per second.
+          Default is 0 (disabled). The latency and the errors of all the dials are reported.
+  -tls    Establish TLS connections with the target server (tcp or unix). The TLS handshake time is
+          reported separately from the TCP connect time. Default is false.
+  -tlsca  Path of the PEM encoded CA bundle that verifies the certificate of the target server.
+          Default is the system CA bundle. This flag is applied only if -tls is true.
+  -tlscert, -tlskey
+          Paths of the PEM encoded client certificate and key for mutual TLS (mTLS). Both must be
+          specified together. This flag is applied only if -tls is true.
+  -tlssni Server name indication (SNI). Default is the host of the target address. It must be
+          specified for a unix target. This flag is applied only if -tls is true.
+  -tlsk   Skip the verification of the certificate of the target server. Default is false.
+  -tlsmin Minimum TLS version. Supported values are 1.0, 1.1, 1.2 and 1.3. Default is 1.2.
+  -tlsr   Resume the TLS sessions when the connections are re-established (-rc) or churned
+          (-chn, -cht, -chr). Default is false.
+  -hs     Handshake file path. If specified, the content of the file is written on each connection after it is
+          established (and after the TLS handshake), and blast reads the ack before sending the requests on
+          the connection. A connection whose handshake fails is closed and reported as a dial error.
+  -hsd    Handshake ack delimiter ends the ack. Escape sequences like \n and \x00 are supported. Default is \n.
+  -hsa    Handshake ack prefix. If specified, the handshake fails if the ack does not start with the prefix.
+          Escape sequences are supported. Example usage: -hsa "+OK".
+  -hst    Handshake timeout is the maximum time for writing the handshake and reading the ack.
+          Default is the connect timeout (-t). These flags are applied only if -hs is specified.
+  -lb     Load balancing strategy, if multiple urls are specified. Supported values are:
+          roundrobin: the connections (-conn) are assigned to the urls one after the other.
+          weighted:   the connections are assigned to the urls in proportion to their weights.
+          random:     each connection is assigned to a random url.
+          hash:       each connection is opened with all the urls, and each request is sent to the url
+                      that owns the key of its payload (-lbko, -lbkl) on a consistent hash ring.
+          Default is roundrobin.
+  -lbko   Offset of the key in the payload, if the load balancing strategy (-lb) is "hash". Default is 0.
+  -lbkl   Length of the key in the payload, if the load balancing strategy (-lb) is "hash".
+          Default is 0 (the key extends till the end of the payload). The key is truncated to the end
+          of a shorter payload, and it is the entire payload if the payload is not longer than -lbko.
   -Rr     Read responses from the target server. Default is false.
-  -Rrs    Read response size is the size of the responses in bytes returned by the target server. 
+  -Rrs    Read response size is the size of the responses in bytes returned by the target server.
+          This flag is applied only if the response framing (-Rf) is "fixed".
+  -Rf     Response framing defines how the responses are separated from each other in the stream.
+          Each response that blast counts is one complete message. Supported values are:
+          fixed:  responses of fixed size (-Rrs).
+          len2be, len2le, len4be, len4le: responses prefixed with their length in 2 or 4 bytes,
+                  big-endian or little-endian. The length does not include the prefix.
+          varint: responses prefixed with their length encoded as an unsigned varint.
+          delim:  responses ending with the delimiter (-Rfd).
+          Default is fixed.
+  -Rfd    Response delimiter ends each response, if the response framing (-Rf) is "delim".
+          Escape sequences like \n and \x00 are supported. Default is \n.
   -Rrd    Read response deadline defines the deadline for the read calls on connection.
           Default is no deadline which means the read calls do not timeout.
+          With -Rid, a request whose response is not read within the deadline (or the duration -z,
+          if there is no deadline) is counted once as a failed response (timeout), the read calls that
+          time out are then not counted, and its response is discarded if it is read later.
           This flag is applied only if "Read responses" (-Rr) is true.
   -Rtr    Read total responses is the total responses to read from the target server. 
           blast will stop if either the duration (-z) has exceeded or the total 
@@ -160,8 +527,23 @@ Options:
   -Rsr    Read successful responses is the total successful responses to read from the target server. 
           blast will stop if either the duration (-z) has exceeded or 
           the total successful responses have been read. Either of "-Rtr"
-          or "-Rsr" must be specified, if -Rr is set. This flag is applied only if 
+          or "-Rsr" must be specified, if -Rr is set without -n. This flag is applied only if 
           "Read responses" (-Rr) is true.
+  -Rid    Request id offset is the offset (in bytes) of the request id in the responses returned
+          by the target server. The request id is expected to be a big-endian 8 byte unsigned integer,
+          the same id that is passed to the PayloadGenerator. If specified, blast correlates
+          responses with requests and reports the latency percentiles. Default is -1 (disabled).
+          This flag is applied only if "Read responses" (-Rr) is true.
+  -Rvp    Response validation prefix. If specified, a response is successful only if it starts with the
+          prefix, else it is reported as a failed response (and is not counted in -Rsr).
+          Escape sequences like \n and \x00 are supported. Example usage: -Rvp "+OK".
+  -Rvr    Response validation regex. If specified, a response is successful only if it matches the regular
+          expression. Example usage: -Rvr "^(OK|STORED)".
+  -Rvs    Response validation status byte, of the form offset:value. If specified, a response is successful
+          only if the byte at the offset (in bytes) is the value. The failed responses are reported by their
+          status byte. Example usage: -Rvs 4:0x00.
+          Only one of -Rvp, -Rvr or -Rvs can be specified. These flags are applied only if "Read responses"
+          (-Rr) is true.
 
   -conn   Number of connections to open with the target URL.
           Total number of connections cannot be greater than the concurrency level.
@@ -170,21 +552,76 @@ Options:
 	
   -kA     Keep connections alive. If set, blast will keep running until a termination signal is sent. Default is false.
 
+  -rf     Report format. Supported values are text, json and csv. Default is text.
+  -ro     Report output file path. If specified, the report in the format (-rf) is written to the file
+          and the human-readable report is written to stdout. If not specified, the report in the
+          format (-rf) is written to stdout.
+  -rw     Report window is the size of each window in the time-series of the report. The requests,
+          responses, errors, payload sizes and latency are reported for each window.
+          Default is 1 second. Example usage: -rw 5s.
+  -pi     Progress interval. If specified, blast writes a progress line to stderr every interval,
+          with the elapsed time, requests sent, responses read, current RPS, error rate
+          and connections alive. Default is 0 (disabled). Example usage: -pi 1s.
+  -pm     Prometheus metrics address (host:port). If specified, blast exposes the live metrics of the run
+          (requests sent, bytes written, write errors by class, responses read, read errors by class, active
+          connections and latency) on http://<address>/metrics in the Prometheus text format, till the run
+          completes. Default is no metrics listener. Example usage: -pm localhost:9100.
+
+  -Ser    SLO max error rate is the maximum ratio (0 to 1) of the failed (or dropped) requests and the failed
+          responses to the total requests and responses. Default is -1 (disabled). Example usage: -Ser 0.01.
+  -Stp    SLO min throughput is the minimum rate of the successful requests per second. Default is 0 (disabled).
+  -Sp99   SLO max p99 latency is the maximum p99 latency corrected for the coordinated omission. It requires
+          "Read responses" (-Rr) and the request id offset (-Rid). Default is 0 (disabled). Example usage: -Sp99 50ms.
+  -Srr    SLO min response ratio is the minimum ratio (0 to 1) of the successful responses to the requests sent.
+          It requires "Read responses" (-Rr). Default is 0 (disabled).
+  -Set    SLO max error count of a type (the error message), across the requests and the responses. It is of the
+          form type=count and can be repeated. Example usage: -Set "connection reset by peer=10".
+          If any of the SLO thresholds is specified, blast prints a pass/fail table of the thresholds after the
+          report (on stderr, if the report on stdout is json or csv), and exits with status 2 if any of them
+          is breached.
+
+  -cmp    Compare the candidate report with the baseline report, instead of running blast. The reports are
+          the JSON reports (-rf json) of two runs, specified in place of the urls. Example usage:
+          -cmp baseline.json candidate.json. blast prints the deltas and the percent changes of the throughput,
+          latency percentiles, error counts and payload sizes, and exits with status 3 if the candidate
+          regresses beyond the tolerances.
+  -cmptp  Throughput tolerance is the maximum decrease (in percent) of the throughput. Default is 5.
+  -cmplt  Latency tolerance is the maximum increase (in percent) of each latency percentile. Default is 10.
+  -cmpet  Errors tolerance is the maximum increase (in percent) of each error count, in total and by type.
+          Any error is a regression if the baseline has no errors of the same type. Default is 0.
+
   -cpus   Number of cpu cores to use.
           (default for current machine is %d cores)
+
+  -config Config file path (JSON: .json or YAML: .yaml, .yml) describing the run: the target urls and
+          the values of the options. The options specified on the command line override the values in
+          the config file, and the urls specified on the command line override the urls in the config file.
+          The values in the config file are validated like the options. The relative paths of the input files
+          (-f, -fcsv, -tlsca, -tlscert, -tlskey, -hs) in the config file are relative to its directory.
+          Example usage: -config run.yaml.
 `
 		_, _ = fmt.Fprint(os.Stderr, fmt.Sprintf(usage, executableName, executableName, runtime.NumCPU()))
 	}
 
 	flag.Parse()
-	if flag.NArg() < 1 {
+	if *compareReports {
+		compareAndExit(flag.Args())
+	}
+	urls := urlsWithConfigFile(*configFilePath)
+	if len(urls) < 1 {
 		usageAndExit("")
 	}
 
-	url := flag.Args()[0]
-	assertUrl(url)
+	assertUrls(urls)
+	assertLoadBalancing(*loadBalancing)
 	assertConnectTimeout(*connectTimeout)
+	assertReconnectPolicy(*reconnectStrategy, *reconnectBackoff, *reconnectMaxBackoff)
+	assertChurnPolicy(*churnLifetime, *churnRate)
+	assertTLSOptions(*tlsMinVersion, *tlsCertFile, *tlsKeyFile)
+	assertHandshake(*handshakeFilePath, *handshakeAckDelimiter, *handshakeAckPrefix, *handshakeTimeout)
 	assertRequestsPerSecond(*requestsPerSecond)
+	assertArrivalRate(*arrivalRate)
+	assertLoadProfile(*loadProfile, *concurrency)
 	assertMaxDuration(*maxDuration)
 	assertConcurrencyWithClientConnections(
 		*concurrency,
@@ -192,18 +629,53 @@ Options:
 	)
 	assertResponseReading(
 		*readResponses,
-		*responsePayloadSize,
 		*readTotalResponses,
 		*readSuccessfulResponses,
+		*totalRequests,
+	)
+	assertResponseFraming(
+		*readResponses,
+		*responseFraming,
+		*responsePayloadSize,
+		*responseDelimiter,
+	)
+	assertResponseValidation(
+		*readResponses,
+		*validResponsePrefix,
+		*validResponsePattern,
+		*validResponseStatusByte,
 	)
+	assertRequestIdOffset(*requestIdOffset)
+	assertReportFormat(*reportFormat)
+	assertReportWindow(*reportWindow)
+	assertProgressInterval(*progressInterval)
+	assertMetricsAddress(*metricsAddress)
+	assertSLOThresholds(*readResponses, *requestIdOffset)
 	assertAndSetMaxProcs(*cpus)
-	return setUpBlast(parser.payloadGenerator, url)
+	return setUpBlast(parser.payloadGenerator, urls)
 }
 
-// assertUrl asserts that the URL is not empty.
+// assertUrl asserts that the URL is not empty, and it is a tcp, unix or udp target.
 func assertUrl(url string) {
 	if len(strings.Trim(url, " ")) == 0 {
-		exitFunction("URL cannot be blank. URL is of the form host:port.")
+		exitFunction("URL cannot be blank. URL is of the form host:port, tcp://host:port, unix:///path or udp://host:port.")
+	}
+	if _, err := workers.ParseTarget(url); err != nil {
+		exitFunction(fmt.Sprintf("%v. URL is of the form host:port, tcp://host:port, unix:///path or udp://host:port.", err))
+	}
+}
+
+// assertUrls asserts each of the URLs (see assertUrl).
+func assertUrls(urls []string) {
+	for _, url := range urls {
+		assertUrl(url)
+	}
+}
+
+// assertLoadBalancing asserts that the load balancing strategy is supported.
+func assertLoadBalancing(strategy string) {
+	if _, err := workers.ParseLoadBalancingStrategy(strategy); err != nil {
+		exitFunction("-lb must be one of roundrobin, weighted, random or hash.")
 	}
 }
 
@@ -214,6 +686,27 @@ func assertPayloadFilePath(filePath string) {
 	}
 }
 
+// assertPayloadCorpus asserts that the format and the selection of the payload file are supported.
+func assertPayloadCorpus(format, selection string) {
+	if _, err := payload.ParseCorpusFormat(format); err != nil {
+		exitFunction("-ff must be one of file, dir, lines, bundle or manifest.")
+	}
+	if _, err := payload.ParseSelectionStrategy(selection); err != nil {
+		exitFunction("-fs must be one of sequential, random or weighted.")
+	}
+}
+
+// assertPayloadTemplate asserts that the payload template is used with the file format, and the data file is
+// used with the payload template.
+func assertPayloadTemplate(template bool, format, dataFilePath string) {
+	if dataFilePath != "" && !template {
+		exitFunction("-fcsv requires -ft.")
+	}
+	if template && format != "file" {
+		exitFunction("-ft requires -ff file.")
+	}
+}
+
 // assertConnectTimeout asserts that the connectTimeout is greater than zero.
 func assertConnectTimeout(timeout time.Duration) {
 	if timeout <= time.Duration(0) {
@@ -221,6 +714,56 @@ func assertConnectTimeout(timeout time.Duration) {
 	}
 }
 
+// assertReconnectPolicy asserts that the reconnect strategy is supported, and the backoffs are greater than zero.
+func assertReconnectPolicy(strategy string, backoff, maxBackoff time.Duration) {
+	if _, err := workers.ParseReconnectStrategy(strategy); err != nil {
+		exitFunction("-rc must be one of none, immediate or backoff.")
+	}
+	if backoff <= 0 || maxBackoff <= 0 {
+		exitFunction("-rcb and -rcm cannot be smaller than or equal to zero.")
+	}
+}
+
+// assertChurnPolicy asserts that the lifetime and the rate of churning the connections are not smaller than zero.
+func assertChurnPolicy(lifetime time.Duration, rate float64) {
+	if lifetime < 0 {
+		exitFunction("-cht cannot be smaller than zero.")
+	}
+	if rate < 0 {
+		exitFunction("-chr cannot be smaller than zero.")
+	}
+}
+
+// assertTLSOptions asserts that the minimum TLS version (if specified) is supported, and the client certificate
+// and the client key are specified together.
+func assertTLSOptions(minVersion, certFile, keyFile string) {
+	if minVersion != "" {
+		if _, err := workers.ParseTLSVersion(minVersion); err != nil {
+			exitFunction("-tlsmin must be one of 1.0, 1.1, 1.2 or 1.3.")
+		}
+	}
+	if (certFile == "") != (keyFile == "") {
+		exitFunction("-tlscert and -tlskey must be specified together.")
+	}
+}
+
+// assertHandshake asserts that the handshake file exists, the ack delimiter and the ack prefix are valid, and the
+// handshake timeout is not smaller than zero.
+func assertHandshake(filePath, ackDelimiter, ackPrefix string, timeout time.Duration) {
+	if filePath == "" {
+		if ackPrefix != "" {
+			exitFunction("-hsa requires -hs.")
+		}
+		return
+	}
+	if timeout < 0 {
+		exitFunction("-hst cannot be smaller than zero.")
+	}
+	if _, err := newHandshakeInitializer(filePath, ackDelimiter, ackPrefix); err != nil {
+		exitFunction(fmt.Sprintf("-hs/-hsd/-hsa: %v.", err.Error()))
+	}
+}
+
 // assertRequestsPerSecond asserts that the requestsPerSecond is greater than zero.
 func assertRequestsPerSecond(requestsPerSecond float64) {
 	if requestsPerSecond <= 0 {
@@ -228,6 +771,29 @@ func assertRequestsPerSecond(requestsPerSecond float64) {
 	}
 }
 
+// assertArrivalRate asserts that the arrivalRate is not smaller than zero.
+func assertArrivalRate(arrivalRate float64) {
+	if arrivalRate < 0 {
+		exitFunction("-ar cannot be smaller than zero.")
+	}
+}
+
+// assertLoadProfile asserts that the loadProfile (if specified) is valid, and that none of its stages need
+// more workers than the concurrency.
+func assertLoadProfile(loadProfile string, concurrency uint) {
+	if loadProfile == "" {
+		return
+	}
+	profile, err := workers.ParseLoadProfile(loadProfile)
+	if err != nil {
+		exitFunction(fmt.Sprintf("-lp: %v.", err.Error()))
+		return
+	}
+	if profile.MaxWorkers() > concurrency {
+		exitFunction("-lp: workers of a stage cannot be greater than -c.")
+	}
+}
+
 // assertMaxDuration asserts that the maxDuration is greater than zero.
 func assertMaxDuration(duration time.Duration) {
 	if duration <= time.Duration(0) {
@@ -263,24 +829,148 @@ func assertAndSetMaxProcs(cpus int) {
 }
 
 // assertResponseReading asserts the options related to reading responses.
+// Neither of -Rtr or -Rsr is required if the total requests (-n) are specified, blast then reads the responses
+// of the total requests.
 func assertResponseReading(
 	readResponses bool,
-	responsePayloadSize int64,
-	readTotalResponses, readSuccessfulResponses uint,
+	readTotalResponses, readSuccessfulResponses, totalRequests uint,
 ) {
 	if readResponses {
-		if responsePayloadSize < 0 {
-			exitFunction("-Rrs cannot be smaller than 0.")
-		}
 		if readTotalResponses > 0 && readSuccessfulResponses > 0 {
 			exitFunction("both -Rtr and -Rsr cannot be specified.")
 		}
-		if readTotalResponses == 0 && readSuccessfulResponses == 0 {
+		if readTotalResponses == 0 && readSuccessfulResponses == 0 && totalRequests == 0 {
 			exitFunction("either of -Rtr or -Rsr must be specified.")
 		}
 	}
 }
 
+// assertResponseFraming asserts the options related to framing the responses.
+func assertResponseFraming(
+	readResponses bool,
+	responseFraming string,
+	responsePayloadSize int64,
+	responseDelimiter string,
+) {
+	if readResponses {
+		if responseFraming == fixedSizeFraming && responsePayloadSize <= 0 {
+			exitFunction("-Rrs must be greater than 0.")
+		}
+		if _, err := newResponseFramer(responseFraming, responsePayloadSize, responseDelimiter); err != nil {
+			exitFunction(fmt.Sprintf("-Rf/-Rfd: %v.", err.Error()))
+		}
+	}
+}
+
+// assertResponseValidation asserts that at most one of the response validations is specified, that it is valid,
+// and that the responses are read.
+func assertResponseValidation(
+	readResponses bool,
+	validResponsePrefix string,
+	validResponsePattern string,
+	validResponseStatusByte string,
+) {
+	specified := 0
+	for _, validation := range []string{validResponsePrefix, validResponsePattern, validResponseStatusByte} {
+		if validation != "" {
+			specified++
+		}
+	}
+	if specified == 0 {
+		return
+	}
+	if specified > 1 {
+		exitFunction("only one of -Rvp, -Rvr or -Rvs can be specified.")
+	}
+	if !readResponses {
+		exitFunction("-Rvp, -Rvr and -Rvs require -Rr.")
+	}
+	if _, err := newResponseValidator(validResponsePrefix, validResponsePattern, validResponseStatusByte); err != nil {
+		exitFunction(fmt.Sprintf("-Rvp/-Rvr/-Rvs: %v.", err.Error()))
+	}
+}
+
+// assertReportFormat asserts that the reportFormat is one of the supported report formats.
+func assertReportFormat(reportFormat string) {
+	if _, err := report.ParseFormat(reportFormat); err != nil {
+		exitFunction("-rf must be one of text, json or csv.")
+	}
+}
+
+// assertReportWindow asserts that the reportWindow is greater than zero.
+func assertReportWindow(window time.Duration) {
+	if window <= time.Duration(0) {
+		exitFunction("-rw cannot be smaller than or equal to zero.")
+	}
+}
+
+// assertProgressInterval asserts that the progressInterval is not smaller than zero.
+func assertProgressInterval(interval time.Duration) {
+	if interval < time.Duration(0) {
+		exitFunction("-pi cannot be smaller than zero.")
+	}
+}
+
+// assertRequestIdOffset asserts that the requestIdOffset is either -1 (disabled) or not smaller than zero.
+func assertRequestIdOffset(requestIdOffset int) {
+	if requestIdOffset < -1 {
+		exitFunction("-Rid cannot be smaller than -1.")
+	}
+}
+
+// assertMetricsAddress asserts that the metricsAddress (if specified) is of the form host:port.
+func assertMetricsAddress(address string) {
+	if address == "" {
+		return
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		exitFunction("-pm must be of the form host:port.")
+	}
+}
+
+// assertSLOThresholds asserts that the SLO thresholds are in their ranges, and that the responses are read
+// (with the request id offset for the p99 latency) if the thresholds need them.
+func assertSLOThresholds(readResponses bool, requestIdOffset int) {
+	if *sloMaxErrorRate > 1 {
+		exitFunction("-Ser cannot be greater than 1.")
+	}
+	if *sloMinThroughput < 0 {
+		exitFunction("-Stp cannot be smaller than zero.")
+	}
+	if *sloMaxP99Latency < 0 {
+		exitFunction("-Sp99 cannot be smaller than zero.")
+	}
+	if *sloMinResponseRatio < 0 || *sloMinResponseRatio > 1 {
+		exitFunction("-Srr must be between 0 and 1.")
+	}
+	if *sloMaxP99Latency > 0 && (!readResponses || requestIdOffset < 0) {
+		exitFunction("-Sp99 requires -Rr and -Rid.")
+	}
+	if *sloMinResponseRatio > 0 && !readResponses {
+		exitFunction("-Srr requires -Rr.")
+	}
+}
+
+// sloThresholds returns the SLOThresholds, or nil if none of the SLO thresholds are specified.
+func sloThresholds() *SLOThresholds {
+	if *sloMaxErrorRate < 0 && *sloMinThroughput == 0 && *sloMaxP99Latency == 0 &&
+		*sloMinResponseRatio == 0 && len(sloMaxErrorCountByType) == 0 {
+		return nil
+	}
+	var maxErrorRate *float64
+	if *sloMaxErrorRate >= 0 {
+		rate := *sloMaxErrorRate
+		maxErrorRate = &rate
+	}
+	return &SLOThresholds{
+		MaxErrorRate:        maxErrorRate,
+		MinThroughput:       *sloMinThroughput,
+		MaxP99Latency:       *sloMaxP99Latency,
+		MinResponseRatio:    *sloMinResponseRatio,
+		MaxErrorCountByType: sloMaxErrorCountByType,
+	}
+}
+
 // getFilePayload returns the file content.
 func getFilePayload(filePath string) []byte {
 	provider, err := payload.NewFilePayloadProvider(filePath)
@@ -290,6 +980,138 @@ func getFilePayload(filePath string) []byte {
 	return provider.Get()
 }
 
+// payloadSeedOf returns the seed (-fseed) if it is specified in the flagSet (on the command line or in the config
+// file), or a time-based seed from the now otherwise, so that the runs without -fseed select different payloads
+// and render different random values of the template.
+func payloadSeedOf(flagSet *flag.FlagSet, now time.Time) int64 {
+	seed := now.UnixNano()
+	flagSet.Visit(func(specified *flag.Flag) {
+		if specified.Name == "fseed" {
+			seed = specified.Value.(flag.Getter).Get().(int64)
+		}
+	})
+	return seed
+}
+
+// newFilePayloadGenerator creates a new payload.PayloadGenerator that selects the payload of each request from
+// the payloads loaded from the filePath in the format.
+// The content of the file is sent as-is with each request, for the default format (file) and selection (sequential).
+func newFilePayloadGenerator(filePath, format, selection string, seed int64) payload.PayloadGenerator {
+	corpusFormat, _ := payload.ParseCorpusFormat(format)
+	selectionStrategy, _ := payload.ParseSelectionStrategy(selection)
+	if corpusFormat == payload.FileCorpusFormat && selectionStrategy == payload.SequentialSelection {
+		return payload.NewConstantPayloadGenerator(getFilePayload(filePath))
+	}
+	corpus, err := payload.LoadPayloadCorpus(filePath, corpusFormat)
+	if err != nil {
+		exitFunction(fmt.Sprintf("-f %v cannot be loaded as %v: %v.", filePath, format, err.Error()))
+	}
+	return payload.NewCorpusPayloadGenerator(corpus, selectionStrategy, seed)
+}
+
+// newTemplatePayloadGenerator creates a new payload.TemplatePayloadGenerator from the template in the filePath,
+// and the data in the (optional) dataFilePath.
+func newTemplatePayloadGenerator(filePath, dataFilePath string, seed int64) payload.TemplatePayloadGenerator {
+	var data payload.TemplateData
+	if dataFilePath != "" {
+		var err error
+		if data, err = payload.LoadTemplateData(dataFilePath); err != nil {
+			exitFunction(fmt.Sprintf("-fcsv %v cannot be loaded: %v.", dataFilePath, err.Error()))
+		}
+	}
+	generator, err := payload.NewTemplatePayloadGenerator(getFilePayload(filePath), data, seed)
+	if err != nil {
+		exitFunction(fmt.Sprintf("-f %v: %v.", filePath, err.Error()))
+	}
+	return generator
+}
+
+// newResponseFramer creates a new report.ResponseFramer identified by responseFraming.
+func newResponseFramer(
+	responseFraming string,
+	responsePayloadSize int64,
+	responseDelimiter string,
+) (report.ResponseFramer, error) {
+	switch responseFraming {
+	case fixedSizeFraming:
+		return report.NewFixedSizeResponseFramer(responsePayloadSize)
+	case "len2be":
+		return report.NewLengthPrefixedResponseFramer(2, binary.BigEndian)
+	case "len2le":
+		return report.NewLengthPrefixedResponseFramer(2, binary.LittleEndian)
+	case "len4be":
+		return report.NewLengthPrefixedResponseFramer(4, binary.BigEndian)
+	case "len4le":
+		return report.NewLengthPrefixedResponseFramer(4, binary.LittleEndian)
+	case "varint":
+		return report.NewVarintLengthPrefixedResponseFramer(), nil
+	case "delim":
+		delimiter, err := strconv.Unquote(`"` + responseDelimiter + `"`)
+		if err != nil {
+			return nil, fmt.Errorf("invalid delimiter %v", responseDelimiter)
+		}
+		return report.NewDelimiterResponseFramer([]byte(delimiter))
+	default:
+		return nil, fmt.Errorf("unsupported response framing %v", responseFraming)
+	}
+}
+
+// newResponseValidator creates a new report.ResponseValidator from the specified response validation, it returns
+// nil if none of the response validations is specified.
+func newResponseValidator(
+	validResponsePrefix string,
+	validResponsePattern string,
+	validResponseStatusByte string,
+) (report.ResponseValidator, error) {
+	switch {
+	case validResponsePrefix != "":
+		prefix, err := strconv.Unquote(`"` + validResponsePrefix + `"`)
+		if err != nil {
+			return nil, fmt.Errorf("invalid prefix %v", validResponsePrefix)
+		}
+		return report.NewPrefixResponseValidator([]byte(prefix))
+	case validResponsePattern != "":
+		return report.NewRegexResponseValidator(validResponsePattern)
+	case validResponseStatusByte != "":
+		offset, status, found := strings.Cut(validResponseStatusByte, ":")
+		if !found {
+			return nil, fmt.Errorf("status byte must be of the form offset:value")
+		}
+		parsedOffset, err := strconv.ParseUint(offset, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("invalid status byte offset %v", offset)
+		}
+		parsedStatus, err := strconv.ParseUint(status, 0, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid status byte value %v", status)
+		}
+		return report.NewStatusByteResponseValidator(int(parsedOffset), byte(parsedStatus)), nil
+	default:
+		return nil, nil
+	}
+}
+
+// newHandshakeInitializer creates a new workers.HandshakeConnectionInitializer that writes the content of the
+// handshake file, and reads the ack ending with the ackDelimiter. The ack must start with the ackPrefix, if
+// specified.
+func newHandshakeInitializer(filePath, ackDelimiter, ackPrefix string) (workers.ConnectionInitializer, error) {
+	provider, err := payload.NewFilePayloadProvider(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("file path: %v does not exist", filePath)
+	}
+	ackFramer, err := newResponseFramer("delim", -1, ackDelimiter)
+	if err != nil {
+		return nil, err
+	}
+	var ackValidator report.ResponseValidator
+	if ackPrefix != "" {
+		if ackValidator, err = newResponseValidator(ackPrefix, "", ""); err != nil {
+			return nil, err
+		}
+	}
+	return workers.NewHandshakeConnectionInitializer(provider.Get(), ackFramer, ackValidator)
+}
+
 // usageAndExit defines the usage of blast application and exits the application.
 func usageAndExit(msg string) {
 	if msg != "" {
@@ -302,19 +1124,86 @@ func usageAndExit(msg string) {
 }
 
 // setUpBlast creates a new instance of blast.Blast.
+// The load is distributed across the urls as per the load balancing strategy (-lb), if multiple urls are
+// specified.
 func setUpBlast(
-	payloadGenerator payload.PayloadGenerator,
-	url string,
+	payloadGenerator payload.ContextualPayloadGenerator,
+	urls []string,
 ) Blast {
 	groupOptions := workers.NewGroupOptionsFullyLoaded(
 		*concurrency,
 		*connections,
-		payloadGenerator,
-		url,
+		nil,
+		urls[0],
 		*connectTimeout,
 		*requestsPerSecond,
 		*maxDuration,
-	)
+	).WithContextualPayloadGenerator(payloadGenerator)
+	if *loadProfile != "" {
+		profile, _ := workers.ParseLoadProfile(*loadProfile)
+		groupOptions = groupOptions.WithLoadProfile(profile)
+	} else if *arrivalRate > 0 {
+		groupOptions = groupOptions.WithArrivalRate(*arrivalRate)
+	}
+	if *totalRequests > 0 {
+		groupOptions = groupOptions.WithTotalRequests(*totalRequests)
+	}
+	if strategy, _ := workers.ParseReconnectStrategy(*reconnectStrategy); strategy != workers.NoReconnect {
+		groupOptions = groupOptions.WithReconnectPolicy(workers.ReconnectPolicy{
+			Strategy:       strategy,
+			InitialBackoff: *reconnectBackoff,
+			MaxBackoff:     *reconnectMaxBackoff,
+			MaxAttempts:    *reconnectAttempts,
+		})
+	}
+	groupOptions = groupOptions.WithChurnPolicy(workers.ChurnPolicy{
+		RequestsPerConnection:   *churnRequests,
+		ConnectionLifetime:      *churnLifetime,
+		NewConnectionsPerSecond: *churnRate,
+	})
+	if len(urls) > 1 {
+		targets, _ := workers.ParseTargets(urls)
+		strategy, _ := workers.ParseLoadBalancingStrategy(*loadBalancing)
+		groupOptions = groupOptions.WithTargets(targets, strategy).WithHashKey(workers.HashKey{
+			Offset: int(*hashKeyOffset),
+			Length: int(*hashKeyLength),
+		})
+	}
+	if *useTLS {
+		for _, url := range urls {
+			if target, _ := workers.ParseTarget(url); target.IsDatagram() {
+				exitFunction(fmt.Sprintf("invalid TLS options: %v.", workers.ErrTLSOverDatagram))
+			}
+		}
+		minVersion, _ := workers.ParseTLSVersion(*tlsMinVersion)
+		tlsConfig, err := workers.TLSOptions{
+			CAFile:             *tlsCAFile,
+			CertFile:           *tlsCertFile,
+			KeyFile:            *tlsKeyFile,
+			ServerName:         *tlsServerName,
+			InsecureSkipVerify: *tlsInsecureSkipVerify,
+			MinVersion:         minVersion,
+			SessionResumption:  *tlsSessionResumption,
+		}.Config(urls[0])
+		if err != nil {
+			exitFunction(fmt.Sprintf("invalid TLS options: %v.", err))
+		}
+		groupOptions = groupOptions.WithTLSConfig(tlsConfig)
+	}
+	if *handshakeFilePath != "" {
+		initializer, _ := newHandshakeInitializer(*handshakeFilePath, *handshakeAckDelimiter, *handshakeAckPrefix)
+		groupOptions = groupOptions.WithConnectionInitializer(initializer, *handshakeTimeout)
+	}
+
+	format, _ := report.ParseFormat(*reportFormat)
+	reportOptions := ReportOptions{
+		Format:           format,
+		FilePath:         *reportFilePath,
+		TimeSeriesWindow: *reportWindow,
+		ProgressInterval: *progressInterval,
+		MetricsAddress:   *metricsAddress,
+		SLOThresholds:    sloThresholds(),
+	}
 
 	var instance Blast
 	if *readResponses {
@@ -322,16 +1211,30 @@ func setUpBlast(
 		if *readSuccessfulResponses > 0 {
 			readingOption = ReadSuccessfulResponses
 		}
+		totalResponsesToRead := *readTotalResponses
+		if totalResponsesToRead == 0 && *readSuccessfulResponses == 0 {
+			totalResponsesToRead = *totalRequests
+		}
 		responseOptions := ResponseOptions{
 			ResponsePayloadSizeBytes:       *responsePayloadSize,
-			TotalResponsesToRead:           *readTotalResponses,
+			TotalResponsesToRead:           totalResponsesToRead,
 			TotalSuccessfulResponsesToRead: *readSuccessfulResponses,
 			ReadingOption:                  readingOption,
 			ReadDeadline:                   *readResponseDeadline,
 		}
-		instance = NewBlastWithResponseReading(groupOptions, responseOptions, *keepConnectionsAlive)
+		responseOptions.ResponseFramer, _ = newResponseFramer(*responseFraming, *responsePayloadSize, *responseDelimiter)
+		if *requestIdOffset >= 0 {
+			responseOptions.RequestIdExtractor = report.NewOffsetRequestIdExtractor(*requestIdOffset, binary.BigEndian)
+		}
+		responseOptions.ResponseValidator, _ = newResponseValidator(
+			*validResponsePrefix,
+			*validResponsePattern,
+			*validResponseStatusByte,
+		)
+		instance = NewBlastWithResponseReadingWithReportOptions(groupOptions, responseOptions, reportOptions, *keepConnectionsAlive)
 	} else {
-		instance = NewBlastWithoutResponseReading(groupOptions, *keepConnectionsAlive)
+		instance = NewBlastWithoutResponseReadingWithReportOptions(groupOptions, reportOptions, *keepConnectionsAlive)
 	}
+	instance.exitOnSLOBreach = true
 	return instance
 }
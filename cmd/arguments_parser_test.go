@@ -1,7 +1,11 @@
 package blast
 
 import (
+	"bufio"
+	"flag"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -26,6 +30,51 @@ func TestParseCommandLineArgumentsWithEmptyUrl(t *testing.T) {
 	})
 }
 
+func TestParseCommandLineArgumentsWithAnUnsupportedNetwork(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertUrl("http://localhost:8080")
+	})
+}
+
+func TestParseCommandLineArgumentsWithUrls(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.NotPanics(t, func() {
+		assertUrl("localhost:8080")
+		assertUrl("tcp://localhost:8080")
+		assertUrl("udp://localhost:8080")
+		assertUrl("unix:///tmp/blast.sock")
+	})
+}
+
+func TestParseCommandLineArgumentsWithAnInvalidUrlAmongUrls(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertUrls([]string{"localhost:8080", "localhost"})
+	})
+}
+
+func TestParseCommandLineArgumentsWithWeightedUrls(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.NotPanics(t, func() {
+		assertUrls([]string{"tcp://node1:8080?weight=3", "tcp://node2:8080"})
+	})
+}
+
+func TestParseCommandLineArgumentsWithAnUnsupportedLoadBalancingStrategy(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertLoadBalancing("leastconn")
+	})
+}
+
+func TestParseCommandLineArgumentsWithLoadBalancingStrategy(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.NotPanics(t, func() {
+		assertLoadBalancing("hash")
+	})
+}
+
 func TestParseCommandLineArgumentsWithoutPayloadFilePath(t *testing.T) {
 	exitFunction = exitWithPanic
 	assert.Panics(t, func() {
@@ -156,35 +205,241 @@ func TestParseCommandLineArgumentsWithMaxProcsIsGreaterThanZero(t *testing.T) {
 func TestParseCommandLineArgumentsWithResponseSizeLessThanZero(t *testing.T) {
 	exitFunction = exitWithPanic
 	assert.Panics(t, func() {
-		assertResponseReading(true, -1, 10, 10)
+		assertResponseFraming(true, "fixed", -1, "")
+	})
+}
+
+func TestParseCommandLineArgumentsWithResponseSizeEqualToZero(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertResponseFraming(true, "fixed", 0, "")
+	})
+}
+
+func TestParseCommandLineArgumentsWithUnsupportedResponseFraming(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertResponseFraming(true, "len3be", -1, "")
+	})
+}
+
+func TestParseCommandLineArgumentsWithLengthPrefixedResponseFraming(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.NotPanics(t, func() {
+		assertResponseFraming(true, "len4be", -1, "")
+	})
+}
+
+func TestParseCommandLineArgumentsWithEmptyResponseDelimiter(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertResponseFraming(true, "delim", -1, "")
+	})
+}
+
+func TestParseCommandLineArgumentsWithEscapedResponseDelimiter(t *testing.T) {
+	framer, err := newResponseFramer("delim", -1, "\\r\\n")
+	assert.Nil(t, err)
+
+	response, err := framer.Frame(bufio.NewReader(strings.NewReader("ok\r\nnext")))
+	assert.Nil(t, err)
+	assert.Equal(t, "ok\r\n", string(response))
+}
+
+func TestParseCommandLineArgumentsWithMultipleResponseValidations(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertResponseValidation(true, "OK", "^OK", "")
+	})
+}
+
+func TestParseCommandLineArgumentsWithResponseValidationWithoutReadingResponses(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertResponseValidation(false, "OK", "", "")
+	})
+}
+
+func TestParseCommandLineArgumentsWithInvalidResponseValidationRegex(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertResponseValidation(true, "", "(OK", "")
+	})
+}
+
+func TestParseCommandLineArgumentsWithInvalidResponseValidationStatusByte(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertResponseValidation(true, "", "", "4")
+	})
+	assert.Panics(t, func() {
+		assertResponseValidation(true, "", "", "4:0x100")
 	})
 }
 
+func TestParseCommandLineArgumentsWithResponseValidation(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.NotPanics(t, func() {
+		assertResponseValidation(false, "", "", "")
+		assertResponseValidation(true, "+OK", "", "")
+		assertResponseValidation(true, "", "^(OK|STORED)", "")
+		assertResponseValidation(true, "", "", "4:0x00")
+	})
+}
+
+func TestParseCommandLineArgumentsWithEscapedResponseValidationPrefix(t *testing.T) {
+	validator, err := newResponseValidator("\\x00OK", "", "")
+	assert.Nil(t, err)
+
+	assert.Nil(t, validator.Validate([]byte("\x00OK\r\n")))
+	assert.NotNil(t, validator.Validate([]byte("OK\r\n")))
+}
+
+func TestParseCommandLineArgumentsWithResponseValidationStatusByte(t *testing.T) {
+	validator, err := newResponseValidator("", "", "1:0x2")
+	assert.Nil(t, err)
+
+	assert.Nil(t, validator.Validate([]byte{0, 2}))
+	assert.Equal(t, "unexpected status byte: 0x05", validator.Validate([]byte{0, 5}).Error())
+}
+
 func TestParseCommandLineArgumentsWithBothTotalResponsesAndSuccessfulResponsesSpecified(t *testing.T) {
 	exitFunction = exitWithPanic
 	assert.Panics(t, func() {
-		assertResponseReading(true, 100, 10, 10)
+		assertResponseReading(true, 10, 10, 0)
 	})
 }
 
 func TestParseCommandLineArgumentsWithOnlyTotalResponsesSpecified(t *testing.T) {
 	exitFunction = exitWithPanic
 	assert.NotPanics(t, func() {
-		assertResponseReading(false, 100, 10, 0)
+		assertResponseReading(false, 10, 0, 0)
 	})
 }
 
 func TestParseCommandLineArgumentsWithOnlySuccessfulResponsesSpecified(t *testing.T) {
 	exitFunction = exitWithPanic
 	assert.NotPanics(t, func() {
-		assertResponseReading(false, 100, 0, 10)
+		assertResponseReading(false, 0, 10, 0)
 	})
 }
 
 func TestParseCommandLineArgumentsWithoutResponseReading(t *testing.T) {
 	exitFunction = exitWithPanic
 	assert.NotPanics(t, func() {
-		assertResponseReading(false, 100, 10, 10)
+		assertResponseReading(false, 10, 10, 0)
+	})
+}
+
+func TestParseCommandLineArgumentsWithResponseReadingWithoutTotalResponsesAndSuccessfulResponses(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertResponseReading(true, 0, 0, 0)
+	})
+}
+
+func TestParseCommandLineArgumentsWithResponseReadingAndTotalRequests(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.NotPanics(t, func() {
+		assertResponseReading(true, 0, 0, 100)
+	})
+}
+
+func TestParseCommandLineArgumentsWithUnsupportedReconnectStrategy(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertReconnectPolicy("always", time.Second, time.Second)
+	})
+}
+
+func TestParseCommandLineArgumentsWithReconnectBackoffEqualToZero(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertReconnectPolicy("backoff", 0, time.Second)
+	})
+}
+
+func TestParseCommandLineArgumentsWithReconnectPolicy(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.NotPanics(t, func() {
+		assertReconnectPolicy("backoff", 100*time.Millisecond, time.Second)
+	})
+}
+
+func TestParseCommandLineArgumentsWithChurnLifetimeLessThanZero(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertChurnPolicy(-time.Second, 0)
+	})
+}
+
+func TestParseCommandLineArgumentsWithChurnRateLessThanZero(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertChurnPolicy(0, -1)
+	})
+}
+
+func TestParseCommandLineArgumentsWithChurnPolicy(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.NotPanics(t, func() {
+		assertChurnPolicy(5*time.Second, 10)
+	})
+}
+
+func TestParseCommandLineArgumentsWithAnUnsupportedTLSVersion(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertTLSOptions("2.0", "", "")
+	})
+}
+
+func TestParseCommandLineArgumentsWithAClientCertificateWithoutKey(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertTLSOptions("", "client.pem", "")
+	})
+}
+
+func TestParseCommandLineArgumentsWithTLSOptions(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.NotPanics(t, func() {
+		assertTLSOptions("1.3", "client.pem", "client-key.pem")
+	})
+}
+
+func TestParseCommandLineArgumentsWithHandshakeAckPrefixWithoutHandshake(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertHandshake("", "\\n", "+OK", 0)
+	})
+}
+
+func TestParseCommandLineArgumentsWithNonExistingHandshakeFile(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertHandshake(filepath.Join(t.TempDir(), "handshake"), "\\n", "", 0)
+	})
+}
+
+func TestParseCommandLineArgumentsWithHandshakeTimeoutLessThanZero(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "handshake")
+	assert.Nil(t, os.WriteFile(filePath, []byte("AUTH\n"), 0644))
+
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertHandshake(filePath, "\\n", "", -time.Second)
+	})
+}
+
+func TestParseCommandLineArgumentsWithHandshake(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "handshake")
+	assert.Nil(t, os.WriteFile(filePath, []byte("AUTH\n"), 0644))
+
+	exitFunction = exitWithPanic
+	assert.NotPanics(t, func() {
+		assertHandshake("", "\\n", "", 0)
+		assertHandshake(filePath, "\\r\\n", "+OK", time.Second)
 	})
 }
 
@@ -205,3 +460,222 @@ func TestParseCommandLineArgumentsWithAnExistingFile(t *testing.T) {
 		getFilePayload("./testFile")
 	})
 }
+
+func TestParseCommandLineArgumentsWithUnsupportedPayloadFormat(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertPayloadCorpus("json", "sequential")
+	})
+}
+
+func TestParseCommandLineArgumentsWithUnsupportedPayloadSelection(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertPayloadCorpus("lines", "shuffle")
+	})
+}
+
+func TestParseCommandLineArgumentsWithPayloadCorpus(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.NotPanics(t, func() {
+		assertPayloadCorpus("file", "sequential")
+		assertPayloadCorpus("manifest", "weighted")
+	})
+}
+
+func TestParseCommandLineArgumentsWithAnEmptyLinesPayloadFile(t *testing.T) {
+	file, err := os.Create("testLinesFile")
+	assert.Nil(t, err)
+	defer func() {
+		_ = os.Remove(file.Name())
+	}()
+
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		newFilePayloadGenerator("./testLinesFile", "lines", "sequential", 0)
+	})
+}
+
+func TestParseCommandLineArgumentsWithALinesPayloadFile(t *testing.T) {
+	err := os.WriteFile("testLinesFile", []byte("GET a\nGET b\n"), 0644)
+	assert.Nil(t, err)
+	defer func() {
+		_ = os.Remove("testLinesFile")
+	}()
+
+	exitFunction = exitWithPanic
+	generator := newFilePayloadGenerator("./testLinesFile", "lines", "sequential", 0)
+	assert.Equal(t, "GET a\n", string(generator.Generate(1)))
+	assert.Equal(t, "GET b\n", string(generator.Generate(2)))
+}
+
+func TestParseCommandLineArgumentsWithPayloadDataWithoutTemplate(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertPayloadTemplate(false, "file", "data.csv")
+	})
+}
+
+func TestParseCommandLineArgumentsWithPayloadTemplateOfALinesFile(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertPayloadTemplate(true, "lines", "")
+	})
+}
+
+func TestParseCommandLineArgumentsWithPayloadTemplate(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.NotPanics(t, func() {
+		assertPayloadTemplate(true, "file", "data.csv")
+		assertPayloadTemplate(false, "lines", "")
+	})
+}
+
+func TestParseCommandLineArgumentsWithAnInvalidPayloadTemplate(t *testing.T) {
+	err := os.WriteFile("testTemplateFile", []byte("GET {{unknown}}\n"), 0644)
+	assert.Nil(t, err)
+	defer func() {
+		_ = os.Remove("testTemplateFile")
+	}()
+
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		newTemplatePayloadGenerator("./testTemplateFile", "", 0)
+	})
+}
+
+func TestParseCommandLineArgumentsWithAPayloadTemplateAndData(t *testing.T) {
+	err := os.WriteFile("testTemplateFile", []byte("GET {{csv key}} {{requestId}}\n"), 0644)
+	assert.Nil(t, err)
+	err = os.WriteFile("testDataFile", []byte("key\nuser:1\nuser:2\n"), 0644)
+	assert.Nil(t, err)
+	defer func() {
+		_ = os.Remove("testTemplateFile")
+		_ = os.Remove("testDataFile")
+	}()
+
+	exitFunction = exitWithPanic
+	generator := newTemplatePayloadGenerator("./testTemplateFile", "./testDataFile", 0)
+	assert.Equal(t, "GET user:1 1\n", string(generator.Generate(1)))
+	assert.Equal(t, "GET user:2 2\n", string(generator.Generate(2)))
+}
+
+func TestParseCommandLineArgumentsWithRequestIdOffsetSmallerThanMinusOne(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertRequestIdOffset(-2)
+	})
+}
+
+func TestParseCommandLineArgumentsWithRequestIdOffset(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.NotPanics(t, func() {
+		assertRequestIdOffset(0)
+	})
+}
+
+func TestParseCommandLineArgumentsWithUnsupportedReportFormat(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertReportFormat("xml")
+	})
+}
+
+func TestParseCommandLineArgumentsWithReportFormat(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.NotPanics(t, func() {
+		assertReportFormat("csv")
+	})
+}
+
+func TestParseCommandLineArgumentsWithReportWindowEqualToZero(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertReportWindow(time.Duration(0))
+	})
+}
+
+func TestParseCommandLineArgumentsWithReportWindow(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.NotPanics(t, func() {
+		assertReportWindow(time.Second)
+	})
+}
+
+func TestParseCommandLineArgumentsWithNegativeProgressInterval(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertProgressInterval(-1 * time.Second)
+	})
+}
+
+func TestParseCommandLineArgumentsWithProgressInterval(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.NotPanics(t, func() {
+		assertProgressInterval(time.Second)
+	})
+}
+
+func TestParseCommandLineArgumentsWithArrivalRateLessThanZero(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertArrivalRate(-1)
+	})
+}
+
+func TestParseCommandLineArgumentsWithArrivalRate(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.NotPanics(t, func() {
+		assertArrivalRate(0)
+		assertArrivalRate(100)
+	})
+}
+
+func TestParseCommandLineArgumentsWithInvalidLoadProfile(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertLoadProfile("60s", 10)
+	})
+}
+
+func TestParseCommandLineArgumentsWithLoadProfileNeedingMoreWorkersThanConcurrency(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertLoadProfile("60s:0-100,10s:100@20", 10)
+	})
+}
+
+func TestParseCommandLineArgumentsWithLoadProfile(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.NotPanics(t, func() {
+		assertLoadProfile("", 10)
+		assertLoadProfile("60s:0-100,10s:100@5,30s:100-0", 10)
+	})
+}
+
+func TestPayloadSeedWithoutTheSeedFlag(t *testing.T) {
+	flagSet := flag.NewFlagSet("blast", flag.ContinueOnError)
+	flagSet.Int64("fseed", 0, "")
+	assert.Nil(t, flagSet.Parse([]string{}))
+
+	now := time.Unix(100, 25)
+	assert.Equal(t, now.UnixNano(), payloadSeedOf(flagSet, now))
+	assert.NotEqual(t, payloadSeedOf(flagSet, now), payloadSeedOf(flagSet, now.Add(time.Nanosecond)))
+}
+
+func TestPayloadSeedWithTheSeedFlag(t *testing.T) {
+	flagSet := flag.NewFlagSet("blast", flag.ContinueOnError)
+	flagSet.Int64("fseed", 0, "")
+	assert.Nil(t, flagSet.Parse([]string{"-fseed", "7"}))
+
+	assert.Equal(t, int64(7), payloadSeedOf(flagSet, time.Unix(100, 25)))
+	assert.Equal(t, int64(7), payloadSeedOf(flagSet, time.Unix(200, 25)))
+}
+
+func TestPayloadSeedWithTheSeedFlagOfZero(t *testing.T) {
+	flagSet := flag.NewFlagSet("blast", flag.ContinueOnError)
+	flagSet.Int64("fseed", 0, "")
+	assert.Nil(t, flagSet.Parse([]string{"-fseed", "0"}))
+
+	assert.Equal(t, int64(0), payloadSeedOf(flagSet, time.Unix(100, 25)))
+}
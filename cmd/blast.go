@@ -1,9 +1,11 @@
 package blast
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/SarthakMakhija/blast-core/report"
@@ -12,12 +14,18 @@ import (
 
 // OutputStream defines a io.Writer to write the report to.
 // Currently, os.Stdout is the only supported io.Writer.
-// The entire system writes the error messages to os.Stderr.
+// The entire system writes the error messages, the banner and the status messages (for example: [Load completed])
+// to os.Stderr, so that a report in the JSON or CSV format on os.Stdout can be parsed.
+// The report can also be written to a file, identified by ReportOptions.FilePath.
 var OutputStream io.Writer = os.Stdout
 
 // MaxResponsesToRead is the size of the responseChannel on which the responses read from the server are sent.
 const MaxResponsesToRead = 10_00_000
 
+// DefaultDrainTimeout is the maximum time to wait for the responses of the requests that are already sent, after
+// the workers.GroupOptions.TotalRequests have been sent, if ResponseOptions.DrainTimeout is not specified.
+const DefaultDrainTimeout = 5 * time.Second
+
 // ResponseReadingOption defines the type of responses to read before the load on the target server stops.
 // 1) Total responses
 // 2) Successful responses
@@ -29,12 +37,117 @@ const (
 )
 
 // ResponseOptions defines the options for reading responses from the target server.
+// ResponseFramer is optional, if not specified, the responses are framed as fixed size responses of
+// ResponsePayloadSizeBytes.
+// RequestIdExtractor is optional, if specified, the latency of each response is measured by correlating
+// the response with its request. A request whose response is not read within the ReadDeadline (or the maximum
+// duration of the run, if the ReadDeadline is not specified) is then counted as a failed response with
+// report.ErrResponseTimeout.
+// ResponseValidator is optional, if specified, each response is classified as successful or failed by it.
+// The failed responses are counted in report.ResponseMetrics.ErrorCountByType and not in
+// TotalSuccessfulResponsesToRead.
+// DrainTimeout is the maximum time to wait for the responses of the requests that are already sent, after
+// the workers.GroupOptions.TotalRequests have been sent, DefaultDrainTimeout is used if it is zero.
 type ResponseOptions struct {
+	ResponseFramer                 report.ResponseFramer
 	ResponsePayloadSizeBytes       int64
 	TotalResponsesToRead           uint
 	TotalSuccessfulResponsesToRead uint
 	ReadingOption                  ResponseReadingOption
 	ReadDeadline                   time.Duration
+	RequestIdExtractor             report.RequestIdExtractor
+	ResponseValidator              report.ResponseValidator
+	DrainTimeout                   time.Duration
+}
+
+// ReportOptions defines the options for writing the report.
+// If FilePath is empty, the report is written to OutputStream in the given Format.
+// If FilePath is specified, the human-readable (text) report is written to OutputStream and the report
+// in the given Format is written to the file identified by FilePath.
+// TimeSeriesWindow is the size of each window in the report.TimeSeries, report.DefaultTimeSeriesWindow is
+// used if it is zero.
+// If ProgressInterval is greater than zero, the Progress is written to ProgressStream every ProgressInterval,
+// and ProgressCallback (if specified) is invoked with the same Progress.
+// If MetricsAddress is specified (host:port), the live metrics of the run are exposed on the /metrics path of an
+// HTTP listener on the address in the Prometheus text format, till the run completes.
+// If SLOThresholds are specified, WaitForCompletion evaluates the report against them and writes the SLOResult
+// after the human-readable report, or to os.Stderr if the report on OutputStream is in the JSON or CSV format.
+// The SLOResult is returned by Blast.EvaluateSLO. Only the blast CLI exits the application with
+// SLOBreachedExitCode if any of the thresholds is breached.
+// The zero value of ReportOptions writes the human-readable report to OutputStream.
+type ReportOptions struct {
+	Format           report.Format
+	FilePath         string
+	TimeSeriesWindow time.Duration
+	ProgressInterval time.Duration
+	ProgressCallback func(Progress)
+	MetricsAddress   string
+	SLOThresholds    *SLOThresholds
+}
+
+// reporterOptions returns the report.ReporterOptions.
+// If the workers.GroupOptions follow a workers.LoadProfile, the metrics of each stage are reported separately.
+// liveMetrics is nil if the live metrics are not exposed.
+// startTime is the start time of the workers.WorkerGroup, which is the start of the time series and the stages.
+func (reportOptions ReportOptions) reporterOptions(
+	groupOptions workers.GroupOptions,
+	liveMetrics *report.LiveMetrics,
+	startTime time.Time,
+) report.ReporterOptions {
+	reporterOptions := report.ReporterOptions{
+		TimeSeriesWindow: reportOptions.TimeSeriesWindow,
+		LiveMetrics:      liveMetrics,
+		StartTime:        startTime,
+	}
+	if loadProfile := groupOptions.LoadProfile(); loadProfile != nil {
+		reporterOptions.Stages = loadProfile.ReportStages()
+	}
+	return reporterOptions
+}
+
+// progressReporter returns a new instance of progressReporter, or nil if the progress is not to be reported.
+func (reportOptions ReportOptions) progressReporter(
+	reporter *report.Reporter,
+	responseReader *report.ResponseReader,
+	workerGroup *workers.WorkerGroup,
+) *progressReporter {
+	if reportOptions.ProgressInterval <= 0 {
+		return nil
+	}
+	return newProgressReporter(
+		reporter,
+		responseReader,
+		workerGroup,
+		reportOptions.ProgressInterval,
+		reportOptions.ProgressCallback,
+	)
+}
+
+// liveMetrics returns a new instance of report.LiveMetrics, or nil if the live metrics are not to be exposed.
+func (reportOptions ReportOptions) liveMetrics() *report.LiveMetrics {
+	if reportOptions.MetricsAddress == "" {
+		return nil
+	}
+	return report.NewLiveMetrics()
+}
+
+// metricsServer starts a new instance of metricsServer exposing the liveMetrics, or returns nil if the live
+// metrics are not to be exposed. The number of the active connections is taken from the workers.WorkerGroup.
+// Any error in starting the metricsServer is written to os.Stderr, and the run continues without it.
+func (reportOptions ReportOptions) metricsServer(
+	liveMetrics *report.LiveMetrics,
+	workerGroup *workers.WorkerGroup,
+) *metricsServer {
+	if liveMetrics == nil {
+		return nil
+	}
+	liveMetrics.SetActiveConnections(workerGroup.TotalConnectionsAlive)
+	server, err := startMetricsServer(reportOptions.MetricsAddress, liveMetrics)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "[Blast] metrics listener: %v\n", err.Error())
+		return nil
+	}
+	return server
 }
 
 // Blast runs the workers for sending the load, starting the reporters and waiting for the process to complete.
@@ -44,18 +157,36 @@ type Blast struct {
 	responseReader                *report.ResponseReader
 	groupOptions                  workers.GroupOptions
 	responseOptions               ResponseOptions
+	reportOptions                 ReportOptions
 	workerGroup                   *workers.WorkerGroup
 	loadGenerationResponseChannel chan report.LoadGenerationResponse
 	responseChannel               chan report.SubjectServerResponse
 	doneChannel                   chan struct{}
+	doneOnce                      *sync.Once
 	keepConnectionsAlive          bool
+	progress                      *progressReporter
+	metrics                       *metricsServer
+	exitOnSLOBreach               bool
 }
 
 // NewBlastWithoutResponseReading returns a new instance of Blast that does not read responses from the target server.
+// The human-readable report is written to OutputStream.
 func NewBlastWithoutResponseReading(
 	workerGroupOptions workers.GroupOptions,
 	keepConnectionsAlive bool,
 ) Blast {
+	return NewBlastWithoutResponseReadingWithReportOptions(workerGroupOptions, ReportOptions{}, keepConnectionsAlive)
+}
+
+// NewBlastWithoutResponseReadingWithReportOptions returns a new instance of Blast that does not read responses from
+// the target server, and writes the report as defined by the ReportOptions.
+func NewBlastWithoutResponseReadingWithReportOptions(
+	workerGroupOptions workers.GroupOptions,
+	reportOptions ReportOptions,
+	keepConnectionsAlive bool,
+) Blast {
+	liveMetrics := reportOptions.liveMetrics()
+
 	// startLoad starts the workers for sending load on the target server.
 	startLoad := func() (*workers.WorkerGroup, chan report.LoadGenerationResponse) {
 		workerGroup := workers.NewWorkerGroup(workerGroupOptions)
@@ -63,9 +194,14 @@ func NewBlastWithoutResponseReading(
 	}
 
 	// startReporter starts the reporter.
-	startReporter := func(loadGenerationResponseChannel chan report.LoadGenerationResponse) *report.Reporter {
-		reporter := report.
-			NewLoadGenerationMetricsCollectingReporter(loadGenerationResponseChannel)
+	startReporter := func(
+		loadGenerationResponseChannel chan report.LoadGenerationResponse,
+		startTime time.Time,
+	) *report.Reporter {
+		reporter := report.NewLoadGenerationMetricsCollectingReporterWithOptions(
+			loadGenerationResponseChannel,
+			reportOptions.reporterOptions(workerGroupOptions, liveMetrics, startTime),
+		)
 
 		reporter.Run()
 		return reporter
@@ -74,15 +210,19 @@ func NewBlastWithoutResponseReading(
 	// setUpBlast creates a new instance of Blast.
 	setUpBlast := func() Blast {
 		workerGroup, loadGenerationResponseChannel := startLoad()
-		reporter := startReporter(loadGenerationResponseChannel)
+		reporter := startReporter(loadGenerationResponseChannel, workerGroup.StartTime())
 
 		return Blast{
 			reporter:                      reporter,
 			groupOptions:                  workerGroupOptions,
+			reportOptions:                 reportOptions,
 			workerGroup:                   workerGroup,
 			loadGenerationResponseChannel: loadGenerationResponseChannel,
 			doneChannel:                   make(chan struct{}),
+			doneOnce:                      &sync.Once{},
 			keepConnectionsAlive:          keepConnectionsAlive,
+			progress:                      reportOptions.progressReporter(reporter, nil, workerGroup),
+			metrics:                       reportOptions.metricsServer(liveMetrics, workerGroup),
 		}
 	}
 
@@ -90,20 +230,50 @@ func NewBlastWithoutResponseReading(
 }
 
 // NewBlastWithResponseReading creates a new instance of Blast that reads responses from the target server.
+// The human-readable report is written to OutputStream.
 func NewBlastWithResponseReading(
 	workerGroupOptions workers.GroupOptions,
 	responseOptions ResponseOptions,
 	keepConnectionsAlive bool,
 ) Blast {
+	return NewBlastWithResponseReadingWithReportOptions(
+		workerGroupOptions,
+		responseOptions,
+		ReportOptions{},
+		keepConnectionsAlive,
+	)
+}
+
+// NewBlastWithResponseReadingWithReportOptions creates a new instance of Blast that reads responses from the target
+// server, and writes the report as defined by the ReportOptions.
+// It panics if the ResponseOptions do not specify a ResponseFramer and the ResponsePayloadSizeBytes is not positive,
+// NewRunner validates the options before creating a Blast.
+func NewBlastWithResponseReadingWithReportOptions(
+	workerGroupOptions workers.GroupOptions,
+	responseOptions ResponseOptions,
+	reportOptions ReportOptions,
+	keepConnectionsAlive bool,
+) Blast {
+	liveMetrics := reportOptions.liveMetrics()
+	responseFramer := responseOptions.ResponseFramer
+	if responseFramer == nil {
+		fixedSizeFramer, err := report.NewFixedSizeResponseFramer(responseOptions.ResponsePayloadSizeBytes)
+		if err != nil {
+			panic(fmt.Sprintf("[Blast] response payload size %v: %v", responseOptions.ResponsePayloadSizeBytes, err))
+		}
+		responseFramer = fixedSizeFramer
+	}
+
 	// newResponseReader creates a new instance of ResponseReader that reads responses from the target server.
 	newResponseReader := func() (*report.ResponseReader, chan report.SubjectServerResponse) {
 		responseChannel := make(chan report.SubjectServerResponse, MaxResponsesToRead)
-		return report.NewResponseReader(
-				responseOptions.ResponsePayloadSizeBytes,
-				responseOptions.ReadDeadline,
-				responseChannel,
-			),
-			responseChannel
+		responseReader := report.NewResponseReaderFullyLoaded(
+			responseFramer,
+			responseOptions.ReadDeadline,
+			responseOptions.RequestIdExtractor,
+			responseChannel,
+		).WithResponseValidator(responseOptions.ResponseValidator)
+		return responseReader.WithInFlightTimeout(responseOptions.inFlightTimeout(workerGroupOptions)), responseChannel
 	}
 
 	// startLoad starts the workers for sending load on the target server.
@@ -116,9 +286,13 @@ func NewBlastWithResponseReading(
 	startReporter := func(
 		loadGenerationResponseChannel chan report.LoadGenerationResponse,
 		responseChannel chan report.SubjectServerResponse,
+		startTime time.Time,
 	) *report.Reporter {
-		reporter := report.
-			NewResponseMetricsCollectingReporter(loadGenerationResponseChannel, responseChannel)
+		reporter := report.NewResponseMetricsCollectingReporterWithOptions(
+			loadGenerationResponseChannel,
+			responseChannel,
+			reportOptions.reporterOptions(workerGroupOptions, liveMetrics, startTime),
+		)
 
 		reporter.Run()
 		return reporter
@@ -128,18 +302,22 @@ func NewBlastWithResponseReading(
 	setUpBlast := func() Blast {
 		responseReader, responseChannel := newResponseReader()
 		workerGroup, loadGenerationResponseChannel := startLoad(responseReader)
-		reporter := startReporter(loadGenerationResponseChannel, responseChannel)
+		reporter := startReporter(loadGenerationResponseChannel, responseChannel, workerGroup.StartTime())
 
 		return Blast{
 			reporter:                      reporter,
 			responseReader:                responseReader,
 			responseOptions:               responseOptions,
 			groupOptions:                  workerGroupOptions,
+			reportOptions:                 reportOptions,
 			workerGroup:                   workerGroup,
 			loadGenerationResponseChannel: loadGenerationResponseChannel,
 			responseChannel:               responseChannel,
 			doneChannel:                   make(chan struct{}),
+			doneOnce:                      &sync.Once{},
 			keepConnectionsAlive:          keepConnectionsAlive,
+			progress:                      reportOptions.progressReporter(reporter, responseReader, workerGroup),
+			metrics:                       reportOptions.metricsServer(liveMetrics, workerGroup),
 		}
 	}
 
@@ -150,19 +328,61 @@ func NewBlastWithResponseReading(
 // Case1:
 // Consider that Blast is configured to run without response reading. In this case, WaitForCompletion will finish, if:
 // Blast has run for the specified maximum duration or,
+// The workers.GroupOptions.TotalRequests have been sent (if configured) or,
 // Blast is made to stop.
 // If keepConnectionsAlive, then Blast will keep running until a termination signal is sent.
 // Case2:
 // Consider that Blast is configured to run with response reading. In this case, WaitForCompletion will finish, if:
 // The total responses or the total successful responses have been read from the target server or,
+// The workers.GroupOptions.TotalRequests have been sent (if configured), and their responses have been read
+// or ResponseOptions.DrainTimeout has elapsed or,
 // Blast has run for the specified maximum duration.
 // Blast is made to stop.
 // If keepConnectionsAlive, then Blast will keep running until a termination signal is sent.
+// The report is evaluated against the ReportOptions.SLOThresholds (if specified) once it is written.
 func (blast Blast) WaitForCompletion() {
+	blast.waitTillDone()
+	blast.writeReport()
+	blast.writeSLOResult()
+}
+
+// Run waits for the load to complete (as described in WaitForCompletion) or the context to be done, and returns
+// the report.Report without writing it to OutputStream.
+// If the context is done before the load completes, Blast is stopped, and the report.Report of the load till then
+// is returned along with the error of the context.
+func (blast Blast) Run(ctx context.Context) (*report.Report, error) {
+	completed := make(chan struct{})
+	defer close(completed)
+	go func() {
+		select {
+		case <-ctx.Done():
+			blast.Stop()
+		case <-completed:
+		}
+	}()
+
+	blast.waitTillDone()
+	runReport := blast.Report()
+	return &runReport, ctx.Err()
+}
+
+// Report returns the report.Report of the completed Blast.
+// Report can only be called after WaitForCompletion.
+func (blast Blast) Report() report.Report {
+	return blast.reporter.Report()
+}
+
+// waitTillDone waits for the load to complete (as described in WaitForCompletion), without writing the report.
+// The metrics of the connections (for example: reconnects) and the metrics of the dials are added to the report
+// once the load completes.
+func (blast Blast) waitTillDone() {
+	defer func() {
+		blast.reporter.AddConnectionMetrics(blast.workerGroup.ConnectionMetrics())
+		blast.reporter.AddDialMetrics(blast.workerGroup.DialMetrics())
+	}()
 	if blast.keepConnectionsAlive {
-		<-blast.doneChannel
+		blast.waitForStop()
 		blast.stopAll()
-		blast.reporter.PrintReport(OutputStream)
 		return
 	}
 
@@ -172,18 +392,68 @@ func (blast Blast) WaitForCompletion() {
 		blast.waitForLoadToComplete()
 	}
 	<-blast.doneChannel
+}
+
+// writeReport writes the report as defined by the ReportOptions.
+// Any error in writing the report to the file is written to os.Stderr.
+func (blast Blast) writeReport() {
+	if blast.reportOptions.FilePath == "" {
+		_ = blast.reporter.WriteReport(OutputStream, blast.reportOptions.Format)
+		return
+	}
 	blast.reporter.PrintReport(OutputStream)
+
+	file, err := os.Create(blast.reportOptions.FilePath)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "[Blast] %v\n", err.Error())
+		return
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	if err := blast.reporter.WriteReport(file, blast.reportOptions.Format); err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "[Blast] %v\n", err.Error())
+	}
+}
+
+// EvaluateSLO evaluates the report against the ReportOptions.SLOThresholds, it returns nil if the SLOThresholds
+// are not specified.
+// EvaluateSLO can only be called after WaitForCompletion.
+func (blast Blast) EvaluateSLO() *SLOResult {
+	if blast.reportOptions.SLOThresholds == nil {
+		return nil
+	}
+	result := blast.reportOptions.SLOThresholds.Evaluate(blast.Report())
+	return &result
+}
+
+// writeSLOResult writes the SLOResult (if the SLOThresholds are specified) after the human-readable report on
+// OutputStream, or to os.Stderr so that a report in the JSON or CSV format on OutputStream can be parsed.
+// It exits the application with SLOBreachedExitCode if any of the thresholds is breached, only if Blast is
+// created by the blast CLI.
+func (blast Blast) writeSLOResult() {
+	result := blast.EvaluateSLO()
+	if result == nil {
+		return
+	}
+	var writer io.Writer = OutputStream
+	if blast.reportOptions.FilePath == "" && blast.reportOptions.Format != report.TextFormat {
+		writer = os.Stderr
+	}
+	_ = result.Print(writer)
+	if !result.Passed && blast.exitOnSLOBreach {
+		exitWithCode(SLOBreachedExitCode)
+	}
 }
 
 // Stop stops the blast, usually called when an interrupt is received from the CLI.
 func (blast Blast) Stop() {
-	if !isClosed(blast.doneChannel) {
-		close(blast.doneChannel)
-	}
+	blast.closeDoneChannel()
 }
 
 // waitForLoadToComplete finishes if either of the conditions are true:
 // Blast has run for the specified maximum duration or,
+// The workers.GroupOptions.TotalRequests have been sent (if configured) or,
 // Blast is made to stop.
 func (blast Blast) waitForLoadToComplete() {
 	loadReportedInspectionTimer := time.NewTicker(5 * time.Millisecond)
@@ -196,10 +466,18 @@ func (blast Blast) waitForLoadToComplete() {
 			blast.stopAll()
 		}
 
+		loadDoneChannel := blast.workerGroup.DoneChannel()
 		for {
 			select {
-			case <-blast.workerGroup.DoneChannel():
-				_, _ = fmt.Fprintln(os.Stdout, "[Load completed]")
+			case <-loadDoneChannel:
+				_, _ = fmt.Fprintln(os.Stderr, "[Load completed]")
+				if blast.groupOptions.TotalRequests() > 0 {
+					stopAll()
+					return
+				}
+				loadDoneChannel = nil
+			case now := <-loadReportedInspectionTimer.C:
+				blast.inspectProgress(now)
 			case <-maxRunTimer.C:
 				stopAll()
 				return
@@ -213,6 +491,8 @@ func (blast Blast) waitForLoadToComplete() {
 
 // waitForResponsesToComplete finishes if either of the conditions are true:
 // The total responses or the total successful responses have been read from the target server or,
+// The workers.GroupOptions.TotalRequests have been sent (if configured), and their responses have been read
+// or ResponseOptions.DrainTimeout has elapsed or,
 // Blast has run for the specified maximum duration.
 // Blast is made to stop.
 func (blast Blast) waitForResponsesToComplete() {
@@ -226,11 +506,25 @@ func (blast Blast) waitForResponsesToComplete() {
 			blast.stopAll()
 		}
 
+		var drainTimer <-chan time.Time
+		loadDoneChannel := blast.workerGroup.DoneChannel()
 		for {
 			select {
-			case <-blast.workerGroup.DoneChannel():
-				_, _ = fmt.Fprintln(os.Stdout, "[Load completed]")
-			case <-responsesCapturedInspectionTimer.C:
+			case <-loadDoneChannel:
+				_, _ = fmt.Fprintln(os.Stderr, "[Load completed]")
+				if blast.groupOptions.TotalRequests() > 0 {
+					drainTimer = time.After(blast.responseOptions.drainTimeout())
+				}
+				loadDoneChannel = nil
+			case <-drainTimer:
+				stopAll()
+				return
+			case now := <-responsesCapturedInspectionTimer.C:
+				blast.inspectProgress(now)
+				if drainTimer != nil && blast.allResponsesDrained() {
+					stopAll()
+					return
+				}
 				if blast.responseOptions.ReadingOption == ReadTotalResponses {
 					if blast.responseReader.TotalResponsesRead() >= uint64(
 						blast.responseOptions.TotalResponsesToRead) {
@@ -255,28 +549,79 @@ func (blast Blast) waitForResponsesToComplete() {
 	}()
 }
 
-// isClosed returns true if the channel is closed, false otherwise.
-func isClosed(ch <-chan struct{}) bool {
-	select {
-	case _, ok := <-ch:
-		if !ok {
-			return true
-		}
+// allResponsesDrained returns true if the responses of all the successfully sent requests have been read,
+// after all the load is reported.
+func (blast Blast) allResponsesDrained() bool {
+	if len(blast.loadGenerationResponseChannel) > 0 {
 		return false
-	default:
 	}
-	return false
+	successfulLoad := blast.reporter.TotalLoadReportedTillNow() - blast.reporter.TotalLoadErrorsReportedTillNow()
+	return blast.responseReader.TotalResponsesRead() >= successfulLoad
+}
+
+// inFlightTimeout returns the duration after which a request without its response is counted as timed out:
+// the ReadDeadline, or the maximum duration of the run if the ReadDeadline is not specified.
+func (responseOptions ResponseOptions) inFlightTimeout(groupOptions workers.GroupOptions) time.Duration {
+	if responseOptions.ReadDeadline > 0 {
+		return responseOptions.ReadDeadline
+	}
+	return groupOptions.MaxDuration()
+}
+
+// drainTimeout returns the DrainTimeout, or DefaultDrainTimeout if it is not specified.
+func (responseOptions ResponseOptions) drainTimeout() time.Duration {
+	if responseOptions.DrainTimeout > 0 {
+		return responseOptions.DrainTimeout
+	}
+	return DefaultDrainTimeout
+}
+
+// waitForStop waits till Blast is made to stop, reporting the progress (if configured) in the meantime.
+func (blast Blast) waitForStop() {
+	if blast.progress == nil {
+		<-blast.doneChannel
+		return
+	}
+	progressInspectionTimer := time.NewTicker(5 * time.Millisecond)
+	defer progressInspectionTimer.Stop()
+
+	for {
+		select {
+		case now := <-progressInspectionTimer.C:
+			blast.inspectProgress(now)
+		case <-blast.doneChannel:
+			return
+		}
+	}
+}
+
+// inspectProgress reports the progress, if it is configured.
+func (blast Blast) inspectProgress(now time.Time) {
+	if blast.progress != nil {
+		blast.progress.inspect(now)
+	}
+}
+
+// closeDoneChannel closes the doneChannel once, it is closed by both Stop and stopAll.
+func (blast Blast) closeDoneChannel() {
+	blast.doneOnce.Do(func() {
+		close(blast.doneChannel)
+	})
 }
 
 // stopAll stops all the other components of blast.
+// The connections of the workers are closed once the workers are done, which also stops reading the responses
+// from them. The responseChannel is closed only after the ResponseReader is closed, which waits for its goroutines.
 func (blast Blast) stopAll() {
 	blast.workerGroup.Close()
+	blast.workerGroup.CloseConnections()
 	if blast.responseReader != nil {
 		blast.responseReader.Close()
 		close(blast.responseChannel)
 	}
 	close(blast.loadGenerationResponseChannel)
-	if !isClosed(blast.doneChannel) {
-		close(blast.doneChannel)
+	if blast.metrics != nil {
+		blast.metrics.close()
 	}
+	blast.closeDoneChannel()
 }
@@ -0,0 +1,74 @@
+package blast
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/SarthakMakhija/blast-core/report"
+)
+
+// ComparisonRegressedExitCode is the exit status of blast when the candidate report regresses from the baseline
+// report beyond the tolerances.
+const ComparisonRegressedExitCode = 3
+
+// CompareReportFiles reads the baseline and the candidate reports (written in the report.JsonFormat) from the
+// files, and compares the candidate with the baseline (see report.Compare).
+func CompareReportFiles(baselinePath, candidatePath string, tolerances report.Tolerances) (report.Comparison, error) {
+	baseline, err := readJsonReportFile(baselinePath)
+	if err != nil {
+		return report.Comparison{}, err
+	}
+	candidate, err := readJsonReportFile(candidatePath)
+	if err != nil {
+		return report.Comparison{}, err
+	}
+	return report.Compare(baseline, candidate, tolerances), nil
+}
+
+// readJsonReportFile reads the report (written in the report.JsonFormat) from the file.
+func readJsonReportFile(filePath string) (report.Report, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return report.Report{}, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+	runReport, err := report.ReadJsonReport(file)
+	if err != nil {
+		return report.Report{}, fmt.Errorf("invalid report %v: %w", filePath, err)
+	}
+	return runReport, nil
+}
+
+// compareAndExit compares the candidate report with the baseline report (the files), writes the comparison to
+// OutputStream and exits the application with ComparisonRegressedExitCode if the candidate regresses.
+func compareAndExit(filePaths []string) {
+	if len(filePaths) != 2 {
+		exitFunction("-cmp requires the baseline and the candidate report files.")
+		return
+	}
+	assertTolerances(*throughputTolerance, *latencyTolerance, *errorsTolerance)
+	comparison, err := CompareReportFiles(filePaths[0], filePaths[1], report.Tolerances{
+		Throughput: *throughputTolerance,
+		Latency:    *latencyTolerance,
+		Errors:     *errorsTolerance,
+	})
+	if err != nil {
+		exitFunction(fmt.Sprintf("-cmp: %v.", err))
+		return
+	}
+	_ = comparison.Print(OutputStream)
+	if comparison.Regressed {
+		exitWithCode(ComparisonRegressedExitCode)
+		return
+	}
+	exitWithCode(0)
+}
+
+// assertTolerances asserts that the tolerances of the comparison are not smaller than zero.
+func assertTolerances(throughput, latency, errors float64) {
+	if throughput < 0 || latency < 0 || errors < 0 {
+		exitFunction("-cmptp, -cmplt and -cmpet cannot be smaller than zero.")
+	}
+}
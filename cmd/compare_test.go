@@ -0,0 +1,77 @@
+package blast
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/SarthakMakhija/blast-core/report"
+)
+
+func writeReportFile(t *testing.T, name string, successCount uint) string {
+	filePath := filepath.Join(t.TempDir(), name)
+	content := fmt.Sprintf(`{"Load": {"TotalRequests": %d, "SuccessCount": %d, "TotalTime": 10000000000}}`, successCount, successCount)
+	assert.Nil(t, os.WriteFile(filePath, []byte(content), 0644))
+	return filePath
+}
+
+func TestCompareReportFiles(t *testing.T) {
+	comparison, err := CompareReportFiles(
+		writeReportFile(t, "baseline.json", 500),
+		writeReportFile(t, "candidate.json", 400),
+		report.Tolerances{Throughput: 5},
+	)
+	assert.Nil(t, err)
+	assert.True(t, comparison.Regressed)
+	assert.Equal(t, 50.0, comparison.Metrics[0].Baseline)
+	assert.Equal(t, 40.0, comparison.Metrics[0].Candidate)
+}
+
+func TestCompareReportFilesWithAMissingFile(t *testing.T) {
+	_, err := CompareReportFiles(
+		writeReportFile(t, "baseline.json", 500),
+		filepath.Join(t.TempDir(), "candidate.json"),
+		report.Tolerances{},
+	)
+	assert.Error(t, err)
+}
+
+func TestCompareAndExitWithARegression(t *testing.T) {
+	exitCode := -1
+	exitWithCode = func(code int) {
+		exitCode = code
+	}
+	OutputStream = &bytes.Buffer{}
+
+	compareAndExit([]string{writeReportFile(t, "baseline.json", 500), writeReportFile(t, "candidate.json", 400)})
+	assert.Equal(t, ComparisonRegressedExitCode, exitCode)
+}
+
+func TestCompareAndExitWithoutARegression(t *testing.T) {
+	exitCode := -1
+	exitWithCode = func(code int) {
+		exitCode = code
+	}
+	OutputStream = &bytes.Buffer{}
+
+	compareAndExit([]string{writeReportFile(t, "baseline.json", 500), writeReportFile(t, "candidate.json", 500)})
+	assert.Equal(t, 0, exitCode)
+}
+
+func TestCompareAndExitWithoutTheCandidateReport(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		compareAndExit([]string{"baseline.json"})
+	})
+}
+
+func TestParseCommandLineArgumentsWithNegativeTolerance(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertTolerances(5, -1, 0)
+	})
+}
@@ -0,0 +1,286 @@
+package blast
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ErrUnsupportedConfigFileFormat is the error that is returned when the config file is neither JSON nor YAML.
+var ErrUnsupportedConfigFileFormat = errors.New("config file must be a JSON (.json) or a YAML (.yaml, .yml) file")
+
+// ConfigFile defines a blast run in a JSON or YAML file, so that the run can be checked in and re-executed.
+// Each field corresponds to a command line flag (mentioned against the field), and the fields that are not
+// specified keep the default of their flags. The durations are specified as strings, for example: "5s".
+// The flags that are specified on the command line override the values in the ConfigFile, and the urls that
+// are specified on the command line override the Targets.
+// The relative paths of the input files (-f, -fcsv, -tlsca, -tlscert, -tlskey and -hs) are relative to the
+// directory of the config file, so that the run is the same from any working directory. The report file (-ro)
+// is an output, and is relative to the working directory like on the command line.
+type ConfigFile struct {
+	Targets              []string        `json:"targets" yaml:"targets"`
+	Concurrency          *uint           `json:"concurrency" yaml:"concurrency"`                   // -c
+	Connections          *uint           `json:"connections" yaml:"connections"`                   // -conn
+	KeepConnectionsAlive *bool           `json:"keepConnectionsAlive" yaml:"keepConnectionsAlive"` // -kA
+	LoadBalancing        *string         `json:"loadBalancing" yaml:"loadBalancing"`               // -lb
+	HashKeyOffset        *uint           `json:"hashKeyOffset" yaml:"hashKeyOffset"`               // -lbko
+	HashKeyLength        *uint           `json:"hashKeyLength" yaml:"hashKeyLength"`               // -lbkl
+	RequestsPerSecond    *float64        `json:"requestsPerSecond" yaml:"requestsPerSecond"`       // -rps
+	ArrivalRate          *float64        `json:"arrivalRate" yaml:"arrivalRate"`                   // -ar
+	LoadProfile          *string         `json:"loadProfile" yaml:"loadProfile"`                   // -lp
+	Duration             *string         `json:"duration" yaml:"duration"`                         // -z
+	TotalRequests        *uint           `json:"totalRequests" yaml:"totalRequests"`               // -n
+	ConnectTimeout       *string         `json:"connectTimeout" yaml:"connectTimeout"`             // -t
+	PayloadFile          *string         `json:"payloadFile" yaml:"payloadFile"`                   // -f
+	PayloadFormat        *string         `json:"payloadFormat" yaml:"payloadFormat"`               // -ff
+	PayloadSelection     *string         `json:"payloadSelection" yaml:"payloadSelection"`         // -fs
+	PayloadSeed          *int64          `json:"payloadSeed" yaml:"payloadSeed"`                   // -fseed
+	PayloadTemplate      *bool           `json:"payloadTemplate" yaml:"payloadTemplate"`           // -ft
+	PayloadData          *string         `json:"payloadData" yaml:"payloadData"`                   // -fcsv
+	Reconnect            ReconnectConfig `json:"reconnect" yaml:"reconnect"`
+	Churn                ChurnConfig     `json:"churn" yaml:"churn"`
+	TLS                  TLSConfig       `json:"tls" yaml:"tls"`
+	Handshake            HandshakeConfig `json:"handshake" yaml:"handshake"`
+	Responses            ResponsesConfig `json:"responses" yaml:"responses"`
+	Output               OutputConfig    `json:"output" yaml:"output"`
+	SLO                  SLOConfig       `json:"slo" yaml:"slo"`
+	Cpus                 *int            `json:"cpus" yaml:"cpus"` // -cpus
+}
+
+// ReconnectConfig defines the reconnect policy in the ConfigFile.
+type ReconnectConfig struct {
+	Strategy   *string `json:"strategy" yaml:"strategy"`     // -rc
+	Attempts   *uint   `json:"attempts" yaml:"attempts"`     // -rca
+	Backoff    *string `json:"backoff" yaml:"backoff"`       // -rcb
+	MaxBackoff *string `json:"maxBackoff" yaml:"maxBackoff"` // -rcm
+}
+
+// ChurnConfig defines the churn policy in the ConfigFile.
+type ChurnConfig struct {
+	Requests *uint    `json:"requests" yaml:"requests"` // -chn
+	Lifetime *string  `json:"lifetime" yaml:"lifetime"` // -cht
+	Rate     *float64 `json:"rate" yaml:"rate"`         // -chr
+}
+
+// TLSConfig defines the TLS options in the ConfigFile.
+type TLSConfig struct {
+	Enabled            *bool   `json:"enabled" yaml:"enabled"`                       // -tls
+	CAFile             *string `json:"caFile" yaml:"caFile"`                         // -tlsca
+	CertFile           *string `json:"certFile" yaml:"certFile"`                     // -tlscert
+	KeyFile            *string `json:"keyFile" yaml:"keyFile"`                       // -tlskey
+	ServerName         *string `json:"serverName" yaml:"serverName"`                 // -tlssni
+	InsecureSkipVerify *bool   `json:"insecureSkipVerify" yaml:"insecureSkipVerify"` // -tlsk
+	MinVersion         *string `json:"minVersion" yaml:"minVersion"`                 // -tlsmin
+	SessionResumption  *bool   `json:"sessionResumption" yaml:"sessionResumption"`   // -tlsr
+}
+
+// HandshakeConfig defines the handshake of each connection in the ConfigFile.
+type HandshakeConfig struct {
+	File         *string `json:"file" yaml:"file"`                 // -hs
+	AckDelimiter *string `json:"ackDelimiter" yaml:"ackDelimiter"` // -hsd
+	AckPrefix    *string `json:"ackPrefix" yaml:"ackPrefix"`       // -hsa
+	Timeout      *string `json:"timeout" yaml:"timeout"`           // -hst
+}
+
+// ResponsesConfig defines the response reading options in the ConfigFile.
+type ResponsesConfig struct {
+	Read                *bool   `json:"read" yaml:"read"`                               // -Rr
+	PayloadSize         *int64  `json:"payloadSize" yaml:"payloadSize"`                 // -Rrs
+	Framing             *string `json:"framing" yaml:"framing"`                         // -Rf
+	Delimiter           *string `json:"delimiter" yaml:"delimiter"`                     // -Rfd
+	ReadDeadline        *string `json:"readDeadline" yaml:"readDeadline"`               // -Rrd
+	TotalResponses      *uint   `json:"totalResponses" yaml:"totalResponses"`           // -Rtr
+	SuccessfulResponses *uint   `json:"successfulResponses" yaml:"successfulResponses"` // -Rsr
+	RequestIdOffset     *int    `json:"requestIdOffset" yaml:"requestIdOffset"`         // -Rid
+	ValidPrefix         *string `json:"validPrefix" yaml:"validPrefix"`                 // -Rvp
+	ValidPattern        *string `json:"validPattern" yaml:"validPattern"`               // -Rvr
+	ValidStatusByte     *string `json:"validStatusByte" yaml:"validStatusByte"`         // -Rvs
+}
+
+// OutputConfig defines the output (report, progress and metrics) options in the ConfigFile.
+type OutputConfig struct {
+	ReportFormat     *string `json:"reportFormat" yaml:"reportFormat"`         // -rf
+	ReportFile       *string `json:"reportFile" yaml:"reportFile"`             // -ro
+	ReportWindow     *string `json:"reportWindow" yaml:"reportWindow"`         // -rw
+	ProgressInterval *string `json:"progressInterval" yaml:"progressInterval"` // -pi
+	MetricsAddress   *string `json:"metricsAddress" yaml:"metricsAddress"`     // -pm
+}
+
+// SLOConfig defines the SLO thresholds in the ConfigFile.
+type SLOConfig struct {
+	MaxErrorRate        *float64        `json:"maxErrorRate" yaml:"maxErrorRate"`               // -Ser
+	MinThroughput       *float64        `json:"minThroughput" yaml:"minThroughput"`             // -Stp
+	MaxP99Latency       *string         `json:"maxP99Latency" yaml:"maxP99Latency"`             // -Sp99
+	MinResponseRatio    *float64        `json:"minResponseRatio" yaml:"minResponseRatio"`       // -Srr
+	MaxErrorCountByType map[string]uint `json:"maxErrorCountByType" yaml:"maxErrorCountByType"` // -Set
+}
+
+// LoadConfigFile loads the ConfigFile from the JSON or YAML file, identified by the extension of the file.
+// The fields that are not defined by the ConfigFile are rejected, to catch the typos in the file.
+func LoadConfigFile(filePath string) (ConfigFile, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return ConfigFile{}, err
+	}
+	var config ConfigFile
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(content))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&config)
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(content))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&config)
+	default:
+		return ConfigFile{}, ErrUnsupportedConfigFileFormat
+	}
+	if err != nil {
+		return ConfigFile{}, fmt.Errorf("invalid config file %v: %w", filePath, err)
+	}
+	config.resolvePaths(filepath.Dir(filePath))
+	return config, nil
+}
+
+// resolvePaths resolves the relative paths of the input files in the ConfigFile against the directory.
+func (config *ConfigFile) resolvePaths(directory string) {
+	paths := []**string{
+		&config.PayloadFile,
+		&config.PayloadData,
+		&config.TLS.CAFile,
+		&config.TLS.CertFile,
+		&config.TLS.KeyFile,
+		&config.Handshake.File,
+	}
+	for _, path := range paths {
+		if *path == nil || **path == "" || filepath.IsAbs(**path) {
+			continue
+		}
+		resolved := filepath.Join(directory, **path)
+		*path = &resolved
+	}
+}
+
+// flagValues returns the values of the fields that are specified in the ConfigFile, by the names of their flags.
+// A flag that can be repeated (-Set) has multiple values.
+func (config ConfigFile) flagValues() map[string][]string {
+	values := make(map[string][]string)
+	addFlagValue(values, "c", config.Concurrency)
+	addFlagValue(values, "conn", config.Connections)
+	addFlagValue(values, "kA", config.KeepConnectionsAlive)
+	addFlagValue(values, "lb", config.LoadBalancing)
+	addFlagValue(values, "lbko", config.HashKeyOffset)
+	addFlagValue(values, "lbkl", config.HashKeyLength)
+	addFlagValue(values, "rps", config.RequestsPerSecond)
+	addFlagValue(values, "ar", config.ArrivalRate)
+	addFlagValue(values, "lp", config.LoadProfile)
+	addFlagValue(values, "z", config.Duration)
+	addFlagValue(values, "n", config.TotalRequests)
+	addFlagValue(values, "t", config.ConnectTimeout)
+	addFlagValue(values, "f", config.PayloadFile)
+	addFlagValue(values, "ff", config.PayloadFormat)
+	addFlagValue(values, "fs", config.PayloadSelection)
+	addFlagValue(values, "fseed", config.PayloadSeed)
+	addFlagValue(values, "ft", config.PayloadTemplate)
+	addFlagValue(values, "fcsv", config.PayloadData)
+	addFlagValue(values, "rc", config.Reconnect.Strategy)
+	addFlagValue(values, "rca", config.Reconnect.Attempts)
+	addFlagValue(values, "rcb", config.Reconnect.Backoff)
+	addFlagValue(values, "rcm", config.Reconnect.MaxBackoff)
+	addFlagValue(values, "chn", config.Churn.Requests)
+	addFlagValue(values, "cht", config.Churn.Lifetime)
+	addFlagValue(values, "chr", config.Churn.Rate)
+	addFlagValue(values, "tls", config.TLS.Enabled)
+	addFlagValue(values, "tlsca", config.TLS.CAFile)
+	addFlagValue(values, "tlscert", config.TLS.CertFile)
+	addFlagValue(values, "tlskey", config.TLS.KeyFile)
+	addFlagValue(values, "tlssni", config.TLS.ServerName)
+	addFlagValue(values, "tlsk", config.TLS.InsecureSkipVerify)
+	addFlagValue(values, "tlsmin", config.TLS.MinVersion)
+	addFlagValue(values, "tlsr", config.TLS.SessionResumption)
+	addFlagValue(values, "hs", config.Handshake.File)
+	addFlagValue(values, "hsd", config.Handshake.AckDelimiter)
+	addFlagValue(values, "hsa", config.Handshake.AckPrefix)
+	addFlagValue(values, "hst", config.Handshake.Timeout)
+	addFlagValue(values, "Rr", config.Responses.Read)
+	addFlagValue(values, "Rrs", config.Responses.PayloadSize)
+	addFlagValue(values, "Rf", config.Responses.Framing)
+	addFlagValue(values, "Rfd", config.Responses.Delimiter)
+	addFlagValue(values, "Rrd", config.Responses.ReadDeadline)
+	addFlagValue(values, "Rtr", config.Responses.TotalResponses)
+	addFlagValue(values, "Rsr", config.Responses.SuccessfulResponses)
+	addFlagValue(values, "Rid", config.Responses.RequestIdOffset)
+	addFlagValue(values, "Rvp", config.Responses.ValidPrefix)
+	addFlagValue(values, "Rvr", config.Responses.ValidPattern)
+	addFlagValue(values, "Rvs", config.Responses.ValidStatusByte)
+	addFlagValue(values, "rf", config.Output.ReportFormat)
+	addFlagValue(values, "ro", config.Output.ReportFile)
+	addFlagValue(values, "rw", config.Output.ReportWindow)
+	addFlagValue(values, "pi", config.Output.ProgressInterval)
+	addFlagValue(values, "pm", config.Output.MetricsAddress)
+	addFlagValue(values, "cpus", config.Cpus)
+	addFlagValue(values, "Ser", config.SLO.MaxErrorRate)
+	addFlagValue(values, "Stp", config.SLO.MinThroughput)
+	addFlagValue(values, "Sp99", config.SLO.MaxP99Latency)
+	addFlagValue(values, "Srr", config.SLO.MinResponseRatio)
+	for errorType, count := range config.SLO.MaxErrorCountByType {
+		values["Set"] = append(values["Set"], fmt.Sprintf("%v=%d", errorType, count))
+	}
+	return values
+}
+
+// addFlagValue adds the value of the flag identified by the name, if the value is specified.
+func addFlagValue[T any](values map[string][]string, name string, value *T) {
+	if value != nil {
+		values[name] = append(values[name], fmt.Sprint(*value))
+	}
+}
+
+// applyConfigFile sets the flags from the ConfigFile, except the flags that are specified on the command line,
+// and returns the urls to run against: the urls on the command line, or the Targets of the ConfigFile.
+// The flags are then validated by the same assertions as the command line flags.
+func applyConfigFile(flagSet *flag.FlagSet, config ConfigFile, commandLineUrls []string) ([]string, error) {
+	setOnCommandLine := make(map[string]bool)
+	flagSet.Visit(func(flag *flag.Flag) {
+		setOnCommandLine[flag.Name] = true
+	})
+	for name, values := range config.flagValues() {
+		if setOnCommandLine[name] {
+			continue
+		}
+		for _, value := range values {
+			if err := flagSet.Set(name, value); err != nil {
+				return nil, fmt.Errorf("invalid value %v of -%v in the config file: %w", value, name, err)
+			}
+		}
+	}
+	if len(commandLineUrls) > 0 {
+		return commandLineUrls, nil
+	}
+	return config.Targets, nil
+}
+
+// urlsWithConfigFile applies the config file (if specified) to the flags, and returns the urls to run against.
+func urlsWithConfigFile(filePath string) []string {
+	if filePath == "" {
+		return flag.Args()
+	}
+	config, err := LoadConfigFile(filePath)
+	if err != nil {
+		exitFunction(err.Error())
+		return nil
+	}
+	urls, err := applyConfigFile(flag.CommandLine, config, flag.Args())
+	if err != nil {
+		exitFunction(err.Error())
+		return nil
+	}
+	return urls
+}
@@ -0,0 +1,219 @@
+package blast
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	filePath := filepath.Join(t.TempDir(), name)
+	assert.Nil(t, os.WriteFile(filePath, []byte(content), 0644))
+	return filePath
+}
+
+func newConfigFlagSet() (*flag.FlagSet, *uint, *time.Duration, *bool, *string) {
+	flagSet := flag.NewFlagSet("blast", flag.ContinueOnError)
+	concurrency := flagSet.Uint("c", 50, "")
+	duration := flagSet.Duration("z", 20*time.Second, "")
+	readResponses := flagSet.Bool("Rr", false, "")
+	framing := flagSet.String("Rf", "fixed", "")
+	return flagSet, concurrency, duration, readResponses, framing
+}
+
+func TestLoadJSONConfigFile(t *testing.T) {
+	filePath := writeConfigFile(t, "run.json", `{
+  "targets": ["localhost:8080", "localhost:8081"],
+  "concurrency": 100,
+  "duration": "30s",
+  "responses": {"read": true, "framing": "len4be"}
+}`)
+	config, err := LoadConfigFile(filePath)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"localhost:8080", "localhost:8081"}, config.Targets)
+	assert.Equal(t, uint(100), *config.Concurrency)
+	assert.Equal(t, "30s", *config.Duration)
+	assert.True(t, *config.Responses.Read)
+	assert.Equal(t, "len4be", *config.Responses.Framing)
+	assert.Nil(t, config.Connections)
+}
+
+func TestLoadYAMLConfigFile(t *testing.T) {
+	filePath := writeConfigFile(t, "run.yaml", `
+targets:
+  - tcp://localhost:8080
+concurrency: 20
+tls:
+  enabled: true
+  minVersion: "1.2"
+output:
+  reportFormat: json
+`)
+	config, err := LoadConfigFile(filePath)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"tcp://localhost:8080"}, config.Targets)
+	assert.Equal(t, uint(20), *config.Concurrency)
+	assert.True(t, *config.TLS.Enabled)
+	assert.Equal(t, "1.2", *config.TLS.MinVersion)
+	assert.Equal(t, "json", *config.Output.ReportFormat)
+}
+
+func TestLoadConfigFileResolvesTheInputPathsAgainstItsDirectory(t *testing.T) {
+	filePath := writeConfigFile(t, "run.yaml", `
+payloadFile: payloads/payload.txt
+payloadData: /data/rows.csv
+tls:
+  caFile: certs/ca.pem
+  certFile: certs/client.pem
+  keyFile: certs/client.key
+handshake:
+  file: hello.bin
+output:
+  reportFile: report.json
+`)
+	config, err := LoadConfigFile(filePath)
+	directory := filepath.Dir(filePath)
+
+	assert.Nil(t, err)
+	assert.Equal(t, filepath.Join(directory, "payloads", "payload.txt"), *config.PayloadFile)
+	assert.Equal(t, "/data/rows.csv", *config.PayloadData)
+	assert.Equal(t, filepath.Join(directory, "certs", "ca.pem"), *config.TLS.CAFile)
+	assert.Equal(t, filepath.Join(directory, "certs", "client.pem"), *config.TLS.CertFile)
+	assert.Equal(t, filepath.Join(directory, "certs", "client.key"), *config.TLS.KeyFile)
+	assert.Equal(t, filepath.Join(directory, "hello.bin"), *config.Handshake.File)
+	assert.Equal(t, "report.json", *config.Output.ReportFile)
+}
+
+func TestLoadJSONConfigFileWithAnUnknownField(t *testing.T) {
+	filePath := writeConfigFile(t, "run.json", `{"concurrency": 100, "concurency": 10}`)
+	_, err := LoadConfigFile(filePath)
+
+	assert.Error(t, err)
+}
+
+func TestLoadYAMLConfigFileWithAnUnknownField(t *testing.T) {
+	filePath := writeConfigFile(t, "run.yml", "concurrency: 100\nconcurency: 10\n")
+	_, err := LoadConfigFile(filePath)
+
+	assert.Error(t, err)
+}
+
+func TestLoadConfigFileWithAnUnsupportedFormat(t *testing.T) {
+	filePath := writeConfigFile(t, "run.toml", "concurrency = 100")
+	_, err := LoadConfigFile(filePath)
+
+	assert.ErrorIs(t, err, ErrUnsupportedConfigFileFormat)
+}
+
+func TestLoadConfigFileThatDoesNotExist(t *testing.T) {
+	_, err := LoadConfigFile(filepath.Join(t.TempDir(), "run.json"))
+
+	assert.Error(t, err)
+}
+
+func TestApplyConfigFileSetsTheFlags(t *testing.T) {
+	flagSet, concurrency, duration, readResponses, framing := newConfigFlagSet()
+	assert.Nil(t, flagSet.Parse(nil))
+
+	filePath := writeConfigFile(t, "run.json", `{
+  "targets": ["localhost:8080"],
+  "concurrency": 100,
+  "duration": "30s",
+  "responses": {"read": true}
+}`)
+	config, err := LoadConfigFile(filePath)
+	assert.Nil(t, err)
+
+	urls, err := applyConfigFile(flagSet, config, flagSet.Args())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"localhost:8080"}, urls)
+	assert.Equal(t, uint(100), *concurrency)
+	assert.Equal(t, 30*time.Second, *duration)
+	assert.True(t, *readResponses)
+	assert.Equal(t, "fixed", *framing)
+}
+
+func TestApplyConfigFileWithTheCommandLineOverridingTheConfigFile(t *testing.T) {
+	flagSet, concurrency, duration, _, _ := newConfigFlagSet()
+	assert.Nil(t, flagSet.Parse([]string{"-c", "10", "localhost:9090"}))
+
+	filePath := writeConfigFile(t, "run.yaml", `
+targets:
+  - localhost:8080
+concurrency: 100
+duration: 30s
+`)
+	config, err := LoadConfigFile(filePath)
+	assert.Nil(t, err)
+
+	urls, err := applyConfigFile(flagSet, config, flagSet.Args())
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"localhost:9090"}, urls)
+	assert.Equal(t, uint(10), *concurrency)
+	assert.Equal(t, 30*time.Second, *duration)
+}
+
+func TestApplyConfigFileWithAnInvalidValue(t *testing.T) {
+	flagSet, _, _, _, _ := newConfigFlagSet()
+	assert.Nil(t, flagSet.Parse(nil))
+
+	filePath := writeConfigFile(t, "run.json", `{"duration": "thirty seconds"}`)
+	config, err := LoadConfigFile(filePath)
+	assert.Nil(t, err)
+
+	_, err = applyConfigFile(flagSet, config, flagSet.Args())
+	assert.Error(t, err)
+}
+
+func TestUrlsWithAConfigFileThatDoesNotExist(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		urlsWithConfigFile(filepath.Join(t.TempDir(), "run.json"))
+	})
+}
+
+func TestConfigFileFieldsAreTheFlags(t *testing.T) {
+	filePath := writeConfigFile(t, "run.yaml", `
+concurrency: 1
+connections: 1
+keepConnectionsAlive: true
+loadBalancing: hash
+hashKeyOffset: 1
+hashKeyLength: 1
+requestsPerSecond: 1
+arrivalRate: 1
+loadProfile: 1s:1
+duration: 1s
+totalRequests: 1
+connectTimeout: 1s
+payloadFile: payload
+payloadFormat: lines
+payloadSelection: random
+payloadSeed: 7
+payloadTemplate: true
+payloadData: data.csv
+reconnect: {strategy: backoff, attempts: 1, backoff: 1s, maxBackoff: 1s}
+churn: {requests: 1, lifetime: 1s, rate: 1}
+tls: {enabled: true, caFile: ca, certFile: cert, keyFile: key, serverName: sni, insecureSkipVerify: true, minVersion: "1.2", sessionResumption: true}
+handshake: {file: handshake, ackDelimiter: "\\n", ackPrefix: "+OK", timeout: 1s}
+responses: {read: true, payloadSize: 1, framing: delim, delimiter: "\\n", readDeadline: 1s, totalResponses: 1, successfulResponses: 1, requestIdOffset: 1, validPrefix: "OK", validPattern: "^OK", validStatusByte: "0:0x00"}
+output: {reportFormat: json, reportFile: report, reportWindow: 1s, progressInterval: 1s, metricsAddress: "localhost:9100"}
+cpus: 1
+slo: {maxErrorRate: 0.01, minThroughput: 100, maxP99Latency: 50ms, minResponseRatio: 0.9, maxErrorCountByType: {"connection reset": 1, "timeout": 2}}
+`)
+	config, err := LoadConfigFile(filePath)
+	assert.Nil(t, err)
+
+	values := config.flagValues()
+	assert.Equal(t, 59, len(values))
+	assert.ElementsMatch(t, []string{"connection reset=1", "timeout=2"}, values["Set"])
+	for name := range values {
+		assert.NotNil(t, flag.Lookup(name), name)
+	}
+}
@@ -0,0 +1,59 @@
+package blast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/SarthakMakhija/blast-core/report"
+)
+
+// metricsShutdownTimeout is the maximum time to wait for the scrapes in progress, when the metricsServer is closed.
+const metricsShutdownTimeout = time.Second
+
+// metricsServer is the HTTP listener that exposes the report.LiveMetrics in the Prometheus text format on
+// the /metrics path.
+type metricsServer struct {
+	server   *http.Server
+	listener net.Listener
+}
+
+// startMetricsServer creates a new instance of metricsServer listening on the address, and starts serving the
+// scrapes.
+func startMetricsServer(address string, liveMetrics *report.LiveMetrics) (*metricsServer, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = liveMetrics.WritePrometheus(writer)
+	})
+	server := &metricsServer{
+		server:   &http.Server{Handler: mux, ReadHeaderTimeout: metricsShutdownTimeout},
+		listener: listener,
+	}
+	go func() {
+		if err := server.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			_, _ = fmt.Fprintf(os.Stderr, "[MetricsServer] %v\n", err.Error())
+		}
+	}()
+	return server, nil
+}
+
+// address returns the address the metricsServer is listening on.
+func (server *metricsServer) address() string {
+	return server.listener.Addr().String()
+}
+
+// close stops the metricsServer, waiting for the scrapes in progress (at most metricsShutdownTimeout).
+func (server *metricsServer) close() {
+	ctx, cancel := context.WithTimeout(context.Background(), metricsShutdownTimeout)
+	defer cancel()
+	_ = server.server.Shutdown(ctx)
+}
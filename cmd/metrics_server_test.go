@@ -0,0 +1,53 @@
+package blast
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/SarthakMakhija/blast-core/report"
+)
+
+func TestMetricsServerExposesTheLiveMetrics(t *testing.T) {
+	server, err := startMetricsServer("localhost:0", report.NewLiveMetrics())
+	assert.Nil(t, err)
+	defer server.close()
+
+	response, err := http.Get("http://" + server.address() + "/metrics")
+	assert.Nil(t, err)
+	defer func() {
+		_ = response.Body.Close()
+	}()
+
+	body, err := io.ReadAll(response.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Contains(t, response.Header.Get("Content-Type"), "text/plain; version=0.0.4")
+	assert.Contains(t, string(body), "blast_requests_sent_total 0")
+}
+
+func TestMetricsServerWithAnAddressInUse(t *testing.T) {
+	server, err := startMetricsServer("localhost:0", report.NewLiveMetrics())
+	assert.Nil(t, err)
+	defer server.close()
+
+	_, err = startMetricsServer(server.address(), report.NewLiveMetrics())
+	assert.Error(t, err)
+}
+
+func TestParseCommandLineArgumentsWithAnInvalidMetricsAddress(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.Panics(t, func() {
+		assertMetricsAddress("9100")
+	})
+}
+
+func TestParseCommandLineArgumentsWithMetricsAddress(t *testing.T) {
+	exitFunction = exitWithPanic
+	assert.NotPanics(t, func() {
+		assertMetricsAddress("localhost:9100")
+		assertMetricsAddress(":9100")
+	})
+}
@@ -0,0 +1,126 @@
+package blast
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/SarthakMakhija/blast-core/report"
+	"github.com/SarthakMakhija/blast-core/workers"
+)
+
+// ProgressStream defines a io.Writer to write the progress to.
+// The progress is written to os.Stderr so that it does not mix with the report written on OutputStream.
+var ProgressStream io.Writer = os.Stderr
+
+// Progress represents the progress of a running Blast.
+// RequestsPerSecond is the rate of the requests sent since the previous Progress.
+// ErrorRate is the ratio of the failed requests and responses to the total requests and responses.
+type Progress struct {
+	Elapsed           time.Duration
+	RequestsSent      uint64
+	ResponsesRead     uint64
+	RequestsPerSecond float64
+	ErrorRate         float64
+	ConnectionsAlive  uint
+}
+
+// String returns the Progress as a single line.
+func (progress Progress) String() string {
+	return fmt.Sprintf(
+		"[Progress] elapsed: %v, requests: %d, responses: %d, rps: %.2f, error rate: %.2f%%, connections alive: %d",
+		progress.Elapsed.Round(time.Millisecond),
+		progress.RequestsSent,
+		progress.ResponsesRead,
+		progress.RequestsPerSecond,
+		progress.ErrorRate*100,
+		progress.ConnectionsAlive,
+	)
+}
+
+// progressReporter reports the Progress of Blast every interval.
+// It writes the Progress on ProgressStream and invokes the callback (if any).
+// progressReporter is driven by the inspection tickers of Blast, so it is not thread-safe.
+type progressReporter struct {
+	reporter             *report.Reporter
+	responseReader       *report.ResponseReader
+	workerGroup          *workers.WorkerGroup
+	interval             time.Duration
+	callback             func(Progress)
+	startTime            time.Time
+	lastReportedTime     time.Time
+	lastReportedRequests uint64
+}
+
+// newProgressReporter creates a new instance of progressReporter.
+// responseReader is nil if Blast does not read responses.
+func newProgressReporter(
+	reporter *report.Reporter,
+	responseReader *report.ResponseReader,
+	workerGroup *workers.WorkerGroup,
+	interval time.Duration,
+	callback func(Progress),
+) *progressReporter {
+	now := time.Now()
+	return &progressReporter{
+		reporter:         reporter,
+		responseReader:   responseReader,
+		workerGroup:      workerGroup,
+		interval:         interval,
+		callback:         callback,
+		startTime:        now,
+		lastReportedTime: now,
+	}
+}
+
+// inspect reports the Progress if the interval has elapsed since the last reported Progress.
+func (reporter *progressReporter) inspect(now time.Time) {
+	if now.Sub(reporter.lastReportedTime) < reporter.interval {
+		return
+	}
+	progress := reporter.progress(now)
+	_, _ = fmt.Fprintln(ProgressStream, progress.String())
+	if reporter.callback != nil {
+		reporter.callback(progress)
+	}
+	reporter.lastReportedTime = now
+	reporter.lastReportedRequests = progress.RequestsSent
+}
+
+// progress computes the Progress at the given time.
+// The ResponseReader counts a successful response before counting it in the total responses, so the successful
+// responses (loaded after the total responses) are at least the successful responses among the total responses,
+// and they are bounded by the total responses, since more responses can be read between the loads.
+func (reporter *progressReporter) progress(now time.Time) Progress {
+	requestsSent := reporter.reporter.TotalLoadReportedTillNow()
+	errors := reporter.reporter.TotalLoadErrorsReportedTillNow()
+
+	var responsesRead uint64
+	if reporter.responseReader != nil {
+		responsesRead = reporter.responseReader.TotalResponsesRead()
+		successfulResponses := reporter.responseReader.TotalSuccessfulResponsesRead()
+		if successfulResponses > responsesRead {
+			successfulResponses = responsesRead
+		}
+		errors = errors + (responsesRead - successfulResponses)
+	}
+
+	var errorRate float64
+	if requestsSent+responsesRead > 0 {
+		errorRate = float64(errors) / float64(requestsSent+responsesRead)
+	}
+
+	var requestsPerSecond float64
+	if elapsedSinceLastReport := now.Sub(reporter.lastReportedTime).Seconds(); elapsedSinceLastReport > 0 {
+		requestsPerSecond = float64(requestsSent-reporter.lastReportedRequests) / elapsedSinceLastReport
+	}
+	return Progress{
+		Elapsed:           now.Sub(reporter.startTime),
+		RequestsSent:      requestsSent,
+		ResponsesRead:     responsesRead,
+		RequestsPerSecond: requestsPerSecond,
+		ErrorRate:         errorRate,
+		ConnectionsAlive:  reporter.workerGroup.TotalConnectionsAlive(),
+	}
+}
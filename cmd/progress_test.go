@@ -0,0 +1,120 @@
+package blast
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SarthakMakhija/blast-core/payload"
+	"github.com/SarthakMakhija/blast-core/report"
+	"github.com/SarthakMakhija/blast-core/workers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressAsString(t *testing.T) {
+	progress := Progress{
+		Elapsed:           2 * time.Second,
+		RequestsSent:      200,
+		ResponsesRead:     150,
+		RequestsPerSecond: 100,
+		ErrorRate:         0.05,
+		ConnectionsAlive:  4,
+	}
+	assert.Equal(
+		t,
+		"[Progress] elapsed: 2s, requests: 200, responses: 150, rps: 100.00, error rate: 5.00%, connections alive: 4",
+		progress.String(),
+	)
+}
+
+func TestProgressIsNotReportedBeforeTheInterval(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	ProgressStream = buffer
+
+	loadGenerationChannel := make(chan report.LoadGenerationResponse)
+	reporter := report.NewLoadGenerationMetricsCollectingReporter(loadGenerationChannel)
+	workerGroup := workers.NewWorkerGroup(
+		workers.NewGroupOptions(1, payload.NewConstantPayloadGenerator([]byte("HelloWorld")), "localhost:8080", time.Second),
+	)
+
+	invoked := false
+	progressReporter := newProgressReporter(reporter, nil, workerGroup, time.Minute, func(progress Progress) {
+		invoked = true
+	})
+	progressReporter.inspect(time.Now())
+
+	assert.False(t, invoked)
+	assert.Equal(t, 0, buffer.Len())
+}
+
+func TestProgressIsReportedAfterTheInterval(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	ProgressStream = buffer
+
+	loadGenerationChannel := make(chan report.LoadGenerationResponse, 4)
+	reporter := report.NewLoadGenerationMetricsCollectingReporter(loadGenerationChannel)
+	reporter.Run()
+
+	loadGenerationChannel <- report.LoadGenerationResponse{PayloadLengthBytes: 10}
+	loadGenerationChannel <- report.LoadGenerationResponse{PayloadLengthBytes: 10}
+	loadGenerationChannel <- report.LoadGenerationResponse{PayloadLengthBytes: 10}
+	loadGenerationChannel <- report.LoadGenerationResponse{Err: errors.New("test error")}
+	time.Sleep(2 * time.Millisecond)
+	close(loadGenerationChannel)
+
+	workerGroup := workers.NewWorkerGroup(
+		workers.NewGroupOptions(1, payload.NewConstantPayloadGenerator([]byte("HelloWorld")), "localhost:8080", time.Second),
+	)
+
+	var reported []Progress
+	progressReporter := newProgressReporter(reporter, nil, workerGroup, time.Second, func(progress Progress) {
+		reported = append(reported, progress)
+	})
+	progressReporter.inspect(progressReporter.startTime.Add(2 * time.Second))
+
+	assert.Equal(t, 1, len(reported))
+	assert.Equal(t, uint64(4), reported[0].RequestsSent)
+	assert.Equal(t, uint64(0), reported[0].ResponsesRead)
+	assert.Equal(t, 2.0, reported[0].RequestsPerSecond)
+	assert.Equal(t, 0.25, reported[0].ErrorRate)
+	assert.Equal(t, uint(0), reported[0].ConnectionsAlive)
+	assert.True(t, strings.HasPrefix(buffer.String(), "[Progress] elapsed: 2s, requests: 4"))
+}
+
+func TestProgressErrorRateWhileTheResponsesAreRead(t *testing.T) {
+	loadGenerationChannel := make(chan report.LoadGenerationResponse)
+	reporter := report.NewLoadGenerationMetricsCollectingReporter(loadGenerationChannel)
+	workerGroup := workers.NewWorkerGroup(
+		workers.NewGroupOptions(1, payload.NewConstantPayloadGenerator([]byte("HelloWorld")), "localhost:8080", time.Second),
+	)
+
+	responseChannel := make(chan report.SubjectServerResponse, 1024)
+	responseFramer, err := report.NewFixedSizeResponseFramer(1)
+	assert.Nil(t, err)
+	responseReader := report.NewResponseReaderFullyLoaded(responseFramer, 0, nil, responseChannel)
+	server, client := net.Pipe()
+	responseReader.StartReading(client)
+	defer func() {
+		responseReader.Close()
+		_ = server.Close()
+	}()
+
+	totalResponses := 200000
+	go func() {
+		_, _ = server.Write(bytes.Repeat([]byte{'a'}, totalResponses))
+	}()
+	go func() {
+		for range responseChannel {
+		}
+	}()
+
+	progressReporter := newProgressReporter(reporter, responseReader, workerGroup, time.Second, nil)
+	for responseReader.TotalResponsesRead() < uint64(totalResponses) {
+		if !assert.Equal(t, 0.0, progressReporter.progress(time.Now()).ErrorRate) {
+			return
+		}
+	}
+}
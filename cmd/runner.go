@@ -0,0 +1,283 @@
+package blast
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SarthakMakhija/blast-core/payload"
+	"github.com/SarthakMakhija/blast-core/report"
+	"github.com/SarthakMakhija/blast-core/workers"
+)
+
+const (
+	// DefaultConcurrency is the number of workers, if RunOptions.Concurrency is not specified.
+	DefaultConcurrency = 50
+	// DefaultRequestsPerSecond is the requests per second per worker, if -rps is not specified on the command line.
+	DefaultRequestsPerSecond = 50
+	// DefaultMaxDuration is the maximum duration of the run, if RunOptions.MaxDuration is not specified.
+	DefaultMaxDuration = 20 * time.Second
+	// DefaultConnectTimeout is the timeout for establishing a connection, if RunOptions.ConnectTimeout is not
+	// specified.
+	DefaultConnectTimeout = 3 * time.Second
+)
+
+// ErrNoTargets is the error that is returned when RunOptions do not specify any target.
+var ErrNoTargets = errors.New("at least one target must be specified")
+
+// ErrNoPayloadGenerator is the error that is returned when RunOptions do not specify the PayloadGenerator.
+var ErrNoPayloadGenerator = errors.New("payload generator must be specified")
+
+// ErrMultiplePayloadGenerators is the error that is returned when RunOptions specify both the PayloadGenerator
+// and the ContextualPayloadGenerator.
+var ErrMultiplePayloadGenerators = errors.New("only one of payload generator and contextual payload generator can be specified")
+
+// RunOptions defines a run of Blast for embedding blast in Go programs, for example: integration tests.
+// RunOptions mirror the command line options, and are validated by NewRunner: the options that have a command
+// line flag follow the rules of the flag, and the options that are specific to RunOptions (for example, the
+// generators or the HashKey) are validated by their own rules.
+// Targets are the target URLs (see workers.ParseTarget), the load is distributed across them as per the
+// LoadBalancing strategy (and the HashKey for workers.ConsistentHashBalancing) if multiple targets are specified.
+// Concurrency, Connections, MaxDuration and ConnectTimeout take their defaults (DefaultConcurrency, 1,
+// DefaultMaxDuration and DefaultConnectTimeout) if they are zero.
+// The payload of each request is generated by either the PayloadGenerator or the ContextualPayloadGenerator.
+// The requests follow the LoadProfile if it is specified, else the ArrivalRate (open model) if it is greater than
+// zero, else the RequestsPerSecond per worker (closed model), which must then be greater than zero.
+// The connections are established over TLS if TLSOptions are specified.
+// Each connection is initialized by the ConnectionInitializer (if specified) before the requests are sent on it,
+// within the ConnectionInitializationTimeout (the ConnectTimeout is used if it is zero).
+// The responses are read if ResponseOptions are specified. If neither TotalResponsesToRead nor
+// TotalSuccessfulResponsesToRead is specified, the responses of the TotalRequests are read.
+// TimeSeriesWindow, ProgressInterval and ProgressCallback are the same as in ReportOptions.
+type RunOptions struct {
+	Targets                         []string
+	LoadBalancing                   workers.LoadBalancingStrategy
+	HashKey                         workers.HashKey
+	Concurrency                     uint
+	Connections                     uint
+	PayloadGenerator                payload.PayloadGenerator
+	ContextualPayloadGenerator      payload.ContextualPayloadGenerator
+	RequestsPerSecond               float64
+	ArrivalRate                     float64
+	LoadProfile                     *workers.LoadProfile
+	MaxDuration                     time.Duration
+	TotalRequests                   uint
+	ConnectTimeout                  time.Duration
+	ReconnectPolicy                 workers.ReconnectPolicy
+	ChurnPolicy                     workers.ChurnPolicy
+	TLSOptions                      *workers.TLSOptions
+	ConnectionInitializer           workers.ConnectionInitializer
+	ConnectionInitializationTimeout time.Duration
+	ResponseOptions                 *ResponseOptions
+	TimeSeriesWindow                time.Duration
+	ProgressInterval                time.Duration
+	ProgressCallback                func(Progress)
+}
+
+// Runner runs Blast with the validated RunOptions, and returns the report.Report instead of writing it.
+// Unlike the ArgumentsParser, Runner never exits the process: the invalid options are returned as errors.
+type Runner struct {
+	groupOptions    workers.GroupOptions
+	responseOptions *ResponseOptions
+	reportOptions   ReportOptions
+}
+
+// NewRunner creates a new instance of Runner after validating the RunOptions.
+func NewRunner(options RunOptions) (*Runner, error) {
+	options = options.withDefaults()
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+	targets, err := workers.ParseTargets(options.Targets)
+	if err != nil {
+		return nil, err
+	}
+
+	groupOptions := workers.NewGroupOptionsFullyLoaded(
+		options.Concurrency,
+		options.Connections,
+		options.PayloadGenerator,
+		options.Targets[0],
+		options.ConnectTimeout,
+		options.RequestsPerSecond,
+		options.MaxDuration,
+	)
+	if options.ContextualPayloadGenerator != nil {
+		groupOptions = groupOptions.WithContextualPayloadGenerator(options.ContextualPayloadGenerator)
+	}
+	if options.LoadProfile != nil {
+		groupOptions = groupOptions.WithLoadProfile(*options.LoadProfile)
+	} else if options.ArrivalRate > 0 {
+		groupOptions = groupOptions.WithArrivalRate(options.ArrivalRate)
+	}
+	if options.TotalRequests > 0 {
+		groupOptions = groupOptions.WithTotalRequests(options.TotalRequests)
+	}
+	groupOptions = groupOptions.
+		WithReconnectPolicy(options.ReconnectPolicy).
+		WithChurnPolicy(options.ChurnPolicy)
+	if len(targets) > 1 {
+		groupOptions = groupOptions.WithTargets(targets, options.LoadBalancing).WithHashKey(options.HashKey)
+	}
+	if options.TLSOptions != nil {
+		for _, target := range targets {
+			if target.IsDatagram() {
+				return nil, workers.ErrTLSOverDatagram
+			}
+		}
+		tlsConfig, err := options.TLSOptions.Config(options.Targets[0])
+		if err != nil {
+			return nil, err
+		}
+		groupOptions = groupOptions.WithTLSConfig(tlsConfig)
+	}
+
+	if options.ConnectionInitializer != nil {
+		groupOptions = groupOptions.WithConnectionInitializer(
+			options.ConnectionInitializer,
+			options.ConnectionInitializationTimeout,
+		)
+	}
+
+	var responseOptions *ResponseOptions
+	if options.ResponseOptions != nil {
+		responseOptionsCopy := *options.ResponseOptions
+		if responseOptionsCopy.TotalResponsesToRead == 0 && responseOptionsCopy.TotalSuccessfulResponsesToRead == 0 {
+			responseOptionsCopy.ReadingOption = ReadTotalResponses
+			responseOptionsCopy.TotalResponsesToRead = options.TotalRequests
+		}
+		responseOptions = &responseOptionsCopy
+	}
+	return &Runner{
+		groupOptions:    groupOptions,
+		responseOptions: responseOptions,
+		reportOptions: ReportOptions{
+			TimeSeriesWindow: options.TimeSeriesWindow,
+			ProgressInterval: options.ProgressInterval,
+			ProgressCallback: options.ProgressCallback,
+		},
+	}, nil
+}
+
+// Run runs Blast till the load completes (as described in Blast.WaitForCompletion) or the context is done, and
+// returns the report.Report (see Blast.Run).
+func (runner *Runner) Run(ctx context.Context) (*report.Report, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var instance Blast
+	if runner.responseOptions != nil {
+		instance = NewBlastWithResponseReadingWithReportOptions(runner.groupOptions, *runner.responseOptions, runner.reportOptions, false)
+	} else {
+		instance = NewBlastWithoutResponseReadingWithReportOptions(runner.groupOptions, runner.reportOptions, false)
+	}
+	return instance.Run(ctx)
+}
+
+// withDefaults returns the RunOptions with the defaults of the options that are not specified.
+func (options RunOptions) withDefaults() RunOptions {
+	if options.Concurrency == 0 {
+		options.Concurrency = DefaultConcurrency
+	}
+	if options.Connections == 0 {
+		options.Connections = 1
+	}
+	if options.MaxDuration == 0 {
+		options.MaxDuration = DefaultMaxDuration
+	}
+	if options.ConnectTimeout == 0 {
+		options.ConnectTimeout = DefaultConnectTimeout
+	}
+	return options
+}
+
+// validate validates the RunOptions by the rules of their command line flags (see RunOptions).
+func (options RunOptions) validate() error {
+	if len(options.Targets) == 0 {
+		return ErrNoTargets
+	}
+	if options.PayloadGenerator == nil && options.ContextualPayloadGenerator == nil {
+		return ErrNoPayloadGenerator
+	}
+	if options.PayloadGenerator != nil && options.ContextualPayloadGenerator != nil {
+		return ErrMultiplePayloadGenerators
+	}
+	if options.Connections > options.Concurrency {
+		return fmt.Errorf("connections (%v) cannot be greater than concurrency (%v)", options.Connections, options.Concurrency)
+	}
+	if options.Concurrency%options.Connections != 0 {
+		return fmt.Errorf("concurrency (%v) modulo connections (%v) must be equal to zero", options.Concurrency, options.Connections)
+	}
+	if options.RequestsPerSecond < 0 {
+		return fmt.Errorf("requests per second cannot be smaller than zero")
+	}
+	if options.RequestsPerSecond == 0 && options.LoadProfile == nil && options.ArrivalRate == 0 {
+		return fmt.Errorf("requests per second must be greater than zero without an arrival rate or a load profile")
+	}
+	if options.ArrivalRate < 0 {
+		return fmt.Errorf("arrival rate cannot be smaller than zero")
+	}
+	if options.LoadBalancing > workers.ConsistentHashBalancing {
+		return fmt.Errorf("unsupported load balancing strategy %d", options.LoadBalancing)
+	}
+	if options.HashKey.Offset < 0 || options.HashKey.Length < 0 {
+		return fmt.Errorf("hash key offset and length cannot be smaller than zero")
+	}
+	if options.LoadProfile != nil && options.LoadProfile.MaxWorkers() > options.Concurrency {
+		return fmt.Errorf("workers of a load profile stage cannot be greater than concurrency (%v)", options.Concurrency)
+	}
+	if options.MaxDuration < 0 {
+		return fmt.Errorf("max duration cannot be smaller than zero")
+	}
+	if options.ConnectTimeout < 0 {
+		return fmt.Errorf("connect timeout cannot be smaller than zero")
+	}
+	if options.ReconnectPolicy.InitialBackoff < 0 || options.ReconnectPolicy.MaxBackoff < 0 {
+		return fmt.Errorf("reconnect backoff cannot be smaller than zero")
+	}
+	if options.ChurnPolicy.ConnectionLifetime < 0 || options.ChurnPolicy.NewConnectionsPerSecond < 0 {
+		return fmt.Errorf("connection lifetime and new connections per second cannot be smaller than zero")
+	}
+	if options.TLSOptions != nil && (options.TLSOptions.CertFile == "") != (options.TLSOptions.KeyFile == "") {
+		return workers.ErrIncompleteClientCertificate
+	}
+	if options.ConnectionInitializationTimeout < 0 {
+		return fmt.Errorf("connection initialization timeout cannot be smaller than zero")
+	}
+	if options.ResponseOptions != nil {
+		if err := options.ResponseOptions.validate(options.TotalRequests); err != nil {
+			return err
+		}
+	}
+	if options.TimeSeriesWindow < 0 {
+		return fmt.Errorf("time series window cannot be smaller than zero")
+	}
+	if options.ProgressInterval < 0 {
+		return fmt.Errorf("progress interval cannot be smaller than zero")
+	}
+	return nil
+}
+
+// validate validates the ResponseOptions by the same rules as the command line options for reading responses.
+func (responseOptions ResponseOptions) validate(totalRequests uint) error {
+	if responseOptions.TotalResponsesToRead > 0 && responseOptions.TotalSuccessfulResponsesToRead > 0 {
+		return fmt.Errorf("both total responses and total successful responses cannot be specified")
+	}
+	if responseOptions.TotalResponsesToRead == 0 && responseOptions.TotalSuccessfulResponsesToRead == 0 && totalRequests == 0 {
+		return fmt.Errorf("either of total responses, total successful responses or total requests must be specified")
+	}
+	if responseOptions.ReadingOption == ReadTotalResponses && responseOptions.TotalSuccessfulResponsesToRead > 0 {
+		return fmt.Errorf("total successful responses require the reading option ReadSuccessfulResponses")
+	}
+	if responseOptions.ReadingOption == ReadSuccessfulResponses && responseOptions.TotalResponsesToRead > 0 {
+		return fmt.Errorf("total responses require the reading option ReadTotalResponses")
+	}
+	if responseOptions.ResponseFramer == nil && responseOptions.ResponsePayloadSizeBytes <= 0 {
+		return fmt.Errorf("response payload size must be greater than zero without a response framer")
+	}
+	if responseOptions.ReadDeadline < 0 {
+		return fmt.Errorf("read deadline cannot be smaller than zero")
+	}
+	return nil
+}
@@ -0,0 +1,207 @@
+package blast
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/SarthakMakhija/blast-core/payload"
+	"github.com/SarthakMakhija/blast-core/workers"
+)
+
+func newRunOptions() RunOptions {
+	return RunOptions{
+		Targets:           []string{"localhost:8080"},
+		PayloadGenerator:  payload.NewConstantPayloadGenerator([]byte("HelloWorld")),
+		RequestsPerSecond: DefaultRequestsPerSecond,
+	}
+}
+
+func TestNewRunnerWithDefaults(t *testing.T) {
+	runner, err := NewRunner(newRunOptions())
+
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultMaxDuration, runner.groupOptions.MaxDuration())
+	assert.Nil(t, runner.responseOptions)
+}
+
+func TestNewRunnerWithoutTargets(t *testing.T) {
+	options := newRunOptions()
+	options.Targets = nil
+	_, err := NewRunner(options)
+
+	assert.ErrorIs(t, err, ErrNoTargets)
+}
+
+func TestNewRunnerWithAnInvalidTarget(t *testing.T) {
+	options := newRunOptions()
+	options.Targets = []string{"localhost:8080", "http://localhost:8081"}
+	_, err := NewRunner(options)
+
+	assert.Error(t, err)
+}
+
+func TestNewRunnerWithoutRequestsPerSecond(t *testing.T) {
+	options := newRunOptions()
+	options.RequestsPerSecond = 0
+	_, err := NewRunner(options)
+
+	assert.Error(t, err)
+}
+
+func TestNewRunnerWithArrivalRateWithoutRequestsPerSecond(t *testing.T) {
+	options := newRunOptions()
+	options.RequestsPerSecond = 0
+	options.ArrivalRate = 100
+	_, err := NewRunner(options)
+
+	assert.Nil(t, err)
+}
+
+func TestNewRunnerWithAnUnsupportedLoadBalancingStrategy(t *testing.T) {
+	options := newRunOptions()
+	options.Targets = []string{"localhost:8080", "localhost:8081"}
+	options.LoadBalancing = workers.ConsistentHashBalancing + 1
+	_, err := NewRunner(options)
+
+	assert.Error(t, err)
+}
+
+func TestNewRunnerWithANegativeHashKeyOffset(t *testing.T) {
+	options := newRunOptions()
+	options.Targets = []string{"localhost:8080", "localhost:8081"}
+	options.LoadBalancing = workers.ConsistentHashBalancing
+	options.HashKey = workers.HashKey{Offset: -1}
+	_, err := NewRunner(options)
+
+	assert.Error(t, err)
+}
+
+func TestNewRunnerWithoutPayloadGenerator(t *testing.T) {
+	options := newRunOptions()
+	options.PayloadGenerator = nil
+	_, err := NewRunner(options)
+
+	assert.ErrorIs(t, err, ErrNoPayloadGenerator)
+}
+
+func TestNewRunnerWithBothPayloadGenerators(t *testing.T) {
+	options := newRunOptions()
+	options.ContextualPayloadGenerator = payload.NewPayloadGeneratorAdapter(options.PayloadGenerator)
+	_, err := NewRunner(options)
+
+	assert.ErrorIs(t, err, ErrMultiplePayloadGenerators)
+}
+
+func TestNewRunnerWithContextualPayloadGenerator(t *testing.T) {
+	options := newRunOptions()
+	options.ContextualPayloadGenerator = payload.NewPayloadGeneratorAdapter(options.PayloadGenerator)
+	options.PayloadGenerator = nil
+	_, err := NewRunner(options)
+
+	assert.Nil(t, err)
+}
+
+func TestNewRunnerWithConnectionsGreaterThanConcurrency(t *testing.T) {
+	options := newRunOptions()
+	options.Concurrency = 2
+	options.Connections = 4
+	_, err := NewRunner(options)
+
+	assert.Error(t, err)
+}
+
+func TestNewRunnerWithConcurrencyNotAMultipleOfConnections(t *testing.T) {
+	options := newRunOptions()
+	options.Concurrency = 10
+	options.Connections = 3
+	_, err := NewRunner(options)
+
+	assert.Error(t, err)
+}
+
+func TestNewRunnerWithLoadProfileNeedingMoreWorkersThanConcurrency(t *testing.T) {
+	profile, err := workers.ParseLoadProfile("10s:100@20")
+	assert.Nil(t, err)
+
+	options := newRunOptions()
+	options.Concurrency = 10
+	options.LoadProfile = &profile
+	_, err = NewRunner(options)
+
+	assert.Error(t, err)
+}
+
+func TestNewRunnerWithTLSOverUdp(t *testing.T) {
+	options := newRunOptions()
+	options.Targets = []string{"udp://localhost:8080"}
+	options.TLSOptions = &workers.TLSOptions{InsecureSkipVerify: true}
+	_, err := NewRunner(options)
+
+	assert.ErrorIs(t, err, workers.ErrTLSOverDatagram)
+}
+
+func TestNewRunnerWithAnIncompleteClientCertificate(t *testing.T) {
+	options := newRunOptions()
+	options.TLSOptions = &workers.TLSOptions{CertFile: "client.pem"}
+	_, err := NewRunner(options)
+
+	assert.ErrorIs(t, err, workers.ErrIncompleteClientCertificate)
+}
+
+func TestNewRunnerWithResponseReadingWithoutResponsesToRead(t *testing.T) {
+	options := newRunOptions()
+	options.ResponseOptions = &ResponseOptions{ResponsePayloadSizeBytes: 10}
+	_, err := NewRunner(options)
+
+	assert.Error(t, err)
+}
+
+func TestNewRunnerWithResponseReadingWithoutResponsePayloadSize(t *testing.T) {
+	options := newRunOptions()
+	options.ResponseOptions = &ResponseOptions{TotalResponsesToRead: 10}
+	_, err := NewRunner(options)
+
+	assert.Error(t, err)
+}
+
+func TestNewRunnerWithResponseReadingForTotalRequests(t *testing.T) {
+	options := newRunOptions()
+	options.TotalRequests = 100
+	options.ResponseOptions = &ResponseOptions{ResponsePayloadSizeBytes: 10}
+	runner, err := NewRunner(options)
+
+	assert.Nil(t, err)
+	assert.Equal(t, ReadTotalResponses, runner.responseOptions.ReadingOption)
+	assert.Equal(t, uint(100), runner.responseOptions.TotalResponsesToRead)
+}
+
+func TestNewRunnerWithNegativeArrivalRate(t *testing.T) {
+	options := newRunOptions()
+	options.ArrivalRate = -1
+	_, err := NewRunner(options)
+
+	assert.Error(t, err)
+}
+
+func TestRunnerWithAContextThatIsAlreadyDone(t *testing.T) {
+	runner, err := NewRunner(newRunOptions())
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	runReport, err := runner.Run(ctx)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, runReport)
+}
+
+func TestNewRunnerWithNegativeMaxDuration(t *testing.T) {
+	options := newRunOptions()
+	options.MaxDuration = -time.Second
+	_, err := NewRunner(options)
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,191 @@
+package blast
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SarthakMakhija/blast-core/report"
+)
+
+// SLOBreachedExitCode is the exit status of blast when any of the SLOThresholds is breached, it is distinct from
+// the exit status for the invalid command line arguments (1).
+const SLOBreachedExitCode = 2
+
+// exitWithCode exits the application with the given status, it is replaced in the tests.
+var exitWithCode = os.Exit
+
+// SLOThresholds defines the thresholds (SLO) that the final report.Report of Blast is evaluated against, to gate
+// a CI pipeline on the performance of the target server.
+// MaxErrorRate is the maximum ratio of the failed (or dropped) requests and failed responses to the total
+// requests and responses, it is not evaluated if nil (a pointer, so that a maximum error rate of zero can be
+// enforced).
+// MinThroughput is the minimum rate of the successful requests (per second), it is not evaluated if zero.
+// MaxP99Latency is the maximum p99 latency corrected for the coordinated omission, it is not evaluated if zero.
+// MinResponseRatio is the minimum ratio of the successful responses to the requests sent, it is not evaluated
+// if zero.
+// MaxErrorCountByType is the maximum count of each type of error (the error message), across the requests and
+// the responses.
+type SLOThresholds struct {
+	MaxErrorRate        *float64
+	MinThroughput       float64
+	MaxP99Latency       time.Duration
+	MinResponseRatio    float64
+	MaxErrorCountByType map[string]uint
+}
+
+// SLOCheck represents the evaluation of a single threshold of the SLOThresholds.
+type SLOCheck struct {
+	Name      string
+	Threshold string
+	Actual    string
+	Passed    bool
+}
+
+// SLOResult represents the evaluation of the SLOThresholds, it is Passed if none of the thresholds are breached.
+type SLOResult struct {
+	Checks []SLOCheck
+	Passed bool
+}
+
+// Evaluate evaluates the report.Report against the SLOThresholds.
+func (thresholds SLOThresholds) Evaluate(runReport report.Report) SLOResult {
+	result := SLOResult{Passed: true}
+	addCheck := func(name, threshold, actual string, passed bool) {
+		result.Checks = append(result.Checks, SLOCheck{Name: name, Threshold: threshold, Actual: actual, Passed: passed})
+		result.Passed = result.Passed && passed
+	}
+
+	if thresholds.MaxErrorRate != nil {
+		errorRate := errorRateOf(runReport)
+		addCheck(
+			"error rate",
+			fmt.Sprintf("<= %.2f%%", *thresholds.MaxErrorRate*100),
+			fmt.Sprintf("%.2f%%", errorRate*100),
+			errorRate <= *thresholds.MaxErrorRate,
+		)
+	}
+	if thresholds.MinThroughput > 0 {
+		throughput := runReport.Throughput()
+		addCheck(
+			"throughput",
+			fmt.Sprintf(">= %.2f/s", thresholds.MinThroughput),
+			fmt.Sprintf("%.2f/s", throughput),
+			throughput >= thresholds.MinThroughput,
+		)
+	}
+	if thresholds.MaxP99Latency > 0 {
+		latency := runReport.Response.CorrectedLatency
+		if latency.TotalSamples == 0 {
+			addCheck("p99 latency", fmt.Sprintf("<= %v", thresholds.MaxP99Latency), "not measured", false)
+		} else {
+			addCheck(
+				"p99 latency",
+				fmt.Sprintf("<= %v", thresholds.MaxP99Latency),
+				latency.P99.String(),
+				latency.P99 <= thresholds.MaxP99Latency,
+			)
+		}
+	}
+	if thresholds.MinResponseRatio > 0 {
+		responseRatio := responseRatioOf(runReport)
+		addCheck(
+			"response ratio",
+			fmt.Sprintf(">= %.2f", thresholds.MinResponseRatio),
+			fmt.Sprintf("%.2f", responseRatio),
+			responseRatio >= thresholds.MinResponseRatio,
+		)
+	}
+	errorTypes := make([]string, 0, len(thresholds.MaxErrorCountByType))
+	for errorType := range thresholds.MaxErrorCountByType {
+		errorTypes = append(errorTypes, errorType)
+	}
+	sort.Strings(errorTypes)
+	for _, errorType := range errorTypes {
+		count := runReport.Load.ErrorCountByType[errorType] + runReport.Response.ErrorCountByType[errorType]
+		maxCount := thresholds.MaxErrorCountByType[errorType]
+		addCheck(
+			fmt.Sprintf("errors: %v", errorType),
+			fmt.Sprintf("<= %d", maxCount),
+			strconv.FormatUint(uint64(count), 10),
+			count <= maxCount,
+		)
+	}
+	return result
+}
+
+// Print prints the SLOResult on the provided io.Writer, with a table of the checks.
+func (result SLOResult) Print(writer io.Writer) error {
+	status := "PASS"
+	if !result.Passed {
+		status = "FAIL"
+	}
+	builder := &strings.Builder{}
+	_, _ = fmt.Fprintf(builder, "\nSLO: %v\n", status)
+
+	tableWriter := tabwriter.NewWriter(builder, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tableWriter, "  Check\tThreshold\tActual\tResult")
+	for _, check := range result.Checks {
+		checkStatus := "PASS"
+		if !check.Passed {
+			checkStatus = "FAIL"
+		}
+		_, _ = fmt.Fprintf(tableWriter, "  %v\t%v\t%v\t%v\n", check.Name, check.Threshold, check.Actual, checkStatus)
+	}
+	_ = tableWriter.Flush()
+
+	_, err := io.WriteString(writer, builder.String())
+	return err
+}
+
+// errorRateOf returns the ratio of the failed (or dropped) requests and failed responses to the total requests
+// and responses of the report.Report.
+func errorRateOf(runReport report.Report) float64 {
+	failed := runReport.Load.ErrorCount + runReport.Load.DroppedCount + runReport.Response.ErrorCount
+	total := runReport.Load.TotalRequests + runReport.Load.DroppedCount + runReport.Response.TotalResponses
+	if total == 0 {
+		return 0
+	}
+	return float64(failed) / float64(total)
+}
+
+// responseRatioOf returns the ratio of the successful responses to the requests sent of the report.Report.
+func responseRatioOf(runReport report.Report) float64 {
+	if runReport.Load.TotalRequests == 0 {
+		return 0
+	}
+	return float64(runReport.Response.SuccessCount) / float64(runReport.Load.TotalRequests)
+}
+
+// errorCountThresholds is the flag.Value of the maximum count of each type of error, it is specified as
+// type=count and can be repeated.
+type errorCountThresholds map[string]uint
+
+// String returns the thresholds as a comma separated list of type=count.
+func (thresholds errorCountThresholds) String() string {
+	entries := make([]string, 0, len(thresholds))
+	for errorType, count := range thresholds {
+		entries = append(entries, fmt.Sprintf("%v=%d", errorType, count))
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
+
+// Set adds the threshold of the form type=count, the type is the error message and may contain '='.
+func (thresholds errorCountThresholds) Set(value string) error {
+	separator := strings.LastIndex(value, "=")
+	if separator <= 0 {
+		return fmt.Errorf("error count threshold must be of the form type=count")
+	}
+	count, err := strconv.ParseUint(value[separator+1:], 10, 32)
+	if err != nil {
+		return fmt.Errorf("error count threshold must be of the form type=count")
+	}
+	thresholds[value[:separator]] = uint(count)
+	return nil
+}
@@ -0,0 +1,238 @@
+package blast
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/SarthakMakhija/blast-core/report"
+)
+
+func reportForSLO() report.Report {
+	runReport := report.Report{}
+	runReport.Load.TotalRequests = 100
+	runReport.Load.SuccessCount = 98
+	runReport.Load.ErrorCount = 2
+	runReport.Load.TotalTime = 2 * time.Second
+	runReport.Load.ErrorCountByType = map[string]uint{"connection reset": 2}
+	runReport.Response.TotalResponses = 100
+	runReport.Response.SuccessCount = 95
+	runReport.Response.ErrorCount = 5
+	runReport.Response.ErrorCountByType = map[string]uint{"connection reset": 1, "timeout": 4}
+	runReport.Response.CorrectedLatency = report.LatencyMetrics{TotalSamples: 95, P99: 40 * time.Millisecond}
+	return runReport
+}
+
+func errorRate(rate float64) *float64 {
+	return &rate
+}
+
+func TestSLOThresholdsArePassed(t *testing.T) {
+	thresholds := SLOThresholds{
+		MaxErrorRate:        errorRate(0.05),
+		MinThroughput:       40,
+		MaxP99Latency:       50 * time.Millisecond,
+		MinResponseRatio:    0.9,
+		MaxErrorCountByType: map[string]uint{"connection reset": 3},
+	}
+	result := thresholds.Evaluate(reportForSLO())
+
+	assert.True(t, result.Passed)
+	assert.Equal(t, 5, len(result.Checks))
+	assert.Equal(t, SLOCheck{Name: "throughput", Threshold: ">= 40.00/s", Actual: "49.00/s", Passed: true}, result.Checks[1])
+	assert.Equal(t, SLOCheck{Name: "errors: connection reset", Threshold: "<= 3", Actual: "3", Passed: true}, result.Checks[4])
+}
+
+func TestSLOThresholdsAreBreached(t *testing.T) {
+	thresholds := SLOThresholds{
+		MaxErrorRate:        errorRate(0.01),
+		MaxP99Latency:       30 * time.Millisecond,
+		MaxErrorCountByType: map[string]uint{"timeout": 3},
+	}
+	result := thresholds.Evaluate(reportForSLO())
+
+	assert.False(t, result.Passed)
+	assert.Equal(t, SLOCheck{Name: "error rate", Threshold: "<= 1.00%", Actual: "3.50%", Passed: false}, result.Checks[0])
+	assert.Equal(t, SLOCheck{Name: "p99 latency", Threshold: "<= 30ms", Actual: "40ms", Passed: false}, result.Checks[1])
+	assert.Equal(t, SLOCheck{Name: "errors: timeout", Threshold: "<= 3", Actual: "4", Passed: false}, result.Checks[2])
+}
+
+func TestSLOThresholdsWithLatencyNotMeasured(t *testing.T) {
+	runReport := reportForSLO()
+	runReport.Response.CorrectedLatency = report.LatencyMetrics{}
+
+	result := SLOThresholds{MaxP99Latency: time.Second}.Evaluate(runReport)
+	assert.False(t, result.Passed)
+	assert.Equal(t, 1, len(result.Checks))
+	assert.Equal(t, "not measured", result.Checks[0].Actual)
+}
+
+func TestSLOThresholdsWithTheErrorRateNotEvaluated(t *testing.T) {
+	result := SLOThresholds{MinResponseRatio: 0.9}.Evaluate(reportForSLO())
+
+	assert.True(t, result.Passed)
+	assert.Equal(t, 1, len(result.Checks))
+	assert.Equal(t, "response ratio", result.Checks[0].Name)
+}
+
+func TestSLOThresholdsWithOnlyTheThroughput(t *testing.T) {
+	result := SLOThresholds{MinThroughput: 40}.Evaluate(reportForSLO())
+
+	assert.True(t, result.Passed)
+	assert.Equal(t, 1, len(result.Checks))
+	assert.Equal(t, "throughput", result.Checks[0].Name)
+}
+
+func TestSLOThresholdsWithZeroErrorRate(t *testing.T) {
+	result := SLOThresholds{MaxErrorRate: errorRate(0)}.Evaluate(reportForSLO())
+
+	assert.False(t, result.Passed)
+	assert.Equal(t, SLOCheck{Name: "error rate", Threshold: "<= 0.00%", Actual: "3.50%", Passed: false}, result.Checks[0])
+}
+
+func TestPrintSLOResult(t *testing.T) {
+	result := SLOThresholds{MaxErrorRate: errorRate(0.01)}.Evaluate(reportForSLO())
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, result.Print(buffer))
+
+	output := buffer.String()
+	assert.Contains(t, output, "SLO: FAIL")
+	assert.Contains(t, output, "Check")
+	assert.Contains(t, output, "error rate")
+	assert.Contains(t, output, "3.50%")
+}
+
+func reporterWithAFailedRequest() *report.Reporter {
+	loadGenerationChannel := make(chan report.LoadGenerationResponse, 1)
+	reporter := report.NewLoadGenerationMetricsCollectingReporter(loadGenerationChannel)
+	reporter.Run()
+	loadGenerationChannel <- report.LoadGenerationResponse{Err: assert.AnError, PayloadLengthBytes: 10}
+	close(loadGenerationChannel)
+	return reporter
+}
+
+func TestWriteSLOResultExitsWhenAThresholdIsBreachedInTheCLI(t *testing.T) {
+	exitCode := 0
+	exitWithCode = func(code int) {
+		exitCode = code
+	}
+	buffer := &bytes.Buffer{}
+	OutputStream = buffer
+
+	instance := Blast{
+		reporter:        reporterWithAFailedRequest(),
+		reportOptions:   ReportOptions{SLOThresholds: &SLOThresholds{MaxErrorRate: errorRate(0.01)}},
+		exitOnSLOBreach: true,
+	}
+	instance.writeSLOResult()
+
+	assert.Equal(t, SLOBreachedExitCode, exitCode)
+	assert.Contains(t, buffer.String(), "SLO: FAIL")
+}
+
+func TestWriteSLOResultDoesNotExitWhenAThresholdIsBreachedInALibrary(t *testing.T) {
+	exitCode := 0
+	exitWithCode = func(code int) {
+		exitCode = code
+	}
+	OutputStream = &bytes.Buffer{}
+
+	instance := Blast{
+		reporter:      reporterWithAFailedRequest(),
+		reportOptions: ReportOptions{SLOThresholds: &SLOThresholds{MaxErrorRate: errorRate(0.01)}},
+	}
+	instance.writeSLOResult()
+
+	assert.Equal(t, 0, exitCode)
+	result := instance.EvaluateSLO()
+	assert.NotNil(t, result)
+	assert.False(t, result.Passed)
+}
+
+func TestWriteSLOResultDoesNotWriteToTheJSONReport(t *testing.T) {
+	exitWithCode = func(code int) {}
+	buffer := &bytes.Buffer{}
+	OutputStream = buffer
+
+	instance := Blast{
+		reporter:      reporterWithAFailedRequest(),
+		reportOptions: ReportOptions{Format: report.JsonFormat, SLOThresholds: &SLOThresholds{MaxErrorRate: errorRate(0.01)}},
+	}
+	instance.writeSLOResult()
+
+	assert.Equal(t, 0, buffer.Len())
+}
+
+func TestEvaluateSLOWithoutThresholds(t *testing.T) {
+	instance := Blast{reporter: reporterWithAFailedRequest()}
+	assert.Nil(t, instance.EvaluateSLO())
+}
+
+func TestErrorCountThresholdsFlag(t *testing.T) {
+	thresholds := errorCountThresholds{}
+
+	assert.Nil(t, thresholds.Set("connection reset=10"))
+	assert.Nil(t, thresholds.Set("invalid key=value=2"))
+	assert.Error(t, thresholds.Set("timeout"))
+	assert.Error(t, thresholds.Set("timeout=many"))
+
+	assert.Equal(t, errorCountThresholds{"connection reset": 10, "invalid key=value": 2}, thresholds)
+	assert.Equal(t, "connection reset=10,invalid key=value=2", thresholds.String())
+}
+
+func TestParseCommandLineArgumentsWithSLOLatencyWithoutReadingResponses(t *testing.T) {
+	exitFunction = exitWithPanic
+	*sloMaxP99Latency = 50 * time.Millisecond
+	defer func() {
+		*sloMaxP99Latency = 0
+	}()
+	assert.Panics(t, func() {
+		assertSLOThresholds(false, -1)
+	})
+}
+
+func TestParseCommandLineArgumentsWithSLOErrorRateGreaterThanOne(t *testing.T) {
+	exitFunction = exitWithPanic
+	*sloMaxErrorRate = 2
+	defer func() {
+		*sloMaxErrorRate = -1
+	}()
+	assert.Panics(t, func() {
+		assertSLOThresholds(false, -1)
+	})
+}
+
+func TestParseCommandLineArgumentsWithSLOThresholds(t *testing.T) {
+	exitFunction = exitWithPanic
+	*sloMaxP99Latency = 50 * time.Millisecond
+	*sloMinResponseRatio = 0.9
+	defer func() {
+		*sloMaxP99Latency = 0
+		*sloMinResponseRatio = 0
+	}()
+	assert.NotPanics(t, func() {
+		assertSLOThresholds(true, 8)
+	})
+	assert.Equal(t, 50*time.Millisecond, sloThresholds().MaxP99Latency)
+}
+
+func TestParseCommandLineArgumentsWithSLOZeroErrorRate(t *testing.T) {
+	*sloMaxErrorRate = 0
+	defer func() {
+		*sloMaxErrorRate = -1
+	}()
+	thresholds := sloThresholds()
+	assert.NotNil(t, thresholds)
+	assert.Equal(t, 0.0, *thresholds.MaxErrorRate)
+}
+
+func TestParseCommandLineArgumentsWithoutSLOErrorRate(t *testing.T) {
+	*sloMinThroughput = 100
+	defer func() {
+		*sloMinThroughput = 0
+	}()
+	assert.Nil(t, sloThresholds().MaxErrorRate)
+}
@@ -0,0 +1,239 @@
+package blast
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/SarthakMakhija/blast-core/report"
+	"github.com/SarthakMakhija/blast-core/workers"
+)
+
+// ErrLatencyThresholdWithoutLatency is the error that is returned when the ThroughputSearch is configured with
+// SearchThresholds.MaxP99Latency, but the latency can not be measured (no ResponseOptions.RequestIdExtractor).
+var ErrLatencyThresholdWithoutLatency = errors.New("p99 latency threshold requires reading responses with a RequestIdExtractor")
+
+// ErrResponseRatioThresholdWithoutResponses is the error that is returned when the ThroughputSearch is configured
+// with SearchThresholds.MinResponseRatio, but the responses are not read.
+var ErrResponseRatioThresholdWithoutResponses = errors.New("response ratio threshold requires reading responses")
+
+// SearchThresholds defines the thresholds (SLO) that the report.Report of each step of the ThroughputSearch
+// is evaluated against. A step is sustainable if none of the thresholds are breached.
+// MaxErrorRate is the maximum ratio of the failed (or dropped) requests and failed responses to the total
+// requests and responses.
+// MaxP99Latency is the maximum p99 latency corrected for the coordinated omission, it is not evaluated if zero.
+// MinResponseRatio is the minimum ratio of the successful responses to the requests sent, it is not evaluated
+// if zero.
+type SearchThresholds struct {
+	MaxErrorRate     float64
+	MaxP99Latency    time.Duration
+	MinResponseRatio float64
+}
+
+// SearchOptions defines the options for the ThroughputSearch.
+// The search starts at StartRate (requests per second across all the workers) and doubles the rate after each
+// sustainable step till a step breaches the SearchThresholds or MaxRate is reached. It then binary-searches
+// between the highest sustainable rate and the lowest unsustainable rate, till the difference between the two
+// is not greater than Precision (requests per second).
+// Each step runs for StepDuration.
+type SearchOptions struct {
+	StartRate    float64
+	MaxRate      float64
+	Precision    float64
+	StepDuration time.Duration
+	Thresholds   SearchThresholds
+}
+
+// SearchStep represents the result of a single step of the ThroughputSearch.
+// AchievedRate is the rate of the successful requests in the step.
+// Violations describes the thresholds that were breached, it is empty if the step is Sustainable.
+type SearchStep struct {
+	Rate          float64
+	AchievedRate  float64
+	ErrorRate     float64
+	P99Latency    time.Duration
+	ResponseRatio float64
+	Sustainable   bool
+	Violations    []string
+}
+
+// SearchResult represents the result of the ThroughputSearch.
+// MaxSustainableRate is the highest rate that did not breach the SearchThresholds, it is zero if none of the
+// steps were sustainable.
+type SearchResult struct {
+	MaxSustainableRate float64
+	Steps              []SearchStep
+}
+
+// ThroughputSearch searches the maximum throughput that the target server sustains.
+// ThroughputSearch runs short successive steps of Blast, each with the OpenLoadModel at a different arrival rate,
+// and evaluates the report.Report of each step against the SearchThresholds.
+type ThroughputSearch struct {
+	groupOptions    workers.GroupOptions
+	responseOptions *ResponseOptions
+	options         SearchOptions
+	runStep         func(rate float64) report.Report
+}
+
+// NewThroughputSearch creates a new instance of ThroughputSearch that does not read responses from the target server.
+func NewThroughputSearch(groupOptions workers.GroupOptions, options SearchOptions) (*ThroughputSearch, error) {
+	return newThroughputSearch(groupOptions, nil, options)
+}
+
+// NewThroughputSearchWithResponseReading creates a new instance of ThroughputSearch that reads responses from
+// the target server.
+// Each step reads the responses till the end of the step, irrespective of ResponseOptions.ReadingOption.
+func NewThroughputSearchWithResponseReading(
+	groupOptions workers.GroupOptions,
+	responseOptions ResponseOptions,
+	options SearchOptions,
+) (*ThroughputSearch, error) {
+	return newThroughputSearch(groupOptions, &responseOptions, options)
+}
+
+// newThroughputSearch creates a new instance of ThroughputSearch after validating the options.
+func newThroughputSearch(
+	groupOptions workers.GroupOptions,
+	responseOptions *ResponseOptions,
+	options SearchOptions,
+) (*ThroughputSearch, error) {
+	if options.StartRate <= 0 || options.MaxRate < options.StartRate {
+		return nil, fmt.Errorf("start rate must be greater than zero and not greater than the max rate")
+	}
+	if options.Precision <= 0 {
+		return nil, fmt.Errorf("precision must be greater than zero")
+	}
+	if options.StepDuration <= 0 {
+		return nil, fmt.Errorf("step duration must be greater than zero")
+	}
+	if options.Thresholds.MaxP99Latency > 0 && (responseOptions == nil || responseOptions.RequestIdExtractor == nil) {
+		return nil, ErrLatencyThresholdWithoutLatency
+	}
+	if options.Thresholds.MinResponseRatio > 0 && responseOptions == nil {
+		return nil, ErrResponseRatioThresholdWithoutResponses
+	}
+	search := &ThroughputSearch{
+		groupOptions:    groupOptions,
+		responseOptions: responseOptions,
+		options:         options,
+	}
+	search.runStep = search.runBlast
+	return search, nil
+}
+
+// Run runs the search and returns the SearchResult.
+func (search *ThroughputSearch) Run() SearchResult {
+	result := SearchResult{}
+	runStep := func(rate float64) bool {
+		step := search.evaluate(rate, search.runStep(rate))
+		result.Steps = append(result.Steps, step)
+		return step.Sustainable
+	}
+
+	sustainableRate, unsustainableRate := 0.0, 0.0
+	for rate := search.options.StartRate; ; rate = math.Min(rate*2, search.options.MaxRate) {
+		if !runStep(rate) {
+			unsustainableRate = rate
+			break
+		}
+		sustainableRate = rate
+		if rate >= search.options.MaxRate {
+			result.MaxSustainableRate = sustainableRate
+			return result
+		}
+	}
+	for unsustainableRate-sustainableRate > search.options.Precision {
+		rate := (sustainableRate + unsustainableRate) / 2
+		if runStep(rate) {
+			sustainableRate = rate
+		} else {
+			unsustainableRate = rate
+		}
+	}
+	result.MaxSustainableRate = sustainableRate
+	return result
+}
+
+// evaluate evaluates the report.Report of the step against the SearchThresholds.
+func (search *ThroughputSearch) evaluate(rate float64, stepReport report.Report) SearchStep {
+	step := SearchStep{
+		Rate:          rate,
+		AchievedRate:  float64(stepReport.Load.SuccessCount) / search.options.StepDuration.Seconds(),
+		P99Latency:    stepReport.Response.CorrectedLatency.P99,
+		ErrorRate:     errorRateOf(stepReport),
+		ResponseRatio: responseRatioOf(stepReport),
+	}
+
+	thresholds := search.options.Thresholds
+	if stepReport.Load.SuccessCount == 0 {
+		step.Violations = append(step.Violations, "no successful requests")
+	}
+	if step.ErrorRate > thresholds.MaxErrorRate {
+		step.Violations = append(step.Violations, fmt.Sprintf("error rate %.2f%% > %.2f%%", step.ErrorRate*100, thresholds.MaxErrorRate*100))
+	}
+	if thresholds.MaxP99Latency > 0 {
+		if stepReport.Response.CorrectedLatency.TotalSamples == 0 {
+			step.Violations = append(step.Violations, "p99 latency not measured")
+		} else if step.P99Latency > thresholds.MaxP99Latency {
+			step.Violations = append(step.Violations, fmt.Sprintf("p99 %v > %v", step.P99Latency, thresholds.MaxP99Latency))
+		}
+	}
+	if thresholds.MinResponseRatio > 0 && step.ResponseRatio < thresholds.MinResponseRatio {
+		step.Violations = append(step.Violations, fmt.Sprintf("response ratio %.2f < %.2f", step.ResponseRatio, thresholds.MinResponseRatio))
+	}
+	step.Sustainable = len(step.Violations) == 0
+	return step
+}
+
+// runBlast runs a single step of Blast at the given rate and returns its report.Report.
+// The connections of the step are closed before runBlast returns, so that the next step does not start while the
+// connections of the previous steps are still open on the target server.
+func (search *ThroughputSearch) runBlast(rate float64) report.Report {
+	groupOptions := search.groupOptions.WithArrivalRate(rate).WithMaxDuration(search.options.StepDuration)
+
+	var instance Blast
+	if search.responseOptions != nil {
+		responseOptions := *search.responseOptions
+		responseOptions.ReadingOption = ReadTotalResponses
+		responseOptions.TotalResponsesToRead = math.MaxUint
+		instance = NewBlastWithResponseReading(groupOptions, responseOptions, false)
+	} else {
+		instance = NewBlastWithoutResponseReading(groupOptions, false)
+	}
+	instance.waitTillDone()
+	return instance.Report()
+}
+
+// Print prints the SearchResult on the provided io.Writer, with a table of the results of each step.
+func (result SearchResult) Print(writer io.Writer) error {
+	builder := &strings.Builder{}
+	_, _ = fmt.Fprintf(builder, "\nThroughputSearch:\n  MaxSustainableRate: %.2f\n\n  Steps:\n", result.MaxSustainableRate)
+
+	tableWriter := tabwriter.NewWriter(builder, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tableWriter, "    Rate\tAchievedRate\tErrorRate\tp99\tResponseRatio\tSustainable\tViolations")
+	for _, step := range result.Steps {
+		violations := "none"
+		if len(step.Violations) > 0 {
+			violations = strings.Join(step.Violations, "; ")
+		}
+		_, _ = fmt.Fprintf(
+			tableWriter,
+			"    %.2f\t%.2f\t%.2f%%\t%v\t%.2f\t%v\t%v\n",
+			step.Rate,
+			step.AchievedRate,
+			step.ErrorRate*100,
+			step.P99Latency,
+			step.ResponseRatio,
+			step.Sustainable,
+			violations,
+		)
+	}
+	_ = tableWriter.Flush()
+
+	_, err := io.WriteString(writer, builder.String())
+	return err
+}
@@ -0,0 +1,166 @@
+package blast
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/SarthakMakhija/blast-core/payload"
+	"github.com/SarthakMakhija/blast-core/report"
+	"github.com/SarthakMakhija/blast-core/workers"
+)
+
+func newGroupOptionsForSearch() workers.GroupOptions {
+	return workers.NewGroupOptions(10, payload.NewConstantPayloadGenerator([]byte("HelloWorld")), "localhost:8080", time.Second)
+}
+
+// serverSustaining returns a runStep that reports errors for all the rates above the sustainableRate.
+func serverSustaining(sustainableRate float64) func(rate float64) report.Report {
+	return func(rate float64) report.Report {
+		stepReport := report.Report{}
+		stepReport.Load.TotalRequests = uint(rate)
+		stepReport.Load.SuccessCount = uint(rate)
+		if rate > sustainableRate {
+			stepReport.Load.SuccessCount = uint(sustainableRate)
+			stepReport.Load.ErrorCount = uint(rate - sustainableRate)
+		}
+		return stepReport
+	}
+}
+
+func TestThroughputSearchConvergesOnTheMaxSustainableRate(t *testing.T) {
+	search, err := NewThroughputSearch(newGroupOptionsForSearch(), SearchOptions{
+		StartRate:    100,
+		MaxRate:      10000,
+		Precision:    10,
+		StepDuration: time.Second,
+	})
+	assert.Nil(t, err)
+	search.runStep = serverSustaining(700)
+
+	result := search.Run()
+	assert.True(t, result.MaxSustainableRate <= 700)
+	assert.True(t, result.MaxSustainableRate >= 690)
+
+	assert.Equal(t, 100.0, result.Steps[0].Rate)
+	assert.True(t, result.Steps[0].Sustainable)
+	assert.Equal(t, 800.0, result.Steps[3].Rate)
+	assert.False(t, result.Steps[3].Sustainable)
+	assert.Equal(t, []string{"error rate 12.50% > 0.00%"}, result.Steps[3].Violations)
+}
+
+func TestThroughputSearchWithAllTheStepsSustainable(t *testing.T) {
+	search, err := NewThroughputSearch(newGroupOptionsForSearch(), SearchOptions{
+		StartRate:    100,
+		MaxRate:      300,
+		Precision:    10,
+		StepDuration: time.Second,
+	})
+	assert.Nil(t, err)
+	search.runStep = serverSustaining(1000)
+
+	result := search.Run()
+	assert.Equal(t, 300.0, result.MaxSustainableRate)
+	assert.Equal(t, []float64{100, 200, 300}, []float64{result.Steps[0].Rate, result.Steps[1].Rate, result.Steps[2].Rate})
+}
+
+func TestThroughputSearchWithNoSustainableStep(t *testing.T) {
+	search, err := NewThroughputSearch(newGroupOptionsForSearch(), SearchOptions{
+		StartRate:    100,
+		MaxRate:      300,
+		Precision:    50,
+		StepDuration: time.Second,
+	})
+	assert.Nil(t, err)
+	search.runStep = serverSustaining(0)
+
+	result := search.Run()
+	assert.Equal(t, 0.0, result.MaxSustainableRate)
+	for _, step := range result.Steps {
+		assert.False(t, step.Sustainable)
+	}
+}
+
+func TestThroughputSearchEvaluatesTheLatencyAndTheResponseRatio(t *testing.T) {
+	search, err := NewThroughputSearchWithResponseReading(
+		newGroupOptionsForSearch(),
+		ResponseOptions{RequestIdExtractor: report.NewOffsetRequestIdExtractor(0, binary.BigEndian)},
+		SearchOptions{
+			StartRate:    100,
+			MaxRate:      100,
+			Precision:    10,
+			StepDuration: time.Second,
+			Thresholds: SearchThresholds{
+				MaxErrorRate:     0.1,
+				MaxP99Latency:    10 * time.Millisecond,
+				MinResponseRatio: 0.9,
+			},
+		},
+	)
+	assert.Nil(t, err)
+
+	stepReport := report.Report{}
+	stepReport.Load.TotalRequests = 100
+	stepReport.Load.SuccessCount = 100
+	stepReport.Response.TotalResponses = 50
+	stepReport.Response.SuccessCount = 50
+	stepReport.Response.CorrectedLatency = report.LatencyMetrics{TotalSamples: 50, P99: 20 * time.Millisecond}
+
+	step := search.evaluate(100, stepReport)
+	assert.False(t, step.Sustainable)
+	assert.Equal(t, 100.0, step.AchievedRate)
+	assert.Equal(t, 0.5, step.ResponseRatio)
+	assert.Equal(t, []string{"p99 20ms > 10ms", "response ratio 0.50 < 0.90"}, step.Violations)
+}
+
+func TestThroughputSearchWithInvalidOptions(t *testing.T) {
+	_, err := NewThroughputSearch(newGroupOptionsForSearch(), SearchOptions{StartRate: 0, MaxRate: 100, Precision: 1, StepDuration: time.Second})
+	assert.Error(t, err)
+
+	_, err = NewThroughputSearch(newGroupOptionsForSearch(), SearchOptions{StartRate: 200, MaxRate: 100, Precision: 1, StepDuration: time.Second})
+	assert.Error(t, err)
+
+	_, err = NewThroughputSearch(newGroupOptionsForSearch(), SearchOptions{StartRate: 100, MaxRate: 100, Precision: 0, StepDuration: time.Second})
+	assert.Error(t, err)
+
+	_, err = NewThroughputSearch(newGroupOptionsForSearch(), SearchOptions{StartRate: 100, MaxRate: 100, Precision: 1})
+	assert.Error(t, err)
+
+	_, err = NewThroughputSearch(newGroupOptionsForSearch(), SearchOptions{
+		StartRate: 100, MaxRate: 100, Precision: 1, StepDuration: time.Second,
+		Thresholds: SearchThresholds{MaxP99Latency: time.Millisecond},
+	})
+	assert.Equal(t, ErrLatencyThresholdWithoutLatency, err)
+
+	_, err = NewThroughputSearch(newGroupOptionsForSearch(), SearchOptions{
+		StartRate: 100, MaxRate: 100, Precision: 1, StepDuration: time.Second,
+		Thresholds: SearchThresholds{MinResponseRatio: 0.5},
+	})
+	assert.Equal(t, ErrResponseRatioThresholdWithoutResponses, err)
+}
+
+func TestPrintsTheSearchResult(t *testing.T) {
+	result := SearchResult{
+		MaxSustainableRate: 100,
+		Steps: []SearchStep{
+			{Rate: 100, AchievedRate: 100, Sustainable: true},
+			{Rate: 200, AchievedRate: 150, ErrorRate: 0.25, Violations: []string{"error rate 25.00% > 0.00%"}},
+		},
+	}
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, result.Print(buffer))
+
+	expected := `
+ThroughputSearch:
+  MaxSustainableRate: 100.00
+
+  Steps:
+    Rate    AchievedRate  ErrorRate  p99  ResponseRatio  Sustainable  Violations
+    100.00  100.00        0.00%      0s   0.00           true         none
+    200.00  150.00        25.00%     0s   0.00           false        error rate 25.00% > 0.00%
+`
+	assert.Equal(t, expected, buffer.String())
+}
@@ -0,0 +1,87 @@
+package payload
+
+import "fmt"
+
+// SelectionStrategy defines how the CorpusPayloadGenerator selects the payload of each request from the
+// PayloadCorpus.
+// SequentialSelection: the payloads are selected one after the other (round-robin) in the order of the request ids.
+// RandomSelection: each payload is selected uniformly at random.
+// WeightedSelection: each payload is selected at random, in proportion to its weight.
+// The random selections are derived from the seed and the request id, so a run with the same seed selects the
+// same payload for each request id.
+type SelectionStrategy uint8
+
+const (
+	SequentialSelection SelectionStrategy = iota
+	RandomSelection
+	WeightedSelection
+)
+
+// CorpusPayloadGenerator generates the payload of each request by selecting one of the payloads of the
+// PayloadCorpus as per the SelectionStrategy.
+// CorpusPayloadGenerator does not hold any mutable state, so it is safe to be used by all the workers.
+type CorpusPayloadGenerator struct {
+	corpus            PayloadCorpus
+	strategy          SelectionStrategy
+	seed              uint64
+	cumulativeWeights []uint64
+}
+
+// ParseSelectionStrategy returns the SelectionStrategy identified by the name: sequential, random or weighted.
+func ParseSelectionStrategy(name string) (SelectionStrategy, error) {
+	switch name {
+	case "sequential":
+		return SequentialSelection, nil
+	case "random":
+		return RandomSelection, nil
+	case "weighted":
+		return WeightedSelection, nil
+	default:
+		return SequentialSelection, fmt.Errorf("unsupported payload selection %v", name)
+	}
+}
+
+// NewCorpusPayloadGenerator creates a new instance of CorpusPayloadGenerator.
+func NewCorpusPayloadGenerator(corpus PayloadCorpus, strategy SelectionStrategy, seed int64) CorpusPayloadGenerator {
+	return CorpusPayloadGenerator{
+		corpus:            corpus,
+		strategy:          strategy,
+		seed:              uint64(seed),
+		cumulativeWeights: corpus.cumulativeWeights(),
+	}
+}
+
+// Generate returns the payload selected for the request id.
+func (generator CorpusPayloadGenerator) Generate(requestId uint64) []byte {
+	payloads := generator.corpus.payloads
+	switch generator.strategy {
+	case RandomSelection:
+		return payloads[generator.random(requestId)%uint64(len(payloads))]
+	case WeightedSelection:
+		totalWeight := generator.cumulativeWeights[len(generator.cumulativeWeights)-1]
+		return payloads[indexOfWeight(generator.cumulativeWeights, generator.random(requestId)%totalWeight)]
+	default:
+		return payloads[(requestId-1)%uint64(len(payloads))]
+	}
+}
+
+// random returns the pseudo-random number of the request id (splitmix64 of the seed and the request id).
+func (generator CorpusPayloadGenerator) random(requestId uint64) uint64 {
+	state := randomState(generator.seed, requestId)
+	return splitMix64(&state)
+}
+
+// randomState returns the initial state of the pseudo-random numbers of the request id.
+func randomState(seed uint64, requestId uint64) uint64 {
+	return seed + requestId*0x9e3779b97f4a7c15
+}
+
+// splitMix64 advances the state and returns the next pseudo-random number (splitmix64).
+// It does not hold any shared state, so that the workers generate the payloads without any contention.
+func splitMix64(state *uint64) uint64 {
+	*state += 0x9e3779b97f4a7c15
+	value := *state
+	value = (value ^ (value >> 30)) * 0xbf58476d1ce4e5b9
+	value = (value ^ (value >> 27)) * 0x94d049bb133111eb
+	return value ^ (value >> 31)
+}
@@ -0,0 +1,83 @@
+package payload
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsesTheSelectionStrategies(t *testing.T) {
+	strategies := map[string]SelectionStrategy{
+		"sequential": SequentialSelection,
+		"random":     RandomSelection,
+		"weighted":   WeightedSelection,
+	}
+	for name, expected := range strategies {
+		strategy, err := ParseSelectionStrategy(name)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, strategy)
+	}
+}
+
+func TestParsesAnUnsupportedSelectionStrategy(t *testing.T) {
+	_, err := ParseSelectionStrategy("shuffle")
+	assert.Error(t, err)
+}
+
+func TestGeneratesThePayloadsSequentially(t *testing.T) {
+	corpus, err := NewPayloadCorpus([][]byte{[]byte("a"), []byte("b"), []byte("c")})
+	assert.Nil(t, err)
+
+	generator := NewCorpusPayloadGenerator(corpus, SequentialSelection, 0)
+	var payloads string
+	for requestId := uint64(1); requestId <= 7; requestId++ {
+		payloads += string(generator.Generate(requestId))
+	}
+	assert.Equal(t, "abcabca", payloads)
+}
+
+func TestGeneratesThePayloadsRandomlyWithTheSameSeed(t *testing.T) {
+	corpus, err := NewPayloadCorpus([][]byte{[]byte("a"), []byte("b"), []byte("c")})
+	assert.Nil(t, err)
+
+	generator := NewCorpusPayloadGenerator(corpus, RandomSelection, 42)
+	otherGenerator := NewCorpusPayloadGenerator(corpus, RandomSelection, 42)
+
+	countByPayload := make(map[string]int)
+	for requestId := uint64(1); requestId <= 3000; requestId++ {
+		payload := generator.Generate(requestId)
+		assert.Equal(t, payload, otherGenerator.Generate(requestId))
+		countByPayload[string(payload)]++
+	}
+	for _, payload := range []string{"a", "b", "c"} {
+		assert.InDelta(t, 1000, countByPayload[payload], 150)
+	}
+}
+
+func TestGeneratesThePayloadsRandomlyWithDifferentSeeds(t *testing.T) {
+	corpus, err := NewPayloadCorpus([][]byte{[]byte("a"), []byte("b"), []byte("c")})
+	assert.Nil(t, err)
+
+	generator := NewCorpusPayloadGenerator(corpus, RandomSelection, 1)
+	otherGenerator := NewCorpusPayloadGenerator(corpus, RandomSelection, 2)
+
+	var payloads, otherPayloads string
+	for requestId := uint64(1); requestId <= 20; requestId++ {
+		payloads += string(generator.Generate(requestId))
+		otherPayloads += string(otherGenerator.Generate(requestId))
+	}
+	assert.NotEqual(t, payloads, otherPayloads)
+}
+
+func TestGeneratesThePayloadsByWeight(t *testing.T) {
+	corpus, err := newWeightedPayloadCorpus([][]byte{[]byte("get"), []byte("set")}, []uint64{9, 1})
+	assert.Nil(t, err)
+
+	generator := NewCorpusPayloadGenerator(corpus, WeightedSelection, 7)
+	countByPayload := make(map[string]int)
+	for requestId := uint64(1); requestId <= 10000; requestId++ {
+		countByPayload[string(generator.Generate(requestId))]++
+	}
+	assert.InDelta(t, 9000, countByPayload["get"], 300)
+	assert.InDelta(t, 1000, countByPayload["set"], 300)
+}
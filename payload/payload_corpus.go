@@ -0,0 +1,228 @@
+package payload
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ErrEmptyPayloadCorpus is the error that is returned when a PayloadCorpus does not contain any payload.
+var ErrEmptyPayloadCorpus = errors.New("payload corpus does not contain any payload")
+
+// ErrTruncatedPayloadBundle is the error that is returned when the last payload of a bundle (BundleCorpusFormat)
+// is shorter than its length prefix.
+var ErrTruncatedPayloadBundle = errors.New("payload bundle is truncated")
+
+// CorpusFormat defines how the payloads of a PayloadCorpus are stored.
+// FileCorpusFormat: a single file, its content is the only payload.
+// DirectoryCorpusFormat: a directory of files, the content of each file is a payload. The files are ordered by
+// their names and the sub-directories are ignored.
+// LinesCorpusFormat: a newline-delimited file, each line (including its line feed) is a payload. The blank lines
+// are ignored.
+// BundleCorpusFormat: a binary file of the payloads, each prefixed with its length in 4 bytes (big-endian).
+// ManifestCorpusFormat: a text file, each line of the form "weight path" where the content of the file at path
+// (relative to the manifest) is a payload with the given weight. The blank lines and the lines starting with #
+// are ignored.
+type CorpusFormat uint8
+
+const (
+	FileCorpusFormat CorpusFormat = iota
+	DirectoryCorpusFormat
+	LinesCorpusFormat
+	BundleCorpusFormat
+	ManifestCorpusFormat
+)
+
+// PayloadCorpus represents a set of payloads, along with the weight of each payload.
+// The weight of each payload is 1, unless the PayloadCorpus is loaded from a manifest (ManifestCorpusFormat).
+type PayloadCorpus struct {
+	payloads [][]byte
+	weights  []uint64
+}
+
+// ParseCorpusFormat returns the CorpusFormat identified by the name: file, dir, lines, bundle or manifest.
+func ParseCorpusFormat(name string) (CorpusFormat, error) {
+	switch name {
+	case "file":
+		return FileCorpusFormat, nil
+	case "dir":
+		return DirectoryCorpusFormat, nil
+	case "lines":
+		return LinesCorpusFormat, nil
+	case "bundle":
+		return BundleCorpusFormat, nil
+	case "manifest":
+		return ManifestCorpusFormat, nil
+	default:
+		return FileCorpusFormat, fmt.Errorf("unsupported payload format %v", name)
+	}
+}
+
+// NewPayloadCorpus creates a new instance of PayloadCorpus with the payloads, each of weight 1.
+func NewPayloadCorpus(payloads [][]byte) (PayloadCorpus, error) {
+	weights := make([]uint64, len(payloads))
+	for index := range weights {
+		weights[index] = 1
+	}
+	return newWeightedPayloadCorpus(payloads, weights)
+}
+
+// LoadPayloadCorpus loads the PayloadCorpus stored at the path in the CorpusFormat.
+func LoadPayloadCorpus(path string, format CorpusFormat) (PayloadCorpus, error) {
+	switch format {
+	case DirectoryCorpusFormat:
+		return loadDirectoryCorpus(path)
+	case LinesCorpusFormat:
+		return loadLinesCorpus(path)
+	case BundleCorpusFormat:
+		return loadBundleCorpus(path)
+	case ManifestCorpusFormat:
+		return loadManifestCorpus(path)
+	default:
+		provider, err := NewFilePayloadProvider(path)
+		if err != nil {
+			return PayloadCorpus{}, err
+		}
+		return NewPayloadCorpus([][]byte{provider.Get()})
+	}
+}
+
+// Size returns the number of payloads in the PayloadCorpus.
+func (corpus PayloadCorpus) Size() int {
+	return len(corpus.payloads)
+}
+
+// newWeightedPayloadCorpus creates a new instance of PayloadCorpus with the payloads and their weights.
+func newWeightedPayloadCorpus(payloads [][]byte, weights []uint64) (PayloadCorpus, error) {
+	if len(payloads) == 0 {
+		return PayloadCorpus{}, ErrEmptyPayloadCorpus
+	}
+	return PayloadCorpus{payloads: payloads, weights: weights}, nil
+}
+
+// loadDirectoryCorpus loads the content of each file in the directory as a payload, ordered by the file names.
+func loadDirectoryCorpus(path string) (PayloadCorpus, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return PayloadCorpus{}, err
+	}
+	var payloads [][]byte
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(path, entry.Name()))
+		if err != nil {
+			return PayloadCorpus{}, err
+		}
+		payloads = append(payloads, content)
+	}
+	return NewPayloadCorpus(payloads)
+}
+
+// loadLinesCorpus loads each line (including its line feed) of the file as a payload, ignoring the blank lines.
+func loadLinesCorpus(path string) (PayloadCorpus, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return PayloadCorpus{}, err
+	}
+	var payloads [][]byte
+	for len(content) > 0 {
+		line := content
+		if index := bytes.IndexByte(content, '\n'); index >= 0 {
+			line = content[:index+1]
+		}
+		content = content[len(line):]
+		if len(bytes.TrimSpace(line)) > 0 {
+			payloads = append(payloads, line)
+		}
+	}
+	return NewPayloadCorpus(payloads)
+}
+
+// loadBundleCorpus loads each length-prefixed payload of the bundle.
+func loadBundleCorpus(path string) (PayloadCorpus, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return PayloadCorpus{}, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	reader := bufio.NewReader(file)
+	var payloads [][]byte
+	for {
+		var length uint32
+		if err := binary.Read(reader, binary.BigEndian, &length); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return PayloadCorpus{}, ErrTruncatedPayloadBundle
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(reader, payload); err != nil {
+			return PayloadCorpus{}, ErrTruncatedPayloadBundle
+		}
+		payloads = append(payloads, payload)
+	}
+	return NewPayloadCorpus(payloads)
+}
+
+// loadManifestCorpus loads the content of each file listed in the manifest as a payload, with its weight.
+func loadManifestCorpus(path string) (PayloadCorpus, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return PayloadCorpus{}, err
+	}
+	var payloads [][]byte
+	var weights []uint64
+	for number, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		weight, filePath, found := strings.Cut(line, " ")
+		parsedWeight, err := strconv.ParseUint(weight, 10, 32)
+		if !found || err != nil || parsedWeight == 0 {
+			return PayloadCorpus{}, fmt.Errorf("manifest line %d must be of the form \"weight path\" with a weight greater than zero", number+1)
+		}
+		filePath = strings.TrimSpace(filePath)
+		if !filepath.IsAbs(filePath) {
+			filePath = filepath.Join(filepath.Dir(path), filePath)
+		}
+		payload, err := os.ReadFile(filePath)
+		if err != nil {
+			return PayloadCorpus{}, err
+		}
+		payloads = append(payloads, payload)
+		weights = append(weights, parsedWeight)
+	}
+	return newWeightedPayloadCorpus(payloads, weights)
+}
+
+// cumulativeWeights returns the running total of the weights of the payloads.
+func (corpus PayloadCorpus) cumulativeWeights() []uint64 {
+	cumulative := make([]uint64, len(corpus.weights))
+	var total uint64
+	for index, weight := range corpus.weights {
+		total += weight
+		cumulative[index] = total
+	}
+	return cumulative
+}
+
+// indexOfWeight returns the index of the payload that owns the value (0 <= value < total weight).
+func indexOfWeight(cumulativeWeights []uint64, value uint64) int {
+	return sort.Search(len(cumulativeWeights), func(index int) bool {
+		return cumulativeWeights[index] > value
+	})
+}
@@ -0,0 +1,134 @@
+package payload
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsesTheCorpusFormats(t *testing.T) {
+	formats := map[string]CorpusFormat{
+		"file":     FileCorpusFormat,
+		"dir":      DirectoryCorpusFormat,
+		"lines":    LinesCorpusFormat,
+		"bundle":   BundleCorpusFormat,
+		"manifest": ManifestCorpusFormat,
+	}
+	for name, expected := range formats {
+		format, err := ParseCorpusFormat(name)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, format)
+	}
+}
+
+func TestParsesAnUnsupportedCorpusFormat(t *testing.T) {
+	_, err := ParseCorpusFormat("json")
+	assert.Error(t, err)
+}
+
+func TestCreatesAnEmptyPayloadCorpus(t *testing.T) {
+	_, err := NewPayloadCorpus(nil)
+	assert.ErrorIs(t, err, ErrEmptyPayloadCorpus)
+}
+
+func TestLoadsTheFileCorpus(t *testing.T) {
+	directory := t.TempDir()
+	filePath := filepath.Join(directory, "payload")
+	assert.Nil(t, os.WriteFile(filePath, []byte("first\nsecond\n"), 0644))
+
+	corpus, err := LoadPayloadCorpus(filePath, FileCorpusFormat)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("first\nsecond\n")}, corpus.payloads)
+}
+
+func TestLoadsTheDirectoryCorpusOrderedByFileNames(t *testing.T) {
+	directory := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(directory, "b"), []byte("second"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(directory, "a"), []byte("first"), 0644))
+	assert.Nil(t, os.Mkdir(filepath.Join(directory, "nested"), 0755))
+
+	corpus, err := LoadPayloadCorpus(directory, DirectoryCorpusFormat)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("first"), []byte("second")}, corpus.payloads)
+	assert.Equal(t, []uint64{1, 1}, corpus.weights)
+}
+
+func TestLoadsAnEmptyDirectoryCorpus(t *testing.T) {
+	_, err := LoadPayloadCorpus(t.TempDir(), DirectoryCorpusFormat)
+	assert.ErrorIs(t, err, ErrEmptyPayloadCorpus)
+}
+
+func TestLoadsTheLinesCorpusIgnoringTheBlankLines(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "payloads")
+	assert.Nil(t, os.WriteFile(filePath, []byte("GET a\r\n\nGET b\n  \nGET c"), 0644))
+
+	corpus, err := LoadPayloadCorpus(filePath, LinesCorpusFormat)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("GET a\r\n"), []byte("GET b\n"), []byte("GET c")}, corpus.payloads)
+}
+
+func TestLoadsTheBundleCorpus(t *testing.T) {
+	var bundle []byte
+	for _, payload := range []string{"first", "second"} {
+		bundle = binary.BigEndian.AppendUint32(bundle, uint32(len(payload)))
+		bundle = append(bundle, payload...)
+	}
+	filePath := filepath.Join(t.TempDir(), "bundle")
+	assert.Nil(t, os.WriteFile(filePath, bundle, 0644))
+
+	corpus, err := LoadPayloadCorpus(filePath, BundleCorpusFormat)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("first"), []byte("second")}, corpus.payloads)
+}
+
+func TestLoadsATruncatedBundleCorpus(t *testing.T) {
+	bundle := binary.BigEndian.AppendUint32(nil, 10)
+	bundle = append(bundle, "short"...)
+	filePath := filepath.Join(t.TempDir(), "bundle")
+	assert.Nil(t, os.WriteFile(filePath, bundle, 0644))
+
+	_, err := LoadPayloadCorpus(filePath, BundleCorpusFormat)
+	assert.ErrorIs(t, err, ErrTruncatedPayloadBundle)
+}
+
+func TestLoadsABundleCorpusWithATruncatedLengthPrefix(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "bundle")
+	assert.Nil(t, os.WriteFile(filePath, []byte{0, 0}, 0644))
+
+	_, err := LoadPayloadCorpus(filePath, BundleCorpusFormat)
+	assert.ErrorIs(t, err, ErrTruncatedPayloadBundle)
+}
+
+func TestLoadsTheManifestCorpus(t *testing.T) {
+	directory := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(directory, "get"), []byte("GET"), 0644))
+	assert.Nil(t, os.WriteFile(filepath.Join(directory, "set"), []byte("SET"), 0644))
+	manifestPath := filepath.Join(directory, "manifest")
+	assert.Nil(t, os.WriteFile(manifestPath, []byte("# reads dominate\n9 get\n\n1 set\n"), 0644))
+
+	corpus, err := LoadPayloadCorpus(manifestPath, ManifestCorpusFormat)
+	assert.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("GET"), []byte("SET")}, corpus.payloads)
+	assert.Equal(t, []uint64{9, 1}, corpus.weights)
+}
+
+func TestLoadsTheManifestCorpusWithAZeroWeight(t *testing.T) {
+	directory := t.TempDir()
+	assert.Nil(t, os.WriteFile(filepath.Join(directory, "get"), []byte("GET"), 0644))
+	manifestPath := filepath.Join(directory, "manifest")
+	assert.Nil(t, os.WriteFile(manifestPath, []byte("0 get\n"), 0644))
+
+	_, err := LoadPayloadCorpus(manifestPath, ManifestCorpusFormat)
+	assert.Error(t, err)
+}
+
+func TestLoadsTheManifestCorpusWithANonExistingFile(t *testing.T) {
+	manifestPath := filepath.Join(t.TempDir(), "manifest")
+	assert.Nil(t, os.WriteFile(manifestPath, []byte("1 non-existing\n"), 0644))
+
+	_, err := LoadPayloadCorpus(manifestPath, ManifestCorpusFormat)
+	assert.Error(t, err)
+}
@@ -1,5 +1,7 @@
 package payload
 
+import "time"
+
 // PayloadGenerator defines a function for generating the request payload
 type PayloadGenerator interface {
 	Generate(requestId uint64) []byte
@@ -21,3 +23,48 @@ func NewConstantPayloadGenerator(payload []byte) ConstantPayloadGenerator {
 func (generator ConstantPayloadGenerator) Generate(id uint64) []byte {
 	return generator.payload
 }
+
+// RequestContext defines the context of a request, for generating its payload.
+// RequestId is the unique id of the request across all the workers (the id passed to PayloadGenerator).
+// ConnectionId identifies the connection of the worker sending the request, it stays the same when the
+// connection is re-established or churned. With the consistent hash load balancing, where each request is routed
+// to a connection by the key of its payload, ConnectionId identifies the first connection of the worker.
+// WorkerId identifies the worker sending the request, from 0 to concurrency-1.
+// ConnectionSequence is the sequence number (starting at 1) of the request on its ConnectionId, across all the
+// workers that share the connection.
+// Elapsed is the time elapsed since the workers started.
+// Session is the state of the connection returned by its initializer (see workers.ConnectionInitializer), for
+// example: a session token from the ack of the handshake. It is nil if the connection is not initialized.
+type RequestContext struct {
+	RequestId          uint64
+	ConnectionId       int
+	WorkerId           uint
+	ConnectionSequence uint64
+	Elapsed            time.Duration
+	Session            any
+}
+
+// ContextualPayloadGenerator defines a function for generating the request payload from the RequestContext, for
+// protocols that need more than the request id, for example: a per-connection sequence number, or the session
+// token of the connection.
+// ContextualPayloadGenerator is invoked concurrently by all the workers.
+type ContextualPayloadGenerator interface {
+	GenerateWithContext(requestContext RequestContext) []byte
+}
+
+// PayloadGeneratorAdapter adapts a PayloadGenerator to a ContextualPayloadGenerator.
+type PayloadGeneratorAdapter struct {
+	generator PayloadGenerator
+}
+
+// NewPayloadGeneratorAdapter creates a new instance of PayloadGeneratorAdapter.
+func NewPayloadGeneratorAdapter(generator PayloadGenerator) PayloadGeneratorAdapter {
+	return PayloadGeneratorAdapter{
+		generator: generator,
+	}
+}
+
+// GenerateWithContext generates the payload using the PayloadGenerator with the request id of the RequestContext.
+func (adapter PayloadGeneratorAdapter) GenerateWithContext(requestContext RequestContext) []byte {
+	return adapter.generator.Generate(requestContext.RequestId)
+}
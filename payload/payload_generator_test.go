@@ -0,0 +1,28 @@
+package payload
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type requestIdPayloadGenerator struct{}
+
+func (generator requestIdPayloadGenerator) Generate(requestId uint64) []byte {
+	payload := make([]byte, 8)
+	binary.BigEndian.PutUint64(payload, requestId)
+	return payload
+}
+
+func TestGeneratesTheConstantPayload(t *testing.T) {
+	generator := NewConstantPayloadGenerator([]byte("payload"))
+	assert.Equal(t, "payload", string(generator.Generate(1)))
+}
+
+func TestAdaptsThePayloadGeneratorWithTheRequestId(t *testing.T) {
+	adapter := NewPayloadGeneratorAdapter(requestIdPayloadGenerator{})
+	payload := adapter.GenerateWithContext(RequestContext{RequestId: 10, ConnectionId: 2, ConnectionSequence: 5})
+
+	assert.Equal(t, uint64(10), binary.BigEndian.Uint64(payload))
+}
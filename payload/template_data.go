@@ -0,0 +1,55 @@
+package payload
+
+import (
+	"encoding/csv"
+	"errors"
+	"os"
+)
+
+// ErrEmptyTemplateData is the error that is returned when the CSV data file of a template does not contain any row
+// after its header.
+var ErrEmptyTemplateData = errors.New("template data does not contain any row")
+
+// TemplateData represents the rows of a CSV data file, whose values are referenced in a template by the names of
+// the columns (the header of the CSV file).
+// The rows are used one after the other (round-robin) in the order of the request ids, and all the values in a
+// payload are from the same row.
+type TemplateData struct {
+	columns map[string]int
+	rows    [][]string
+}
+
+// LoadTemplateData loads the TemplateData from the CSV file, the first row of the file is the header.
+func LoadTemplateData(filePath string) (TemplateData, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return TemplateData{}, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return TemplateData{}, err
+	}
+	if len(records) < 2 {
+		return TemplateData{}, ErrEmptyTemplateData
+	}
+	columns := make(map[string]int, len(records[0]))
+	for index, column := range records[0] {
+		columns[column] = index
+	}
+	return TemplateData{columns: columns, rows: records[1:]}, nil
+}
+
+// column returns the index of the named column.
+func (data TemplateData) column(name string) (int, bool) {
+	index, ok := data.columns[name]
+	return index, ok
+}
+
+// value returns the value of the column in the row of the request id.
+func (data TemplateData) value(column int, requestId uint64) string {
+	return data.rows[(requestId-1)%uint64(len(data.rows))][column]
+}
@@ -0,0 +1,36 @@
+package payload
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadsTheTemplateData(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "users.csv")
+	assert.Nil(t, os.WriteFile(filePath, []byte("name,city\nalice,paris\nbob,tokyo\n"), 0644))
+
+	data, err := LoadTemplateData(filePath)
+	assert.Nil(t, err)
+
+	column, ok := data.column("city")
+	assert.True(t, ok)
+	assert.Equal(t, "paris", data.value(column, 1))
+	assert.Equal(t, "tokyo", data.value(column, 2))
+	assert.Equal(t, "paris", data.value(column, 3))
+}
+
+func TestLoadsTheTemplateDataWithOnlyTheHeader(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "users.csv")
+	assert.Nil(t, os.WriteFile(filePath, []byte("name,city\n"), 0644))
+
+	_, err := LoadTemplateData(filePath)
+	assert.ErrorIs(t, err, ErrEmptyTemplateData)
+}
+
+func TestLoadsTheNonExistentTemplateData(t *testing.T) {
+	_, err := LoadTemplateData("non-existing")
+	assert.Error(t, err)
+}
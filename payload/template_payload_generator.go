@@ -0,0 +1,503 @@
+package payload
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ErrInvalidTemplate is the error that is wrapped by the errors of parsing a template.
+var ErrInvalidTemplate = errors.New("invalid template")
+
+const (
+	actionStart       = "{{"
+	actionEnd         = "}}"
+	randomStringChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+// TemplatePayloadGenerator generates the payload of each request from a template.
+// The template is sent as-is (byte for byte), except the actions of the form {{name args...}}, which are replaced
+// by their values:
+//
+//	{{requestId [encoding]}}      the id of the request.
+//	{{connectionId [encoding]}}   the id of the connection of the request.
+//	{{workerId [encoding]}}       the id of the worker sending the request.
+//	{{sequence [encoding]}}       the sequence number of the request on its connection.
+//	{{randomInt min max}}         a random integer between min and max (both inclusive).
+//	{{randomString length}}       a random alphanumeric string of the length.
+//	{{randomString min max}}      a random alphanumeric string of a length between min and max (both inclusive).
+//	{{uuid}}                      a random (version 4) UUID.
+//	{{timestamp [unit]}}          the current time in the unit: s, ms (default), us, ns or rfc3339.
+//	{{counter [name] [start]}}    a counter (starting at start, default is 1) shared by all the requests, the
+//	                              actions with the same name share the same counter.
+//	{{csv column}}                the value of the column in the row of the request, from the TemplateData.
+//	{{hex bytes}}                 the bytes encoded in hex, for example: {{hex 0a00ff}}.
+//	{{base64 bytes}}              the bytes encoded in (standard) base64.
+//	{{length encoding}}...{{end}} the length (in bytes) of the rendered region up to the matching {{end}},
+//	                              followed by the region. The encoding is one of u8, u16, u32, u64 (big-endian),
+//	                              u16le, u32le, u64le (little-endian) or ascii (decimal digits). The length is
+//	                              truncated to the width of the encoding. The regions can be nested.
+//	{{lbraces}}                   a literal {{, which otherwise starts an action.
+//
+// The encoding of the ids is one of the encodings of {{length}}, the default is ascii (decimal digits), for
+// example: {{requestId u64}} is the request id in 8 bytes (big-endian).
+// The random values are derived from the seed and the request id, so a run with the same seed generates the
+// same random values for each request id.
+// TemplatePayloadGenerator is safe to be used by all the workers.
+type TemplatePayloadGenerator struct {
+	segments []templateSegment
+	data     TemplateData
+	seed     uint64
+}
+
+// templateSegment is a part of the template, which renders its value for a request.
+type templateSegment interface {
+	render(buffer []byte, state *renderState) []byte
+}
+
+// renderState is the state of rendering the template for a request.
+type renderState struct {
+	requestContext RequestContext
+	data           TemplateData
+	random         uint64
+}
+
+// templateParser parses the template into templateSegments.
+type templateParser struct {
+	template []byte
+	data     TemplateData
+	counters map[string]*counterSegment
+}
+
+// NewTemplatePayloadGenerator creates a new instance of TemplatePayloadGenerator, it returns an error (wrapping
+// ErrInvalidTemplate) if the template can not be parsed.
+// The data is required only if the template references the columns of a CSV data file ({{csv column}}).
+func NewTemplatePayloadGenerator(template []byte, data TemplateData, seed int64) (TemplatePayloadGenerator, error) {
+	parser := &templateParser{template: template, data: data, counters: make(map[string]*counterSegment)}
+	segments, closed, err := parser.parse()
+	if err != nil {
+		return TemplatePayloadGenerator{}, err
+	}
+	if closed {
+		return TemplatePayloadGenerator{}, fmt.Errorf("%w: {{end}} without {{length}}", ErrInvalidTemplate)
+	}
+	return TemplatePayloadGenerator{segments: segments, data: data, seed: uint64(seed)}, nil
+}
+
+// Generate generates the payload for the request id, the other fields of the RequestContext are zero.
+func (generator TemplatePayloadGenerator) Generate(requestId uint64) []byte {
+	return generator.GenerateWithContext(RequestContext{RequestId: requestId})
+}
+
+// GenerateWithContext generates the payload for the RequestContext.
+func (generator TemplatePayloadGenerator) GenerateWithContext(requestContext RequestContext) []byte {
+	state := &renderState{
+		requestContext: requestContext,
+		data:           generator.data,
+		random:         randomState(generator.seed, requestContext.RequestId),
+	}
+	return renderSegments(nil, generator.segments, state)
+}
+
+// parse parses the template up to its end, or up to an {{end}} action, in which case closed is true.
+func (parser *templateParser) parse() (segments []templateSegment, closed bool, err error) {
+	for len(parser.template) > 0 {
+		start := bytes.Index(parser.template, []byte(actionStart))
+		if start < 0 {
+			segments = append(segments, literalSegment(parser.template))
+			parser.template = nil
+			break
+		}
+		if start > 0 {
+			segments = append(segments, literalSegment(parser.template[:start]))
+		}
+		end := bytes.Index(parser.template[start:], []byte(actionEnd))
+		if end < 0 {
+			return nil, false, fmt.Errorf("%w: unclosed action %q", ErrInvalidTemplate, parser.template[start:])
+		}
+		action := strings.Fields(string(parser.template[start+len(actionStart) : start+end]))
+		parser.template = parser.template[start+end+len(actionEnd):]
+		if len(action) == 0 {
+			return nil, false, fmt.Errorf("%w: empty action", ErrInvalidTemplate)
+		}
+		if action[0] == "end" {
+			return segments, true, nil
+		}
+		segment, err := parser.parseAction(action[0], action[1:])
+		if errors.Is(err, ErrInvalidTemplate) {
+			return nil, false, err
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("%w: {{%v}}: %v", ErrInvalidTemplate, strings.Join(action, " "), err)
+		}
+		segments = append(segments, segment)
+	}
+	return segments, false, nil
+}
+
+// parseAction parses the action with its arguments into a templateSegment.
+func (parser *templateParser) parseAction(name string, arguments []string) (templateSegment, error) {
+	switch name {
+	case "requestId":
+		return parseContext(arguments, func(requestContext RequestContext) int64 { return int64(requestContext.RequestId) })
+	case "connectionId":
+		return parseContext(arguments, func(requestContext RequestContext) int64 { return int64(requestContext.ConnectionId) })
+	case "workerId":
+		return parseContext(arguments, func(requestContext RequestContext) int64 { return int64(requestContext.WorkerId) })
+	case "sequence":
+		return parseContext(arguments, func(requestContext RequestContext) int64 {
+			return int64(requestContext.ConnectionSequence)
+		})
+	case "randomInt":
+		return parseRandomInt(arguments)
+	case "randomString":
+		return parseRandomString(arguments)
+	case "uuid":
+		return uuidSegment{}, nil
+	case "timestamp":
+		return parseTimestamp(arguments)
+	case "counter":
+		return parser.parseCounter(arguments)
+	case "csv":
+		return parser.parseCsv(arguments)
+	case "hex":
+		return parseBytes(arguments, hex.DecodeString)
+	case "base64":
+		return parseBytes(arguments, base64.StdEncoding.DecodeString)
+	case "length":
+		return parser.parseLength(arguments)
+	case "lbraces":
+		return literalSegment(actionStart), nil
+	default:
+		return nil, errors.New("unsupported action")
+	}
+}
+
+// parseContext parses {{requestId [encoding]}}, {{connectionId [encoding]}}, {{workerId [encoding]}} and
+// {{sequence [encoding]}}.
+func parseContext(arguments []string, value func(requestContext RequestContext) int64) (templateSegment, error) {
+	if len(arguments) > 1 {
+		return nil, errors.New("expected at most one encoding")
+	}
+	encoding := "ascii"
+	if len(arguments) == 1 {
+		encoding = arguments[0]
+	}
+	if err := assertEncoding(encoding); err != nil {
+		return nil, err
+	}
+	return contextSegment{value: value, encoding: encoding}, nil
+}
+
+// parseRandomInt parses {{randomInt min max}}.
+func parseRandomInt(arguments []string) (templateSegment, error) {
+	if len(arguments) != 2 {
+		return nil, errors.New("expected min and max")
+	}
+	min, err := strconv.ParseInt(arguments[0], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	max, err := strconv.ParseInt(arguments[1], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	if min > max {
+		return nil, errors.New("min cannot be greater than max")
+	}
+	return randomIntSegment{min: min, max: max}, nil
+}
+
+// parseRandomString parses {{randomString length}} and {{randomString min max}}.
+func parseRandomString(arguments []string) (templateSegment, error) {
+	if len(arguments) != 1 && len(arguments) != 2 {
+		return nil, errors.New("expected length, or min and max")
+	}
+	lengths := make([]int, len(arguments))
+	for index, argument := range arguments {
+		length, err := strconv.Atoi(argument)
+		if err != nil {
+			return nil, err
+		}
+		if length < 0 {
+			return nil, errors.New("length cannot be negative")
+		}
+		lengths[index] = length
+	}
+	if len(lengths) == 1 {
+		return randomStringSegment{minLength: lengths[0], maxLength: lengths[0]}, nil
+	}
+	if lengths[0] > lengths[1] {
+		return nil, errors.New("min cannot be greater than max")
+	}
+	return randomStringSegment{minLength: lengths[0], maxLength: lengths[1]}, nil
+}
+
+// parseTimestamp parses {{timestamp [unit]}}.
+func parseTimestamp(arguments []string) (templateSegment, error) {
+	if len(arguments) > 1 {
+		return nil, errors.New("expected at most one unit")
+	}
+	unit := "ms"
+	if len(arguments) == 1 {
+		unit = arguments[0]
+	}
+	switch unit {
+	case "s", "ms", "us", "ns", "rfc3339":
+		return timestampSegment{unit: unit}, nil
+	default:
+		return nil, errors.New("unit must be one of s, ms, us, ns or rfc3339")
+	}
+}
+
+// parseCounter parses {{counter [name] [start]}}.
+func (parser *templateParser) parseCounter(arguments []string) (templateSegment, error) {
+	if len(arguments) > 2 {
+		return nil, errors.New("expected at most name and start")
+	}
+	name, start := "", int64(1)
+	if len(arguments) > 0 {
+		name = arguments[0]
+	}
+	if len(arguments) > 1 {
+		parsedStart, err := strconv.ParseInt(arguments[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		start = parsedStart
+	}
+	if counter, ok := parser.counters[name]; ok {
+		return counter, nil
+	}
+	counter := &counterSegment{start: start}
+	parser.counters[name] = counter
+	return counter, nil
+}
+
+// parseCsv parses {{csv column}}.
+func (parser *templateParser) parseCsv(arguments []string) (templateSegment, error) {
+	if len(arguments) != 1 {
+		return nil, errors.New("expected the column")
+	}
+	column, ok := parser.data.column(arguments[0])
+	if !ok {
+		return nil, errors.New("column does not exist in the data")
+	}
+	return csvSegment{column: column}, nil
+}
+
+// parseBytes parses {{hex bytes}} and {{base64 bytes}}.
+func parseBytes(arguments []string, decode func(string) ([]byte, error)) (templateSegment, error) {
+	if len(arguments) != 1 {
+		return nil, errors.New("expected the bytes")
+	}
+	content, err := decode(arguments[0])
+	if err != nil {
+		return nil, err
+	}
+	return literalSegment(content), nil
+}
+
+// parseLength parses {{length encoding}}...{{end}}.
+func (parser *templateParser) parseLength(arguments []string) (templateSegment, error) {
+	if len(arguments) != 1 {
+		return nil, errors.New("expected the encoding")
+	}
+	segment := lengthSegment{encoding: arguments[0]}
+	if err := assertEncoding(segment.encoding); err != nil {
+		return nil, err
+	}
+	segments, closed, err := parser.parse()
+	if err != nil {
+		return nil, err
+	}
+	if !closed {
+		return nil, errors.New("missing {{end}}")
+	}
+	segment.segments = segments
+	return segment, nil
+}
+
+// assertEncoding returns an error if the encoding of an integer is not supported.
+func assertEncoding(encoding string) error {
+	switch encoding {
+	case "u8", "u16", "u32", "u64", "u16le", "u32le", "u64le", "ascii":
+		return nil
+	default:
+		return errors.New("encoding must be one of u8, u16, u32, u64, u16le, u32le, u64le or ascii")
+	}
+}
+
+// appendEncoded appends the value in the encoding, the value is truncated to the width of the encoding.
+func appendEncoded(buffer []byte, encoding string, value uint64) []byte {
+	switch encoding {
+	case "u8":
+		return append(buffer, byte(value))
+	case "u16":
+		return binary.BigEndian.AppendUint16(buffer, uint16(value))
+	case "u32":
+		return binary.BigEndian.AppendUint32(buffer, uint32(value))
+	case "u64":
+		return binary.BigEndian.AppendUint64(buffer, value)
+	case "u16le":
+		return binary.LittleEndian.AppendUint16(buffer, uint16(value))
+	case "u32le":
+		return binary.LittleEndian.AppendUint32(buffer, uint32(value))
+	case "u64le":
+		return binary.LittleEndian.AppendUint64(buffer, value)
+	default:
+		return strconv.AppendUint(buffer, value, 10)
+	}
+}
+
+// renderSegments renders the templateSegments into the buffer.
+func renderSegments(buffer []byte, segments []templateSegment, state *renderState) []byte {
+	for _, segment := range segments {
+		buffer = segment.render(buffer, state)
+	}
+	return buffer
+}
+
+// nextRandom returns the next pseudo-random number of the request.
+func (state *renderState) nextRandom() uint64 {
+	return splitMix64(&state.random)
+}
+
+// literalSegment is the part of the template that is sent as-is.
+type literalSegment []byte
+
+// render appends the literal.
+func (segment literalSegment) render(buffer []byte, _ *renderState) []byte {
+	return append(buffer, segment...)
+}
+
+// contextSegment renders a field of the RequestContext in the encoding.
+type contextSegment struct {
+	value    func(requestContext RequestContext) int64
+	encoding string
+}
+
+// render appends the field of the RequestContext in the encoding, a negative field (the connection id of an
+// unestablished connection) is rendered with its sign in ascii.
+func (segment contextSegment) render(buffer []byte, state *renderState) []byte {
+	value := segment.value(state.requestContext)
+	if segment.encoding == "ascii" {
+		return strconv.AppendInt(buffer, value, 10)
+	}
+	return appendEncoded(buffer, segment.encoding, uint64(value))
+}
+
+// randomIntSegment renders a random integer between min and max (both inclusive).
+type randomIntSegment struct {
+	min, max int64
+}
+
+// render appends the random integer in decimal.
+func (segment randomIntSegment) render(buffer []byte, state *renderState) []byte {
+	span := uint64(segment.max-segment.min) + 1
+	if span == 0 {
+		return strconv.AppendInt(buffer, int64(state.nextRandom()), 10)
+	}
+	return strconv.AppendInt(buffer, segment.min+int64(state.nextRandom()%span), 10)
+}
+
+// randomStringSegment renders a random alphanumeric string of a length between minLength and maxLength.
+type randomStringSegment struct {
+	minLength, maxLength int
+}
+
+// render appends the random string.
+func (segment randomStringSegment) render(buffer []byte, state *renderState) []byte {
+	length := segment.minLength
+	if segment.maxLength > segment.minLength {
+		length += int(state.nextRandom() % uint64(segment.maxLength-segment.minLength+1))
+	}
+	for index := 0; index < length; index++ {
+		buffer = append(buffer, randomStringChars[state.nextRandom()%uint64(len(randomStringChars))])
+	}
+	return buffer
+}
+
+// uuidSegment renders a random (version 4) UUID.
+type uuidSegment struct{}
+
+// render appends the UUID in its canonical form (8-4-4-4-12 hex digits).
+func (segment uuidSegment) render(buffer []byte, state *renderState) []byte {
+	var uuid [16]byte
+	binary.BigEndian.PutUint64(uuid[:8], state.nextRandom())
+	binary.BigEndian.PutUint64(uuid[8:], state.nextRandom())
+	uuid[6] = (uuid[6] & 0x0f) | 0x40
+	uuid[8] = (uuid[8] & 0x3f) | 0x80
+
+	encoded := make([]byte, 32)
+	hex.Encode(encoded, uuid[:])
+	buffer = append(buffer, encoded[:8]...)
+	for _, part := range [][]byte{encoded[8:12], encoded[12:16], encoded[16:20], encoded[20:]} {
+		buffer = append(buffer, '-')
+		buffer = append(buffer, part...)
+	}
+	return buffer
+}
+
+// timestampSegment renders the current time in the unit.
+type timestampSegment struct {
+	unit string
+}
+
+// render appends the current time.
+func (segment timestampSegment) render(buffer []byte, _ *renderState) []byte {
+	now := time.Now()
+	switch segment.unit {
+	case "s":
+		return strconv.AppendInt(buffer, now.Unix(), 10)
+	case "us":
+		return strconv.AppendInt(buffer, now.UnixMicro(), 10)
+	case "ns":
+		return strconv.AppendInt(buffer, now.UnixNano(), 10)
+	case "rfc3339":
+		return now.AppendFormat(buffer, time.RFC3339Nano)
+	default:
+		return strconv.AppendInt(buffer, now.UnixMilli(), 10)
+	}
+}
+
+// counterSegment renders a counter shared by all the requests.
+type counterSegment struct {
+	start int64
+	count atomic.Int64
+}
+
+// render increments the counter and appends its value before the increment.
+func (segment *counterSegment) render(buffer []byte, _ *renderState) []byte {
+	return strconv.AppendInt(buffer, segment.start+segment.count.Add(1)-1, 10)
+}
+
+// csvSegment renders the value of the column in the row of the request.
+type csvSegment struct {
+	column int
+}
+
+// render appends the value of the column.
+func (segment csvSegment) render(buffer []byte, state *renderState) []byte {
+	return append(buffer, state.data.value(segment.column, state.requestContext.RequestId)...)
+}
+
+// lengthSegment renders the length of the region of the template, followed by the region.
+type lengthSegment struct {
+	encoding string
+	segments []templateSegment
+}
+
+// render appends the length of the rendered region in the encoding, followed by the rendered region.
+func (segment lengthSegment) render(buffer []byte, state *renderState) []byte {
+	region := renderSegments(nil, segment.segments, state)
+	buffer = appendEncoded(buffer, segment.encoding, uint64(len(region)))
+	return append(buffer, region...)
+}
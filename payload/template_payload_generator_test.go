@@ -0,0 +1,178 @@
+package payload
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGeneratesTheTemplateWithoutActions(t *testing.T) {
+	generator, err := NewTemplatePayloadGenerator([]byte("PING\r\n\x00"), TemplateData{}, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("PING\r\n\x00"), generator.Generate(1))
+}
+
+func TestGeneratesTheTemplateWithTheRequestContext(t *testing.T) {
+	generator, err := NewTemplatePayloadGenerator(
+		[]byte("GET {{requestId}} {{ connectionId }} {{workerId}} {{sequence}}\n"),
+		TemplateData{},
+		0,
+	)
+	assert.Nil(t, err)
+
+	payload := generator.GenerateWithContext(RequestContext{RequestId: 10, ConnectionId: 2, WorkerId: 3, ConnectionSequence: 5})
+	assert.Equal(t, "GET 10 2 3 5\n", string(payload))
+}
+
+func TestGeneratesTheTemplateWithTheRequestContextInBinary(t *testing.T) {
+	generator, err := NewTemplatePayloadGenerator(
+		[]byte("{{requestId u64}}{{connectionId u16le}}{{workerId u8}}{{sequence ascii}}"),
+		TemplateData{},
+		0,
+	)
+	assert.Nil(t, err)
+
+	payload := generator.GenerateWithContext(RequestContext{RequestId: 10, ConnectionId: 2, WorkerId: 3, ConnectionSequence: 5})
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 10, 0x02, 0x00, 0x03, '5'}, payload)
+}
+
+func TestGeneratesTheTemplateWithLiteralBraces(t *testing.T) {
+	generator, err := NewTemplatePayloadGenerator([]byte("{{lbraces}}requestId}} {{requestId}}"), TemplateData{}, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, "{{requestId}} 7", string(generator.Generate(7)))
+}
+
+func TestGeneratesTheTemplateWithRandomIntsInTheRange(t *testing.T) {
+	generator, err := NewTemplatePayloadGenerator([]byte("{{randomInt -2 2}}"), TemplateData{}, 0)
+	assert.Nil(t, err)
+
+	values := make(map[int]bool)
+	for requestId := uint64(1); requestId <= 200; requestId++ {
+		value, err := strconv.Atoi(string(generator.Generate(requestId)))
+		assert.Nil(t, err)
+		assert.True(t, value >= -2 && value <= 2)
+		values[value] = true
+	}
+	assert.Equal(t, 5, len(values))
+}
+
+func TestGeneratesTheTemplateWithRandomStrings(t *testing.T) {
+	generator, err := NewTemplatePayloadGenerator([]byte("{{randomString 4}}:{{randomString 2 6}}"), TemplateData{}, 0)
+	assert.Nil(t, err)
+
+	expression := regexp.MustCompile("^[a-zA-Z0-9]{4}:[a-zA-Z0-9]{2,6}$")
+	for requestId := uint64(1); requestId <= 100; requestId++ {
+		assert.Regexp(t, expression, string(generator.Generate(requestId)))
+	}
+}
+
+func TestGeneratesTheTemplateWithUUIDs(t *testing.T) {
+	generator, err := NewTemplatePayloadGenerator([]byte("{{uuid}}"), TemplateData{}, 0)
+	assert.Nil(t, err)
+
+	expression := regexp.MustCompile("^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$")
+	assert.Regexp(t, expression, string(generator.Generate(1)))
+	assert.NotEqual(t, generator.Generate(1), generator.Generate(2))
+}
+
+func TestGeneratesTheSameRandomValuesWithTheSameSeed(t *testing.T) {
+	generator, err := NewTemplatePayloadGenerator([]byte("{{uuid}} {{randomInt 0 1000000}}"), TemplateData{}, 42)
+	assert.Nil(t, err)
+	otherGenerator, err := NewTemplatePayloadGenerator([]byte("{{uuid}} {{randomInt 0 1000000}}"), TemplateData{}, 42)
+	assert.Nil(t, err)
+
+	assert.Equal(t, generator.Generate(7), otherGenerator.Generate(7))
+}
+
+func TestGeneratesTheTemplateWithTimestamps(t *testing.T) {
+	generator, err := NewTemplatePayloadGenerator([]byte("{{timestamp}} {{timestamp s}} {{timestamp rfc3339}}"), TemplateData{}, 0)
+	assert.Nil(t, err)
+
+	parts := strings.Split(string(generator.Generate(1)), " ")
+	assert.Equal(t, 3, len(parts))
+	assert.Regexp(t, regexp.MustCompile("^[0-9]{13}$"), parts[0])
+	assert.Regexp(t, regexp.MustCompile("^[0-9]{10}$"), parts[1])
+	assert.Regexp(t, regexp.MustCompile("^[0-9]{4}-[0-9]{2}-[0-9]{2}T"), parts[2])
+}
+
+func TestGeneratesTheTemplateWithNamedCounters(t *testing.T) {
+	generator, err := NewTemplatePayloadGenerator(
+		[]byte("{{counter orders 100}} {{counter}} {{counter orders}}"),
+		TemplateData{},
+		0,
+	)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "100 1 101", string(generator.Generate(1)))
+	assert.Equal(t, "102 2 103", string(generator.Generate(2)))
+}
+
+func TestGeneratesTheTemplateWithTheTemplateData(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "users.csv")
+	assert.Nil(t, os.WriteFile(filePath, []byte("name,city\nalice,paris\nbob,tokyo\n"), 0644))
+	data, err := LoadTemplateData(filePath)
+	assert.Nil(t, err)
+
+	generator, err := NewTemplatePayloadGenerator([]byte("{{csv name}}@{{csv city}}"), data, 0)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "alice@paris", string(generator.Generate(1)))
+	assert.Equal(t, "bob@tokyo", string(generator.Generate(2)))
+	assert.Equal(t, "alice@paris", string(generator.Generate(3)))
+}
+
+func TestGeneratesTheTemplateWithBinarySegments(t *testing.T) {
+	generator, err := NewTemplatePayloadGenerator([]byte("{{hex 00ff0a}}{{base64 AQI=}}"), TemplateData{}, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x00, 0xff, 0x0a, 0x01, 0x02}, generator.Generate(1))
+}
+
+func TestGeneratesTheTemplateWithLengthFields(t *testing.T) {
+	generator, err := NewTemplatePayloadGenerator(
+		[]byte("{{hex 01}}{{length u16}}id={{requestId}}{{length u8}}ab{{end}}{{end}}{{length ascii}}xyz{{end}}"),
+		TemplateData{},
+		0,
+	)
+	assert.Nil(t, err)
+
+	expected := []byte{0x01, 0x00, 0x08}
+	expected = append(expected, "id=10"...)
+	expected = append(expected, 0x02, 'a', 'b')
+	expected = append(expected, "3xyz"...)
+	assert.Equal(t, expected, generator.Generate(10))
+}
+
+func TestGeneratesTheTemplateWithALittleEndianLengthField(t *testing.T) {
+	generator, err := NewTemplatePayloadGenerator([]byte("{{length u32le}}abc{{end}}"), TemplateData{}, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{0x03, 0x00, 0x00, 0x00, 'a', 'b', 'c'}, generator.Generate(1))
+}
+
+func TestParsesAnInvalidTemplate(t *testing.T) {
+	templates := []string{
+		"{{requestId",
+		"{{}}",
+		"{{unknown}}",
+		"{{randomInt 5 1}}",
+		"{{randomInt 1}}",
+		"{{randomString -1}}",
+		"{{timestamp days}}",
+		"{{csv name}}",
+		"{{hex zz}}",
+		"{{base64 !}}",
+		"{{length u24}}a{{end}}",
+		"{{length u16}}a",
+		"{{requestId u24}}",
+		"{{requestId u64 u8}}",
+		"a{{end}}",
+	}
+	for _, template := range templates {
+		_, err := NewTemplatePayloadGenerator([]byte(template), TemplateData{}, 0)
+		assert.ErrorIs(t, err, ErrInvalidTemplate, template)
+	}
+}
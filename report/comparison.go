@@ -0,0 +1,259 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Direction defines which change of a compared metric is an improvement.
+// HigherIsBetter: a decrease is a regression (for example: throughput).
+// LowerIsBetter: an increase is a regression (for example: latency and errors).
+// Informational: the change is neither an improvement nor a regression (for example: payload sizes).
+type Direction uint8
+
+const (
+	HigherIsBetter Direction = iota
+	LowerIsBetter
+	Informational
+)
+
+// Tolerances defines the changes (in percent) of the metrics of a candidate Report, compared to a baseline Report,
+// beyond which the metrics are flagged as regressions.
+// Throughput is the maximum decrease of the throughput.
+// Latency is the maximum increase of each latency percentile.
+// Errors is the maximum increase of each error count, the total error counts and the error counts of each type
+// (ErrorCountByType). Any error in the candidate is a regression if the baseline has no errors of the same type.
+type Tolerances struct {
+	Throughput float64
+	Latency    float64
+	Errors     float64
+}
+
+// MetricComparison represents the comparison of a single metric between the baseline and the candidate Report.
+// Latency metrics are in nanoseconds. PercentChange is +Inf if the baseline is zero and the candidate is not.
+type MetricComparison struct {
+	Name          string
+	Baseline      float64
+	Candidate     float64
+	Delta         float64
+	PercentChange float64
+	Direction     Direction
+	Regression    bool
+}
+
+// Comparison represents the comparison of a candidate Report with a baseline Report.
+// Regressed is true if any of the metrics is a regression.
+type Comparison struct {
+	Metrics   []MetricComparison
+	Regressed bool
+}
+
+// ReadJsonReport reads the Report written in the JsonFormat.
+func ReadJsonReport(reader io.Reader) (Report, error) {
+	var report Report
+	if err := json.NewDecoder(reader).Decode(&report); err != nil {
+		return Report{}, err
+	}
+	return report, nil
+}
+
+// Throughput returns the rate of the successful requests (per second), over the time to complete the load.
+func (report Report) Throughput() float64 {
+	if report.Load.TotalTime <= 0 {
+		return 0
+	}
+	return float64(report.Load.SuccessCount) / report.Load.TotalTime.Seconds()
+}
+
+// Compare compares the candidate Report with the baseline Report: the throughput, the latency percentiles (if
+// measured in both the reports), the error counts (in total and by type) and the payload sizes. The metrics that
+// change beyond the Tolerances are flagged as regressions.
+func Compare(baseline, candidate Report, tolerances Tolerances) Comparison {
+	comparison := Comparison{}
+	add := func(name string, baselineValue, candidateValue float64, direction Direction, tolerance float64) {
+		metric := newMetricComparison(name, baselineValue, candidateValue, direction, tolerance)
+		comparison.Metrics = append(comparison.Metrics, metric)
+		comparison.Regressed = comparison.Regressed || metric.Regression
+	}
+
+	add("Throughput (req/s)", baseline.Throughput(), candidate.Throughput(), HigherIsBetter, tolerances.Throughput)
+	add("Load.TotalRequests", float64(baseline.Load.TotalRequests), float64(candidate.Load.TotalRequests), Informational, 0)
+	add("Load.ErrorCount", float64(baseline.Load.ErrorCount), float64(candidate.Load.ErrorCount), LowerIsBetter, tolerances.Errors)
+	addErrorsByType := func(section string, baselineErrors, candidateErrors map[string]uint) {
+		for _, errorType := range errorTypesOf(baselineErrors, candidateErrors) {
+			add(
+				section+".ErrorCountByType["+errorType+"]",
+				float64(baselineErrors[errorType]),
+				float64(candidateErrors[errorType]),
+				LowerIsBetter,
+				tolerances.Errors,
+			)
+		}
+	}
+
+	addErrorsByType("Load", baseline.Load.ErrorCountByType, candidate.Load.ErrorCountByType)
+	add("Load.DroppedCount", float64(baseline.Load.DroppedCount), float64(candidate.Load.DroppedCount), LowerIsBetter, tolerances.Errors)
+	add(
+		"Load.AveragePayloadSize",
+		float64(baseline.Load.AveragePayloadLengthBytes),
+		float64(candidate.Load.AveragePayloadLengthBytes),
+		Informational,
+		0,
+	)
+	if baseline.Response.IsAvailableForReporting && candidate.Response.IsAvailableForReporting {
+		add(
+			"Response.TotalResponses",
+			float64(baseline.Response.TotalResponses),
+			float64(candidate.Response.TotalResponses),
+			Informational,
+			0,
+		)
+		add(
+			"Response.ErrorCount",
+			float64(baseline.Response.ErrorCount),
+			float64(candidate.Response.ErrorCount),
+			LowerIsBetter,
+			tolerances.Errors,
+		)
+		addErrorsByType("Response", baseline.Response.ErrorCountByType, candidate.Response.ErrorCountByType)
+		add(
+			"Response.AveragePayloadSize",
+			float64(baseline.Response.AverageResponsePayloadLengthBytes),
+			float64(candidate.Response.AverageResponsePayloadLengthBytes),
+			Informational,
+			0,
+		)
+	}
+	addLatency := func(section string, baselineLatency, candidateLatency LatencyMetrics) {
+		if baselineLatency.TotalSamples == 0 || candidateLatency.TotalSamples == 0 {
+			return
+		}
+		percentiles := []struct {
+			name      string
+			baseline  time.Duration
+			candidate time.Duration
+		}{
+			{"p50", baselineLatency.P50, candidateLatency.P50},
+			{"p90", baselineLatency.P90, candidateLatency.P90},
+			{"p95", baselineLatency.P95, candidateLatency.P95},
+			{"p99", baselineLatency.P99, candidateLatency.P99},
+			{"p99.9", baselineLatency.P999, candidateLatency.P999},
+			{"max", baselineLatency.Max, candidateLatency.Max},
+		}
+		for _, percentile := range percentiles {
+			add(
+				section+"."+percentile.name,
+				float64(percentile.baseline),
+				float64(percentile.candidate),
+				LowerIsBetter,
+				tolerances.Latency,
+			)
+		}
+	}
+	addLatency("Latency", baseline.Response.Latency, candidate.Response.Latency)
+	addLatency("CorrectedLatency", baseline.Response.CorrectedLatency, candidate.Response.CorrectedLatency)
+	return comparison
+}
+
+// errorTypesOf returns the sorted error types of the baseline and the candidate errors.
+func errorTypesOf(baselineErrors, candidateErrors map[string]uint) []string {
+	errorTypes := make([]string, 0, len(baselineErrors)+len(candidateErrors))
+	for errorType := range baselineErrors {
+		errorTypes = append(errorTypes, errorType)
+	}
+	for errorType := range candidateErrors {
+		if _, ok := baselineErrors[errorType]; !ok {
+			errorTypes = append(errorTypes, errorType)
+		}
+	}
+	sort.Strings(errorTypes)
+	return errorTypes
+}
+
+// newMetricComparison creates a new instance of MetricComparison, flagging it as a regression if it changes in
+// the worse Direction beyond the tolerance (in percent).
+func newMetricComparison(
+	name string,
+	baseline, candidate float64,
+	direction Direction,
+	tolerance float64,
+) MetricComparison {
+	metric := MetricComparison{
+		Name:      name,
+		Baseline:  baseline,
+		Candidate: candidate,
+		Delta:     candidate - baseline,
+		Direction: direction,
+	}
+	switch {
+	case baseline != 0:
+		metric.PercentChange = (candidate - baseline) / baseline * 100
+	case candidate != 0:
+		metric.PercentChange = math.Inf(1)
+	}
+	switch direction {
+	case HigherIsBetter:
+		metric.Regression = -metric.PercentChange > tolerance
+	case LowerIsBetter:
+		metric.Regression = metric.PercentChange > tolerance
+	}
+	return metric
+}
+
+// Print prints the Comparison on the provided io.Writer, with a table of the metrics.
+func (comparison Comparison) Print(writer io.Writer) error {
+	status := "no regressions"
+	if comparison.Regressed {
+		status = "REGRESSED"
+	}
+	builder := &strings.Builder{}
+	_, _ = fmt.Fprintf(builder, "\nComparison: %v\n", status)
+
+	tableWriter := tabwriter.NewWriter(builder, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tableWriter, "  Metric\tBaseline\tCandidate\tDelta\tChange\tRegression")
+	for _, metric := range comparison.Metrics {
+		regression := ""
+		if metric.Regression {
+			regression = "yes"
+		}
+		_, _ = fmt.Fprintf(
+			tableWriter,
+			"  %v\t%v\t%v\t%v\t%v\t%v\n",
+			metric.Name,
+			metric.format(metric.Baseline),
+			metric.format(metric.Candidate),
+			metric.format(metric.Delta),
+			formatPercentChange(metric.PercentChange),
+			regression,
+		)
+	}
+	_ = tableWriter.Flush()
+
+	_, err := io.WriteString(writer, builder.String())
+	return err
+}
+
+// format formats the value of the metric, latency metrics are formatted as durations.
+func (metric MetricComparison) format(value float64) string {
+	if strings.Contains(metric.Name, "Latency.") {
+		return time.Duration(value).String()
+	}
+	if value == math.Trunc(value) {
+		return fmt.Sprintf("%.0f", value)
+	}
+	return fmt.Sprintf("%.2f", value)
+}
+
+// formatPercentChange formats the percent change with its sign.
+func formatPercentChange(percentChange float64) string {
+	if math.IsInf(percentChange, 1) {
+		return "+Inf%"
+	}
+	return fmt.Sprintf("%+.2f%%", percentChange)
+}
@@ -0,0 +1,173 @@
+package report
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func reportForComparison(successCount uint, errorCount uint, p99 time.Duration) Report {
+	report := Report{}
+	report.Load.TotalRequests = successCount + errorCount
+	report.Load.SuccessCount = successCount
+	report.Load.ErrorCount = errorCount
+	report.Load.TotalTime = 10 * time.Second
+	report.Load.AveragePayloadLengthBytes = 10
+	report.Response.IsAvailableForReporting = true
+	report.Response.TotalResponses = successCount
+	report.Response.Latency = LatencyMetrics{TotalSamples: 10, P50: p99 / 2, P90: p99, P95: p99, P99: p99, P999: p99, Max: p99}
+	return report
+}
+
+func metricNamed(comparison Comparison, name string) MetricComparison {
+	for _, metric := range comparison.Metrics {
+		if metric.Name == name {
+			return metric
+		}
+	}
+	return MetricComparison{}
+}
+
+func TestCompareWithoutRegressions(t *testing.T) {
+	baseline := reportForComparison(1000, 0, 10*time.Millisecond)
+	candidate := reportForComparison(980, 0, 10500*time.Microsecond)
+
+	comparison := Compare(baseline, candidate, Tolerances{Throughput: 5, Latency: 10})
+	assert.False(t, comparison.Regressed)
+
+	throughput := metricNamed(comparison, "Throughput (req/s)")
+	assert.Equal(t, 100.0, throughput.Baseline)
+	assert.Equal(t, 98.0, throughput.Candidate)
+	assert.InDelta(t, -2.0, throughput.Delta, 0.0001)
+	assert.InDelta(t, -2.0, throughput.PercentChange, 0.0001)
+
+	p99 := metricNamed(comparison, "Latency.p99")
+	assert.InDelta(t, 5.0, p99.PercentChange, 0.0001)
+	assert.False(t, p99.Regression)
+}
+
+func TestCompareWithThroughputRegression(t *testing.T) {
+	comparison := Compare(
+		reportForComparison(1000, 0, 10*time.Millisecond),
+		reportForComparison(900, 0, 10*time.Millisecond),
+		Tolerances{Throughput: 5, Latency: 10},
+	)
+	assert.True(t, comparison.Regressed)
+	assert.True(t, metricNamed(comparison, "Throughput (req/s)").Regression)
+}
+
+func TestCompareWithLatencyRegression(t *testing.T) {
+	comparison := Compare(
+		reportForComparison(1000, 0, 10*time.Millisecond),
+		reportForComparison(1000, 0, 12*time.Millisecond),
+		Tolerances{Throughput: 5, Latency: 10},
+	)
+	assert.True(t, comparison.Regressed)
+	assert.True(t, metricNamed(comparison, "Latency.p99").Regression)
+	assert.False(t, metricNamed(comparison, "Throughput (req/s)").Regression)
+}
+
+func TestCompareWithErrorsOverABaselineWithoutErrors(t *testing.T) {
+	comparison := Compare(
+		reportForComparison(1000, 0, 10*time.Millisecond),
+		reportForComparison(1000, 1, 10*time.Millisecond),
+		Tolerances{Throughput: 5, Latency: 10, Errors: 50},
+	)
+	assert.True(t, comparison.Regressed)
+
+	errors := metricNamed(comparison, "Load.ErrorCount")
+	assert.True(t, math.IsInf(errors.PercentChange, 1))
+	assert.True(t, errors.Regression)
+}
+
+func TestCompareWithANewErrorTypeWithinTheTotalErrorsTolerance(t *testing.T) {
+	baseline := reportForComparison(1000, 10, 10*time.Millisecond)
+	baseline.Load.ErrorCountByType = map[string]uint{"broken pipe": 10}
+	candidate := reportForComparison(1000, 10, 10*time.Millisecond)
+	candidate.Load.ErrorCountByType = map[string]uint{"broken pipe": 9, "connection refused": 1}
+
+	comparison := Compare(baseline, candidate, Tolerances{Throughput: 5, Latency: 10, Errors: 50})
+	assert.True(t, comparison.Regressed)
+	assert.False(t, metricNamed(comparison, "Load.ErrorCount").Regression)
+	assert.False(t, metricNamed(comparison, "Load.ErrorCountByType[broken pipe]").Regression)
+
+	refused := metricNamed(comparison, "Load.ErrorCountByType[connection refused]")
+	assert.Equal(t, 0.0, refused.Baseline)
+	assert.Equal(t, 1.0, refused.Candidate)
+	assert.True(t, math.IsInf(refused.PercentChange, 1))
+	assert.True(t, refused.Regression)
+}
+
+func TestCompareWithTheResponseErrorsByType(t *testing.T) {
+	baseline := reportForComparison(1000, 0, 10*time.Millisecond)
+	baseline.Response.ErrorCount = 10
+	baseline.Response.ErrorCountByType = map[string]uint{"EOF": 10}
+	candidate := reportForComparison(1000, 0, 10*time.Millisecond)
+	candidate.Response.ErrorCount = 12
+	candidate.Response.ErrorCountByType = map[string]uint{"EOF": 12}
+
+	comparison := Compare(baseline, candidate, Tolerances{Throughput: 5, Latency: 10, Errors: 10})
+	assert.True(t, comparison.Regressed)
+
+	eof := metricNamed(comparison, "Response.ErrorCountByType[EOF]")
+	assert.InDelta(t, 20.0, eof.PercentChange, 0.0001)
+	assert.True(t, eof.Regression)
+}
+
+func TestCompareDoesNotFlagThePayloadSizes(t *testing.T) {
+	baseline := reportForComparison(1000, 0, 10*time.Millisecond)
+	candidate := reportForComparison(1000, 0, 10*time.Millisecond)
+	candidate.Load.AveragePayloadLengthBytes = 100
+
+	comparison := Compare(baseline, candidate, Tolerances{})
+	assert.False(t, comparison.Regressed)
+	assert.Equal(t, 900.0, metricNamed(comparison, "Load.AveragePayloadSize").PercentChange)
+}
+
+func TestCompareWithoutLatency(t *testing.T) {
+	baseline := reportForComparison(1000, 0, 10*time.Millisecond)
+	baseline.Response.Latency = LatencyMetrics{}
+
+	comparison := Compare(baseline, reportForComparison(1000, 0, 10*time.Millisecond), Tolerances{})
+	assert.Equal(t, "", metricNamed(comparison, "Latency.p99").Name)
+}
+
+func TestReadJsonReportWrittenInJsonFormat(t *testing.T) {
+	report := reportForComparison(1000, 2, 10*time.Millisecond)
+	report.Load.ErrorCountByType = map[string]uint{"connection reset": 2}
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, writeInFormat(buffer, &report, JsonFormat))
+
+	readReport, err := ReadJsonReport(buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, report.Load.SuccessCount, readReport.Load.SuccessCount)
+	assert.Equal(t, report.Load.TotalTime, readReport.Load.TotalTime)
+	assert.Equal(t, report.Response.Latency, readReport.Response.Latency)
+	assert.Equal(t, uint(2), readReport.Load.ErrorCountByType["connection reset"])
+}
+
+func TestReadJsonReportWithInvalidJson(t *testing.T) {
+	_, err := ReadJsonReport(bytes.NewBufferString("TotalRequests: 10"))
+	assert.Error(t, err)
+}
+
+func TestPrintComparison(t *testing.T) {
+	comparison := Compare(
+		reportForComparison(1000, 0, 10*time.Millisecond),
+		reportForComparison(1000, 0, 12*time.Millisecond),
+		Tolerances{Throughput: 5, Latency: 10},
+	)
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, comparison.Print(buffer))
+
+	output := buffer.String()
+	assert.Contains(t, output, "Comparison: REGRESSED")
+	assert.Contains(t, output, "Latency.p99")
+	assert.Contains(t, output, "10ms")
+	assert.Contains(t, output, "12ms")
+	assert.Contains(t, output, "+20.00%")
+}
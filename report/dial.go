@@ -0,0 +1,119 @@
+package report
+
+import (
+	"sync"
+	"time"
+)
+
+// DialMetrics defines the metrics of the dials of the connections to the target server.
+// TotalDials includes the initial dials, the dials that re-establish the connections that fail and the dials
+// that churn the connections. Latency is the time taken by each (TCP) connect, including the failed connects.
+// TotalHandshakes, ResumedHandshakes and HandshakeLatency are only reported for TLS connections, the
+// HandshakeLatency is the time taken by each TLS handshake (including the failed handshakes), separate from
+// the TCP connect time. TotalInitializations and InitializationLatency are only reported if the connections are
+// initialized (for example: authenticated) after they are established, the InitializationLatency is the time taken
+// by each initialization (including the failed initializations).
+// ErrorCount includes the failed connects, the failed handshakes and the failed initializations.
+// Network is the network of the target server: tcp, unix or udp. A udp dial does not exchange any packets
+// with the target server, it only binds the local socket.
+type DialMetrics struct {
+	Network               string
+	TotalDials            uint
+	ErrorCount            uint
+	ErrorCountByType      map[string]uint
+	Latency               LatencyMetrics
+	TotalHandshakes       uint
+	ResumedHandshakes     uint
+	HandshakeLatency      LatencyMetrics
+	TotalInitializations  uint
+	InitializationLatency LatencyMetrics
+}
+
+// DialRecorder records the latency and the errors of the dials, and computes DialMetrics.
+// DialRecorder is thread-safe, the connections are dialed by multiple goroutines.
+type DialRecorder struct {
+	lock                    sync.Mutex
+	totalDials              uint
+	errorCount              uint
+	errorCountByType        map[string]uint
+	latencies               *latencyRecorder
+	totalHandshakes         uint
+	resumedHandshakes       uint
+	handshakes              *latencyRecorder
+	initializations         uint
+	initializationLatencies *latencyRecorder
+}
+
+// NewDialRecorder creates a new instance of DialRecorder.
+func NewDialRecorder() *DialRecorder {
+	return &DialRecorder{
+		errorCountByType:        make(map[string]uint),
+		latencies:               newLatencyRecorder(),
+		handshakes:              newLatencyRecorder(),
+		initializationLatencies: newLatencyRecorder(),
+	}
+}
+
+// Record records the latency and the error (if any) of a single dial.
+func (recorder *DialRecorder) Record(latency time.Duration, err error) {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	recorder.totalDials++
+	recorder.latencies.record(latency)
+	if err != nil {
+		recorder.errorCount++
+		recorder.errorCountByType[err.Error()]++
+	}
+}
+
+// RecordHandshake records the latency, the error (if any) and the resumption of a single TLS handshake.
+func (recorder *DialRecorder) RecordHandshake(latency time.Duration, resumed bool, err error) {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	recorder.totalHandshakes++
+	recorder.handshakes.record(latency)
+	if resumed {
+		recorder.resumedHandshakes++
+	}
+	if err != nil {
+		recorder.errorCount++
+		recorder.errorCountByType[err.Error()]++
+	}
+}
+
+// RecordInitialization records the latency and the error (if any) of the initialization of a single connection.
+func (recorder *DialRecorder) RecordInitialization(latency time.Duration, err error) {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	recorder.initializations++
+	recorder.initializationLatencies.record(latency)
+	if err != nil {
+		recorder.errorCount++
+		recorder.errorCountByType[err.Error()]++
+	}
+}
+
+// Metrics computes the DialMetrics from the recorded dials.
+func (recorder *DialRecorder) Metrics() DialMetrics {
+	recorder.lock.Lock()
+	defer recorder.lock.Unlock()
+
+	errorCountByType := make(map[string]uint, len(recorder.errorCountByType))
+	for err, count := range recorder.errorCountByType {
+		errorCountByType[err] = count
+	}
+	return DialMetrics{
+		TotalDials:            recorder.totalDials,
+		ErrorCount:            recorder.errorCount,
+		ErrorCountByType:      errorCountByType,
+		Latency:               recorder.latencies.metrics(),
+		TotalHandshakes:       recorder.totalHandshakes,
+		ResumedHandshakes:     recorder.resumedHandshakes,
+		HandshakeLatency:      recorder.handshakes.metrics(),
+		TotalInitializations:  recorder.initializations,
+		InitializationLatency: recorder.initializationLatencies.metrics(),
+	}
+}
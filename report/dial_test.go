@@ -0,0 +1,64 @@
+package report
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialRecorderRecordsTheDials(t *testing.T) {
+	recorder := NewDialRecorder()
+	recorder.Record(2*time.Millisecond, nil)
+	recorder.Record(4*time.Millisecond, nil)
+	recorder.Record(time.Millisecond, errors.New("connection refused"))
+
+	metrics := recorder.Metrics()
+	assert.Equal(t, uint(3), metrics.TotalDials)
+	assert.Equal(t, uint(1), metrics.ErrorCount)
+	assert.Equal(t, uint(1), metrics.ErrorCountByType["connection refused"])
+	assert.Equal(t, uint(3), metrics.Latency.TotalSamples)
+	assert.InEpsilon(t, 2*time.Millisecond, metrics.Latency.P50, 0.01)
+	assert.Equal(t, 4*time.Millisecond, metrics.Latency.Max)
+}
+
+func TestDialRecorderWithoutDials(t *testing.T) {
+	metrics := NewDialRecorder().Metrics()
+	assert.Equal(t, uint(0), metrics.TotalDials)
+	assert.Equal(t, uint(0), metrics.Latency.TotalSamples)
+}
+
+func TestDialRecorderRecordsTheHandshakes(t *testing.T) {
+	recorder := NewDialRecorder()
+	recorder.Record(time.Millisecond, nil)
+	recorder.Record(time.Millisecond, nil)
+	recorder.RecordHandshake(6*time.Millisecond, false, nil)
+	recorder.RecordHandshake(3*time.Millisecond, true, errors.New("tls: bad certificate"))
+
+	metrics := recorder.Metrics()
+	assert.Equal(t, uint(2), metrics.TotalDials)
+	assert.Equal(t, uint(2), metrics.TotalHandshakes)
+	assert.Equal(t, uint(1), metrics.ResumedHandshakes)
+	assert.Equal(t, uint(1), metrics.ErrorCount)
+	assert.Equal(t, uint(1), metrics.ErrorCountByType["tls: bad certificate"])
+	assert.Equal(t, uint(2), metrics.Latency.TotalSamples)
+	assert.Equal(t, uint(2), metrics.HandshakeLatency.TotalSamples)
+	assert.Equal(t, 6*time.Millisecond, metrics.HandshakeLatency.Max)
+}
+
+func TestDialRecorderRecordsTheInitializations(t *testing.T) {
+	recorder := NewDialRecorder()
+	recorder.Record(time.Millisecond, nil)
+	recorder.Record(time.Millisecond, nil)
+	recorder.RecordInitialization(2*time.Millisecond, nil)
+	recorder.RecordInitialization(5*time.Millisecond, errors.New("connection initialization failed: unexpected ack"))
+
+	metrics := recorder.Metrics()
+	assert.Equal(t, uint(2), metrics.TotalDials)
+	assert.Equal(t, uint(2), metrics.TotalInitializations)
+	assert.Equal(t, uint(1), metrics.ErrorCount)
+	assert.Equal(t, uint(1), metrics.ErrorCountByType["connection initialization failed: unexpected ack"])
+	assert.Equal(t, uint(2), metrics.InitializationLatency.TotalSamples)
+	assert.Equal(t, 5*time.Millisecond, metrics.InitializationLatency.Max)
+}
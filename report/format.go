@@ -0,0 +1,148 @@
+package report
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Format defines the format in which the Report is written.
+// TextFormat is the human-readable format defined by the report template.
+// JsonFormat and CsvFormat are the machine-readable formats.
+type Format uint8
+
+const (
+	TextFormat Format = iota
+	JsonFormat
+	CsvFormat
+)
+
+// ParseFormat returns the Format identified by the name: text, json or csv.
+func ParseFormat(name string) (Format, error) {
+	switch name {
+	case "text":
+		return TextFormat, nil
+	case "json":
+		return JsonFormat, nil
+	case "csv":
+		return CsvFormat, nil
+	default:
+		return TextFormat, fmt.Errorf("unsupported report format %v", name)
+	}
+}
+
+// String returns the name of the Format.
+func (format Format) String() string {
+	switch format {
+	case JsonFormat:
+		return "json"
+	case CsvFormat:
+		return "csv"
+	default:
+		return "text"
+	}
+}
+
+// writeInFormat writes the report to the given writer in the given Format.
+func writeInFormat(writer io.Writer, report *Report, format Format) error {
+	switch format {
+	case JsonFormat:
+		return writeJson(writer, report)
+	case CsvFormat:
+		return writeCsv(writer, report)
+	default:
+		return write(writer, report)
+	}
+}
+
+// writeJson writes the report as JSON.
+// time.Duration fields are serialized in nanoseconds and time.Time fields in RFC 3339 format.
+func writeJson(writer io.Writer, report *Report) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// writeCsv writes the report as CSV with three columns: section, metric and value.
+// Each exported field of the Report is written as a row. The section identifies the path of the field, for
+// example: Load, Response.Latency or Load.ErrorCountByType. For maps, the metric is the key of the map.
+// time.Duration fields are written in nanoseconds and time.Time fields in RFC 3339 format.
+func writeCsv(writer io.Writer, report *Report) error {
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write([]string{"section", "metric", "value"}); err != nil {
+		return err
+	}
+	rows := flatten("", reflect.ValueOf(*report), nil)
+	if err := csvWriter.WriteAll(rows); err != nil {
+		return err
+	}
+	return csvWriter.Error()
+}
+
+// flatten flattens the exported fields of the struct into the rows of section, metric and value.
+func flatten(section string, value reflect.Value, rows [][]string) [][]string {
+	valueType := value.Type()
+	for index := 0; index < valueType.NumField(); index++ {
+		field := valueType.Field(index)
+		if !field.IsExported() {
+			continue
+		}
+		rows = flattenField(section, field.Name, value.Field(index), rows)
+	}
+	return rows
+}
+
+// flattenField flattens a single field into the rows.
+// Nested structs, slices and maps are flattened with the name of the field appended to the section.
+func flattenField(section string, name string, value reflect.Value, rows [][]string) [][]string {
+	nestedSection := name
+	if section != "" {
+		nestedSection = section + "." + name
+	}
+	switch {
+	case value.Type() == reflect.TypeOf(time.Time{}) || value.Type() == reflect.TypeOf(time.Duration(0)):
+		return append(rows, []string{section, name, formatCsvValue(value)})
+	case value.Kind() == reflect.Struct:
+		return flatten(nestedSection, value, rows)
+	case value.Kind() == reflect.Slice:
+		for index := 0; index < value.Len(); index++ {
+			rows = flattenField(nestedSection, fmt.Sprintf("%d", index), value.Index(index), rows)
+		}
+		return rows
+	case value.Kind() == reflect.Map:
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprintf("%v", keys[i].Interface()) < fmt.Sprintf("%v", keys[j].Interface())
+		})
+		for _, key := range keys {
+			rows = flattenField(nestedSection, fmt.Sprintf("%v", key.Interface()), value.MapIndex(key), rows)
+		}
+		return rows
+	case value.Kind() == reflect.Pointer:
+		if value.IsNil() {
+			return rows
+		}
+		return flattenField(section, name, value.Elem(), rows)
+	default:
+		return append(rows, []string{section, name, formatCsvValue(value)})
+	}
+}
+
+// formatCsvValue formats a single value for CSV.
+func formatCsvValue(value reflect.Value) string {
+	switch typed := value.Interface().(type) {
+	case time.Time:
+		if typed.IsZero() {
+			return ""
+		}
+		return typed.Format(time.RFC3339Nano)
+	case time.Duration:
+		return fmt.Sprintf("%d", typed.Nanoseconds())
+	default:
+		return fmt.Sprintf("%v", typed)
+	}
+}
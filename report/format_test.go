@@ -0,0 +1,101 @@
+package report
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsesTheReportFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+	}{
+		{name: "text", format: TextFormat},
+		{name: "json", format: JsonFormat},
+		{name: "csv", format: CsvFormat},
+	}
+	for _, test := range tests {
+		format, err := ParseFormat(test.name)
+		assert.Nil(t, err)
+		assert.Equal(t, test.format, format)
+		assert.Equal(t, test.name, format.String())
+	}
+}
+
+func TestParsesAnUnsupportedReportFormat(t *testing.T) {
+	_, err := ParseFormat("xml")
+	assert.Error(t, err)
+}
+
+func TestWritesTheReportAsJson(t *testing.T) {
+	startTime := time.Date(2023, 8, 21, 4, 14, 0, 0, time.UTC)
+	report := &Report{
+		Load: LoadMetrics{
+			TotalRequests:                  1000,
+			SuccessCount:                   999,
+			ErrorCount:                     1,
+			ErrorCountByType:               map[string]uint{"load error": 1},
+			EarliestSuccessfulLoadSendTime: startTime,
+			TotalTime:                      10 * time.Second,
+		},
+		Response: ResponseMetrics{
+			TotalResponses:          1000,
+			ErrorCountByType:        map[string]uint{"response error": 1},
+			IsAvailableForReporting: true,
+			Latency:                 LatencyMetrics{TotalSamples: 1, P99: 5 * time.Millisecond},
+		},
+	}
+
+	buffer := &bytes.Buffer{}
+	err := writeInFormat(buffer, report, JsonFormat)
+	assert.Nil(t, err)
+
+	decoded := &Report{}
+	err = json.Unmarshal(buffer.Bytes(), decoded)
+	assert.Nil(t, err)
+
+	assert.Equal(t, uint(1000), decoded.Load.TotalRequests)
+	assert.Equal(t, map[string]uint{"load error": 1}, decoded.Load.ErrorCountByType)
+	assert.Equal(t, map[string]uint{"response error": 1}, decoded.Response.ErrorCountByType)
+	assert.Equal(t, 10*time.Second, decoded.Load.TotalTime)
+	assert.Equal(t, 5*time.Millisecond, decoded.Response.Latency.P99)
+	assert.True(t, startTime.Equal(decoded.Load.EarliestSuccessfulLoadSendTime))
+}
+
+func TestWritesTheReportAsCsv(t *testing.T) {
+	startTime := time.Date(2023, 8, 21, 4, 14, 0, 0, time.UTC)
+	report := &Report{
+		Load: LoadMetrics{
+			TotalRequests:                  1000,
+			ErrorCountByType:               map[string]uint{"load error": 1, "another load error": 2},
+			EarliestSuccessfulLoadSendTime: startTime,
+			TotalTime:                      10 * time.Second,
+		},
+		Response: ResponseMetrics{
+			ErrorCountByType: map[string]uint{"response error": 3},
+			Latency:          LatencyMetrics{P99: 5 * time.Millisecond},
+		},
+	}
+
+	buffer := &bytes.Buffer{}
+	err := writeInFormat(buffer, report, CsvFormat)
+	assert.Nil(t, err)
+
+	rows, err := csv.NewReader(buffer).ReadAll()
+	assert.Nil(t, err)
+
+	assert.Equal(t, []string{"section", "metric", "value"}, rows[0])
+	assert.Contains(t, rows, []string{"Load", "TotalRequests", "1000"})
+	assert.Contains(t, rows, []string{"Load", "EarliestSuccessfulLoadSendTime", "2023-08-21T04:14:00Z"})
+	assert.Contains(t, rows, []string{"Load", "LatestSuccessfulLoadSendTime", ""})
+	assert.Contains(t, rows, []string{"Load", "TotalTime", "10000000000"})
+	assert.Contains(t, rows, []string{"Load.ErrorCountByType", "another load error", "2"})
+	assert.Contains(t, rows, []string{"Load.ErrorCountByType", "load error", "1"})
+	assert.Contains(t, rows, []string{"Response.ErrorCountByType", "response error", "3"})
+	assert.Contains(t, rows, []string{"Response.Latency", "P99", "5000000"})
+}
@@ -0,0 +1,147 @@
+package report
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+// LatencyMetrics represents the distribution of the time taken by the requests to get their responses.
+// Latency is only measured for the responses that could be correlated with their requests.
+type LatencyMetrics struct {
+	TotalSamples uint
+	P50          time.Duration
+	P90          time.Duration
+	P95          time.Duration
+	P99          time.Duration
+	P999         time.Duration
+	Max          time.Duration
+}
+
+const (
+	// latencySubBucketBits is the number of bits of precision of a latency in the latencyRecorder, the relative
+	// error of a percentile is at most 1/2^latencySubBucketBits (< 1%).
+	latencySubBucketBits = 7
+	// latencySubBucketCount is the number of buckets of the latencies smaller than latencySubBucketCount
+	// nanoseconds, each of these buckets is a single nanosecond.
+	latencySubBucketCount = 1 << latencySubBucketBits
+	// latencyHalfSubBucketCount is the number of buckets of each power of two above latencySubBucketCount.
+	latencyHalfSubBucketCount = latencySubBucketCount / 2
+	// latencyMaxBits is the number of bits of the largest latency that is bucketed (about 73 minutes), a larger
+	// latency is counted in the last bucket, whose percentile is Max.
+	latencyMaxBits = 42
+	// latencyBucketCount is the number of buckets of a latencyRecorder.
+	latencyBucketCount = latencySubBucketCount + (latencyMaxBits-latencySubBucketBits)*latencyHalfSubBucketCount
+)
+
+// latencyRecorder records the latencies in a fixed-size histogram (HDR-style, log-linear buckets) and computes
+// LatencyMetrics.
+// The latencies below latencySubBucketCount nanoseconds are exact, and each power of two above is split into
+// latencyHalfSubBucketCount buckets, so the memory of a latencyRecorder does not grow with the number of
+// samples (about 19KB). A percentile is the middle latency of its bucket (bounded by Max), so it is within 1%
+// of the actual latency.
+// The buckets are allocated on the first sample, and the latencyRecorders of the windows (for example, the
+// TimeSeries) can be merged.
+// latencyRecorder is not thread-safe, it is expected to be used by a single reporter goroutine.
+type latencyRecorder struct {
+	counts       []uint64
+	totalSamples uint64
+	max          time.Duration
+}
+
+// newLatencyRecorder creates a new instance of latencyRecorder.
+func newLatencyRecorder() *latencyRecorder {
+	return &latencyRecorder{}
+}
+
+// record records the latency.
+func (recorder *latencyRecorder) record(latency time.Duration) {
+	if latency < 0 {
+		latency = 0
+	}
+	if recorder.counts == nil {
+		recorder.counts = make([]uint64, latencyBucketCount)
+	}
+	recorder.counts[latencyBucketOf(latency)]++
+	recorder.totalSamples++
+	if latency > recorder.max {
+		recorder.max = latency
+	}
+}
+
+// merge adds the latencies recorded by the other latencyRecorder.
+func (recorder *latencyRecorder) merge(other *latencyRecorder) {
+	if other.totalSamples == 0 {
+		return
+	}
+	if recorder.counts == nil {
+		recorder.counts = make([]uint64, latencyBucketCount)
+	}
+	for bucket, count := range other.counts {
+		recorder.counts[bucket] += count
+	}
+	recorder.totalSamples += other.totalSamples
+	if other.max > recorder.max {
+		recorder.max = other.max
+	}
+}
+
+// metrics computes the LatencyMetrics from the recorded latencies.
+func (recorder *latencyRecorder) metrics() LatencyMetrics {
+	if recorder.totalSamples == 0 {
+		return LatencyMetrics{}
+	}
+	return LatencyMetrics{
+		TotalSamples: uint(recorder.totalSamples),
+		P50:          recorder.percentile(50),
+		P90:          recorder.percentile(90),
+		P95:          recorder.percentile(95),
+		P99:          recorder.percentile(99),
+		P999:         recorder.percentile(99.9),
+		Max:          recorder.max,
+	}
+}
+
+// percentile returns the latency at the given percentile using the nearest-rank method.
+// A small epsilon is subtracted before rounding up, to avoid floating point errors (99.9% of 1000 must be
+// the rank 999, and not 1000).
+func (recorder *latencyRecorder) percentile(percentile float64) time.Duration {
+	rank := uint64(math.Ceil(percentile*float64(recorder.totalSamples)/100 - 1e-9))
+	if rank < 1 {
+		rank = 1
+	}
+	var samples uint64
+	for bucket, count := range recorder.counts {
+		samples += count
+		if samples >= rank {
+			if latency := middleLatencyOf(bucket); latency < recorder.max && bucket < latencyBucketCount-1 {
+				return latency
+			}
+			return recorder.max
+		}
+	}
+	return recorder.max
+}
+
+// latencyBucketOf returns the bucket of the (non-negative) latency.
+func latencyBucketOf(latency time.Duration) int {
+	value := uint64(latency)
+	if value >= 1<<latencyMaxBits {
+		value = 1<<latencyMaxBits - 1
+	}
+	if value < latencySubBucketCount {
+		return int(value)
+	}
+	shift := bits.Len64(value) - latencySubBucketBits
+	return latencySubBucketCount + (shift-1)*latencyHalfSubBucketCount + int(value>>shift) - latencyHalfSubBucketCount
+}
+
+// middleLatencyOf returns the latency in the middle of the bucket.
+func middleLatencyOf(bucket int) time.Duration {
+	if bucket < latencySubBucketCount {
+		return time.Duration(bucket)
+	}
+	shift := (bucket-latencySubBucketCount)/latencyHalfSubBucketCount + 1
+	subBucket := (bucket-latencySubBucketCount)%latencyHalfSubBucketCount + latencyHalfSubBucketCount
+	return time.Duration(uint64(subBucket)<<shift + 1<<(shift-1))
+}
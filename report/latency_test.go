@@ -0,0 +1,81 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatencyMetricsWithoutSamples(t *testing.T) {
+	recorder := newLatencyRecorder()
+	assert.Equal(t, LatencyMetrics{}, recorder.metrics())
+}
+
+func TestLatencyMetricsWithASingleSample(t *testing.T) {
+	recorder := newLatencyRecorder()
+	recorder.record(5 * time.Millisecond)
+
+	metrics := recorder.metrics()
+	assert.Equal(t, uint(1), metrics.TotalSamples)
+	assert.Equal(t, 5*time.Millisecond, metrics.P50)
+	assert.Equal(t, 5*time.Millisecond, metrics.P999)
+	assert.Equal(t, 5*time.Millisecond, metrics.Max)
+}
+
+func TestLatencyMetricsWithSamplesRecordedOutOfOrder(t *testing.T) {
+	recorder := newLatencyRecorder()
+	for latency := 1000; latency >= 1; latency-- {
+		recorder.record(time.Duration(latency) * time.Millisecond)
+	}
+
+	metrics := recorder.metrics()
+	assert.Equal(t, uint(1000), metrics.TotalSamples)
+	assert.InEpsilon(t, 500*time.Millisecond, metrics.P50, 0.01)
+	assert.InEpsilon(t, 900*time.Millisecond, metrics.P90, 0.01)
+	assert.InEpsilon(t, 950*time.Millisecond, metrics.P95, 0.01)
+	assert.InEpsilon(t, 990*time.Millisecond, metrics.P99, 0.01)
+	assert.InEpsilon(t, 999*time.Millisecond, metrics.P999, 0.01)
+	assert.Equal(t, 1000*time.Millisecond, metrics.Max)
+}
+
+func TestLatencyMetricsWithMergedRecorders(t *testing.T) {
+	recorder := newLatencyRecorder()
+	for latency := 1; latency <= 500; latency++ {
+		recorder.record(time.Duration(latency) * time.Millisecond)
+	}
+	other := newLatencyRecorder()
+	for latency := 501; latency <= 1000; latency++ {
+		other.record(time.Duration(latency) * time.Millisecond)
+	}
+	recorder.merge(other)
+	recorder.merge(newLatencyRecorder())
+
+	metrics := recorder.metrics()
+	assert.Equal(t, uint(1000), metrics.TotalSamples)
+	assert.InEpsilon(t, 500*time.Millisecond, metrics.P50, 0.01)
+	assert.InEpsilon(t, 990*time.Millisecond, metrics.P99, 0.01)
+	assert.Equal(t, 1000*time.Millisecond, metrics.Max)
+}
+
+func TestLatencyMetricsWithSmallAndLargeLatencies(t *testing.T) {
+	recorder := newLatencyRecorder()
+	recorder.record(-time.Nanosecond)
+	recorder.record(100 * time.Nanosecond)
+	recorder.record(2 * time.Hour)
+
+	metrics := recorder.metrics()
+	assert.Equal(t, 100*time.Nanosecond, metrics.P50)
+	assert.Equal(t, 2*time.Hour, metrics.Max)
+	assert.Equal(t, 2*time.Hour, metrics.P999)
+}
+
+func TestLatencyBucketsAreContiguous(t *testing.T) {
+	previousBucket := latencyBucketOf(0)
+	for latency := time.Duration(1); latency < 1<<20; latency++ {
+		bucket := latencyBucketOf(latency)
+		assert.True(t, bucket == previousBucket || bucket == previousBucket+1)
+		previousBucket = bucket
+	}
+	assert.Equal(t, latencyBucketCount-1, latencyBucketOf(time.Duration(1<<latencyMaxBits)))
+}
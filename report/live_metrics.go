@@ -0,0 +1,214 @@
+package report
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	// errorClassTimeout is the class of the errors of the deadlines and the timeouts.
+	errorClassTimeout = "timeout"
+	// errorClassEOF is the class of the errors of the connections closed by the server.
+	errorClassEOF = "EOF"
+	// errorClassConnectionReset is the class of the errors of the connections reset by the server.
+	errorClassConnectionReset = "connection reset"
+	// errorClassOther is the class of the errors that are not in any other class.
+	errorClassOther = "other"
+)
+
+// classifiedSentinelErrors are the sentinel errors of the responses that are their own class.
+var classifiedSentinelErrors = []error{
+	ErrResponseTimeout,
+	ErrResponseTooShortForRequestId,
+	ErrResponseFrameTooLarge,
+	ErrResponsePrefixMismatch,
+	ErrResponseRegexMismatch,
+	ErrResponseTooShortForStatusByte,
+	ErrUnexpectedStatusByte,
+}
+
+// latencyBucketsSeconds are the upper bounds (in seconds) of the buckets of the latency histogram of LiveMetrics.
+var latencyBucketsSeconds = []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// LiveMetrics represents the metrics of a run while it is in progress, to be scraped in the Prometheus text
+// format. LiveMetrics is fed by the Reporter from the same LoadGenerationResponse and SubjectServerResponse that
+// populate the Report (see ReporterOptions.LiveMetrics).
+// LiveMetrics is thread-safe, it is updated by the goroutines of the Reporter and read by the scrapes.
+type LiveMetrics struct {
+	lock                sync.Mutex
+	requestsSent        uint64
+	requestsDropped     uint64
+	bytesWritten        int64
+	writeErrorsByType   map[string]uint64
+	responsesRead       uint64
+	bytesRead           int64
+	readErrorsByType    map[string]uint64
+	latencyBuckets      []uint64
+	latencySumSeconds   float64
+	latencyCount        uint64
+	activeConnectionsFn func() uint
+}
+
+// NewLiveMetrics creates a new instance of LiveMetrics.
+func NewLiveMetrics() *LiveMetrics {
+	return &LiveMetrics{
+		writeErrorsByType: make(map[string]uint64),
+		readErrorsByType:  make(map[string]uint64),
+		latencyBuckets:    make([]uint64, len(latencyBucketsSeconds)),
+	}
+}
+
+// SetActiveConnections sets the function that returns the number of the active connections, it is invoked on
+// each scrape.
+func (metrics *LiveMetrics) SetActiveConnections(activeConnections func() uint) {
+	metrics.lock.Lock()
+	defer metrics.lock.Unlock()
+
+	metrics.activeConnectionsFn = activeConnections
+}
+
+// addLoad adds the LoadGenerationResponse to the LiveMetrics, it does nothing if the LiveMetrics is nil.
+func (metrics *LiveMetrics) addLoad(load LoadGenerationResponse) {
+	if metrics == nil {
+		return
+	}
+	metrics.lock.Lock()
+	defer metrics.lock.Unlock()
+
+	if load.Dropped {
+		metrics.requestsDropped++
+		return
+	}
+	metrics.requestsSent++
+	if load.Err != nil {
+		metrics.writeErrorsByType[errorClassOf(load.Err)]++
+		return
+	}
+	metrics.bytesWritten += load.PayloadLengthBytes
+}
+
+// addResponse adds the SubjectServerResponse to the LiveMetrics, it does nothing if the LiveMetrics is nil.
+func (metrics *LiveMetrics) addResponse(response SubjectServerResponse) {
+	if metrics == nil {
+		return
+	}
+	metrics.lock.Lock()
+	defer metrics.lock.Unlock()
+
+	metrics.responsesRead++
+	if response.Err != nil {
+		metrics.readErrorsByType[errorClassOf(response.Err)]++
+	} else {
+		metrics.bytesRead += response.PayloadLengthBytes
+	}
+	if response.LatencyMeasured {
+		metrics.observeLatency(response.Latency)
+	}
+}
+
+// observeLatency adds the latency to the histogram, it expects the lock to be held.
+func (metrics *LiveMetrics) observeLatency(latency time.Duration) {
+	seconds := latency.Seconds()
+	for index, upperBound := range latencyBucketsSeconds {
+		if seconds <= upperBound {
+			metrics.latencyBuckets[index]++
+		}
+	}
+	metrics.latencySumSeconds += seconds
+	metrics.latencyCount++
+}
+
+// WritePrometheus writes the LiveMetrics on the provided io.Writer in the Prometheus text exposition format.
+func (metrics *LiveMetrics) WritePrometheus(writer io.Writer) error {
+	metrics.lock.Lock()
+	builder := &strings.Builder{}
+
+	writeMetric := func(name, kind, help string, value string) {
+		_, _ = fmt.Fprintf(builder, "# HELP %v %v\n# TYPE %v %v\n%v %v\n", name, help, name, kind, name, value)
+	}
+	writeErrors := func(name, help string, errorsByType map[string]uint64) {
+		_, _ = fmt.Fprintf(builder, "# HELP %v %v\n# TYPE %v counter\n", name, help, name)
+		errorTypes := make([]string, 0, len(errorsByType))
+		for errorType := range errorsByType {
+			errorTypes = append(errorTypes, errorType)
+		}
+		sort.Strings(errorTypes)
+		for _, errorType := range errorTypes {
+			_, _ = fmt.Fprintf(builder, "%v{type=\"%v\"} %d\n", name, escapeLabelValue(errorType), errorsByType[errorType])
+		}
+	}
+
+	writeMetric("blast_requests_sent_total", "counter", "Total requests sent, including the failed requests.", formatUint(metrics.requestsSent))
+	writeMetric("blast_requests_dropped_total", "counter", "Total scheduled requests that were dropped.", formatUint(metrics.requestsDropped))
+	writeMetric("blast_bytes_written_total", "counter", "Total payload bytes written.", strconv.FormatInt(metrics.bytesWritten, 10))
+	writeErrors("blast_write_errors_total", "Total errors in writing the requests by class.", metrics.writeErrorsByType)
+	writeMetric("blast_responses_read_total", "counter", "Total responses read, including the failed responses.", formatUint(metrics.responsesRead))
+	writeMetric("blast_bytes_read_total", "counter", "Total response payload bytes read.", strconv.FormatInt(metrics.bytesRead, 10))
+	writeErrors("blast_read_errors_total", "Total errors in reading the responses by class.", metrics.readErrorsByType)
+
+	activeConnections := uint(0)
+	if metrics.activeConnectionsFn != nil {
+		activeConnections = metrics.activeConnectionsFn()
+	}
+	writeMetric("blast_active_connections", "gauge", "Connections that are alive.", formatUint(uint64(activeConnections)))
+
+	name := "blast_response_latency_seconds"
+	_, _ = fmt.Fprintf(builder, "# HELP %v Latency of the responses correlated with their requests.\n# TYPE %v histogram\n", name, name)
+	for index, upperBound := range latencyBucketsSeconds {
+		_, _ = fmt.Fprintf(builder, "%v_bucket{le=\"%v\"} %d\n", name, strconv.FormatFloat(upperBound, 'g', -1, 64), metrics.latencyBuckets[index])
+	}
+	_, _ = fmt.Fprintf(builder, "%v_bucket{le=\"+Inf\"} %d\n", name, metrics.latencyCount)
+	_, _ = fmt.Fprintf(builder, "%v_sum %v\n", name, strconv.FormatFloat(metrics.latencySumSeconds, 'g', -1, 64))
+	_, _ = fmt.Fprintf(builder, "%v_count %d\n", name, metrics.latencyCount)
+	metrics.lock.Unlock()
+
+	_, err := io.WriteString(writer, builder.String())
+	return err
+}
+
+// errorClassOf returns the class of the error, that is the label of the error in the LiveMetrics.
+// The message of an error may contain the addresses, the ports or the bytes of a response, so labelling by the
+// message would create a time series for each distinct message. The classes are a small fixed set instead:
+// timeout, EOF, connection reset, the sentinel error of a response (for example, ErrUnexpectedStatusByte without
+// the status byte), the message of the syscall errno (for example, "connection refused") or other.
+func errorClassOf(err error) string {
+	var netError net.Error
+	if errors.Is(err, os.ErrDeadlineExceeded) || (errors.As(err, &netError) && netError.Timeout()) {
+		return errorClassTimeout
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return errorClassEOF
+	}
+	if errors.Is(err, syscall.ECONNRESET) {
+		return errorClassConnectionReset
+	}
+	for _, sentinel := range classifiedSentinelErrors {
+		if errors.Is(err, sentinel) {
+			return sentinel.Error()
+		}
+	}
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		return errno.Error()
+	}
+	return errorClassOther
+}
+
+// formatUint formats the unsigned value in base 10.
+func formatUint(value uint64) string {
+	return strconv.FormatUint(value, 10)
+}
+
+// escapeLabelValue escapes the backslash, the double-quote and the line feed in the label value.
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
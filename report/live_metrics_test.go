@@ -0,0 +1,115 @@
+package report
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLiveMetricsWithLoad(t *testing.T) {
+	metrics := NewLiveMetrics()
+	metrics.addLoad(LoadGenerationResponse{PayloadLengthBytes: 10})
+	metrics.addLoad(LoadGenerationResponse{PayloadLengthBytes: 10})
+	metrics.addLoad(LoadGenerationResponse{Err: &net.OpError{Op: "write", Net: "tcp", Err: syscall.ECONNRESET}, PayloadLengthBytes: 10})
+	metrics.addLoad(LoadGenerationResponse{Dropped: true})
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, metrics.WritePrometheus(buffer))
+
+	output := buffer.String()
+	assert.Contains(t, output, "# TYPE blast_requests_sent_total counter\nblast_requests_sent_total 3\n")
+	assert.Contains(t, output, "blast_requests_dropped_total 1\n")
+	assert.Contains(t, output, "blast_bytes_written_total 20\n")
+	assert.Contains(t, output, "blast_write_errors_total{type=\"connection reset\"} 1\n")
+}
+
+func TestLiveMetricsWithResponses(t *testing.T) {
+	metrics := NewLiveMetrics()
+	metrics.addResponse(SubjectServerResponse{PayloadLengthBytes: 5, LatencyMeasured: true, Latency: 2 * time.Millisecond})
+	metrics.addResponse(SubjectServerResponse{PayloadLengthBytes: 5, LatencyMeasured: true, Latency: 20 * time.Millisecond})
+	metrics.addResponse(SubjectServerResponse{Err: os.ErrDeadlineExceeded})
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, metrics.WritePrometheus(buffer))
+
+	output := buffer.String()
+	assert.Contains(t, output, "blast_responses_read_total 3\n")
+	assert.Contains(t, output, "blast_bytes_read_total 10\n")
+	assert.Contains(t, output, "blast_read_errors_total{type=\"timeout\"} 1\n")
+	assert.Contains(t, output, "# TYPE blast_response_latency_seconds histogram\n")
+	assert.Contains(t, output, "blast_response_latency_seconds_bucket{le=\"0.001\"} 0\n")
+	assert.Contains(t, output, "blast_response_latency_seconds_bucket{le=\"0.0025\"} 1\n")
+	assert.Contains(t, output, "blast_response_latency_seconds_bucket{le=\"0.025\"} 2\n")
+	assert.Contains(t, output, "blast_response_latency_seconds_bucket{le=\"+Inf\"} 2\n")
+	assert.Contains(t, output, "blast_response_latency_seconds_sum 0.022\n")
+	assert.Contains(t, output, "blast_response_latency_seconds_count 2\n")
+}
+
+func TestLiveMetricsWithActiveConnections(t *testing.T) {
+	metrics := NewLiveMetrics()
+	metrics.SetActiveConnections(func() uint {
+		return 4
+	})
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, metrics.WritePrometheus(buffer))
+	assert.Contains(t, buffer.String(), "# TYPE blast_active_connections gauge\nblast_active_connections 4\n")
+}
+
+func TestLiveMetricsEscapesTheLabelValue(t *testing.T) {
+	assert.Equal(t, `dial \"localhost\"\nrefused \\`, escapeLabelValue("dial \"localhost\"\nrefused \\"))
+}
+
+func TestLiveMetricsLabelsTheErrorsByClass(t *testing.T) {
+	metrics := NewLiveMetrics()
+	metrics.addLoad(LoadGenerationResponse{Err: &net.OpError{Op: "write", Net: "tcp", Err: os.NewSyscallError("write", syscall.EPIPE)}})
+	metrics.addLoad(LoadGenerationResponse{Err: &net.OpError{Op: "write", Net: "tcp", Err: os.NewSyscallError("write", syscall.EPIPE)}})
+	metrics.addLoad(LoadGenerationResponse{Err: errors.New("dial localhost:8080 failed")})
+	metrics.addLoad(LoadGenerationResponse{Err: errors.New("dial localhost:8081 failed")})
+	metrics.addResponse(SubjectServerResponse{Err: io.EOF})
+	metrics.addResponse(SubjectServerResponse{Err: fmt.Errorf("%w: 0x%02x", ErrUnexpectedStatusByte, 0x01)})
+	metrics.addResponse(SubjectServerResponse{Err: fmt.Errorf("%w: 0x%02x", ErrUnexpectedStatusByte, 0x02)})
+	metrics.addResponse(SubjectServerResponse{Err: ErrResponseTimeout})
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, metrics.WritePrometheus(buffer))
+
+	output := buffer.String()
+	assert.Contains(t, output, "blast_write_errors_total{type=\"broken pipe\"} 2\n")
+	assert.Contains(t, output, "blast_write_errors_total{type=\"other\"} 2\n")
+	assert.Contains(t, output, "blast_read_errors_total{type=\"EOF\"} 1\n")
+	assert.Contains(t, output, "blast_read_errors_total{type=\"unexpected status byte\"} 2\n")
+	assert.Contains(t, output, "blast_read_errors_total{type=\"response is not read within the in-flight timeout\"} 1\n")
+}
+
+func TestReporterFeedsTheLiveMetrics(t *testing.T) {
+	liveMetrics := NewLiveMetrics()
+	loadGenerationChannel := make(chan LoadGenerationResponse)
+	responseChannel := make(chan SubjectServerResponse)
+
+	reporter := NewResponseMetricsCollectingReporterWithOptions(
+		loadGenerationChannel,
+		responseChannel,
+		ReporterOptions{LiveMetrics: liveMetrics},
+	)
+	reporter.Run()
+
+	loadGenerationChannel <- LoadGenerationResponse{PayloadLengthBytes: 10, LoadGenerationTime: time.Now()}
+	responseChannel <- SubjectServerResponse{PayloadLengthBytes: 10, ResponseTime: time.Now()}
+	close(loadGenerationChannel)
+	close(responseChannel)
+	reporter.Report()
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, liveMetrics.WritePrometheus(buffer))
+	assert.Contains(t, buffer.String(), "blast_requests_sent_total 1\n")
+	assert.Contains(t, buffer.String(), "blast_responses_read_total 1\n")
+}
@@ -2,6 +2,7 @@ package report
 
 import (
 	"io"
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -11,15 +12,30 @@ import (
 // LoadMetrics defines fields that are relevant to the generated load, whereas
 // ResponseMetrics defines the fields that are relevant to the response read by blast.
 // ResponseMetrics is only captured if NewResponseMetricsCollectingReporter method is called.
+// TimeSeries contains the metrics of the run bucketed in windows of ReporterOptions.TimeSeriesWindow.
+// Stages contains the metrics of each of the ReporterOptions.Stages, it is empty if no stages are configured.
+// Targets contains the metrics of each target server, it is empty if the load is sent to a single target server.
 type Report struct {
-	Load     LoadMetrics
-	Response ResponseMetrics
+	Load       LoadMetrics
+	Response   ResponseMetrics
+	TimeSeries TimeSeries
+	Stages     []StageMetrics
+	Targets    []TargetMetrics
 }
 
+// LoadMetrics defines the metrics of the generated load.
+// DroppedCount and LateCount are only relevant for the open load model: DroppedCount is the number of
+// scheduled requests that could not be sent (these are not counted in TotalRequests), and LateCount is
+// the number of requests that were sent later than their scheduled time.
+// TotalReconnects and Connections are only relevant if the connections are re-established when they fail:
+// Connections contains the metrics of each connection that was reconnected or was down during the run.
+// Dial contains the metrics of the dials of the connections.
 type LoadMetrics struct {
 	TotalRequests                  uint
 	SuccessCount                   uint
 	ErrorCount                     uint
+	DroppedCount                   uint
+	LateCount                      uint
 	ErrorCountByType               map[string]uint
 	TotalConnections               uint
 	TotalPayloadLengthBytes        int64
@@ -27,9 +43,25 @@ type LoadMetrics struct {
 	EarliestSuccessfulLoadSendTime time.Time
 	LatestSuccessfulLoadSendTime   time.Time
 	TotalTime                      time.Duration
+	TotalReconnects                uint
+	Connections                    []ConnectionMetrics
+	Dial                           DialMetrics
 	uniqueConnectionIds            map[int]bool
 }
 
+// ConnectionMetrics defines the metrics of a single connection.
+// Reconnects is the number of times the connection was re-established, and Downtime is the total time for which
+// the connection was down (including the time before it was established for the first time, if it failed at
+// dial time).
+type ConnectionMetrics struct {
+	ConnectionId int
+	Reconnects   uint
+	Downtime     time.Duration
+}
+
+// ResponseMetrics defines the metrics of the responses read by blast.
+// Latency is measured from the actual send time of the requests, whereas CorrectedLatency is measured
+// from the intended send time of the requests, and is not hidden by the stalls in sending the requests.
 type ResponseMetrics struct {
 	TotalResponses                         uint
 	SuccessCount                           uint
@@ -41,6 +73,8 @@ type ResponseMetrics struct {
 	LatestSuccessfulResponseReceivedTime   time.Time
 	IsAvailableForReporting                bool
 	TotalTime                              time.Duration
+	Latency                                LatencyMetrics
+	CorrectedLatency                       LatencyMetrics
 }
 
 // Reporter generates the report.
@@ -50,16 +84,46 @@ type ResponseMetrics struct {
 type Reporter struct {
 	report                     *Report
 	totalLoadReportedTillNow   atomic.Uint64
+	totalLoadErrorsTillNow     atomic.Uint64
 	loadGenerationChannel      chan LoadGenerationResponse
 	responseChannel            chan SubjectServerResponse
 	loadMetricsDoneChannel     chan struct{}
 	responseMetricsDoneChannel chan struct{}
+	timeSeries                 *timeSeriesCollector
+	stages                     *stageCollector
+	startTime                  time.Time
+	targets                    *targetCollector
+	liveMetrics                *LiveMetrics
+	completeReport             sync.Once
+}
+
+// ReporterOptions defines the configuration options for the Reporter.
+// TimeSeriesWindow is the size of each window in the TimeSeries, DefaultTimeSeriesWindow is used if it is zero.
+// Stages are the stages of the run whose metrics are reported separately, they are optional.
+// LiveMetrics (optional) is fed with the same responses as the Report, while the run is in progress.
+// StartTime (optional) is the start time of the run, which is the start of the TimeSeries and the stages. It is
+// the start time of the load (for example, workers.WorkerGroup.StartTime), so that the stages line up with the
+// schedule of the requests. The time of running the Reporter is used if it is zero.
+type ReporterOptions struct {
+	TimeSeriesWindow time.Duration
+	Stages           []ReportStage
+	LiveMetrics      *LiveMetrics
+	StartTime        time.Time
 }
 
 // NewLoadGenerationMetricsCollectingReporter creates a new Reporter that only populates
 // the LoadMetrics.
 func NewLoadGenerationMetricsCollectingReporter(
 	loadGenerationChannel chan LoadGenerationResponse,
+) *Reporter {
+	return NewLoadGenerationMetricsCollectingReporterWithOptions(loadGenerationChannel, ReporterOptions{})
+}
+
+// NewLoadGenerationMetricsCollectingReporterWithOptions creates a new Reporter that only populates
+// the LoadMetrics, and is configured with the given ReporterOptions.
+func NewLoadGenerationMetricsCollectingReporterWithOptions(
+	loadGenerationChannel chan LoadGenerationResponse,
+	options ReporterOptions,
 ) *Reporter {
 	return &Reporter{
 		report: &Report{
@@ -75,6 +139,11 @@ func NewLoadGenerationMetricsCollectingReporter(
 		responseChannel:            nil,
 		loadMetricsDoneChannel:     make(chan struct{}),
 		responseMetricsDoneChannel: nil,
+		timeSeries:                 newTimeSeriesCollector(options.TimeSeriesWindow),
+		stages:                     newStageCollector(options.Stages),
+		startTime:                  options.StartTime,
+		targets:                    newTargetCollector(),
+		liveMetrics:                options.LiveMetrics,
 	}
 }
 
@@ -83,6 +152,16 @@ func NewLoadGenerationMetricsCollectingReporter(
 func NewResponseMetricsCollectingReporter(
 	loadGenerationChannel chan LoadGenerationResponse,
 	responseChannel chan SubjectServerResponse,
+) *Reporter {
+	return NewResponseMetricsCollectingReporterWithOptions(loadGenerationChannel, responseChannel, ReporterOptions{})
+}
+
+// NewResponseMetricsCollectingReporterWithOptions creates a new Reporter that populates both the
+// LoadMetrics and ResponseMetrics, and is configured with the given ReporterOptions.
+func NewResponseMetricsCollectingReporterWithOptions(
+	loadGenerationChannel chan LoadGenerationResponse,
+	responseChannel chan SubjectServerResponse,
+	options ReporterOptions,
 ) *Reporter {
 	return &Reporter{
 		report: &Report{
@@ -99,28 +178,87 @@ func NewResponseMetricsCollectingReporter(
 		responseChannel:            responseChannel,
 		loadMetricsDoneChannel:     make(chan struct{}),
 		responseMetricsDoneChannel: make(chan struct{}),
+		timeSeries:                 newTimeSeriesCollector(options.TimeSeriesWindow),
+		stages:                     newStageCollector(options.Stages),
+		startTime:                  options.StartTime,
+		targets:                    newTargetCollector(),
+		liveMetrics:                options.LiveMetrics,
 	}
 }
 
 // Run runs the Reporter goroutines.
+// ReporterOptions.StartTime (or the time of running the Reporter, if it is zero) is considered as the start time
+// of the TimeSeries and the stages.
 func (reporter *Reporter) Run() {
+	startTime := reporter.startTime
+	if startTime.IsZero() {
+		startTime = time.Now()
+	}
+	reporter.timeSeries.start(startTime)
+	reporter.stages.start(startTime)
 	reporter.collectLoadMetrics()
 	if reporter.responseChannel != nil {
 		reporter.collectResponseMetrics()
 	}
 }
 
-// PrintReport prints the report on the provided io.Writer.
+// PrintReport prints the report on the provided io.Writer in TextFormat.
 // Before the report is ready to be printed, PrintReport waits for
 // the goroutines to finish.
 // This method can only be called after the loadGenerationChannel and responseChannel
 // are closed.
 func (reporter *Reporter) PrintReport(writer io.Writer) {
+	_ = reporter.WriteReport(writer, TextFormat)
+}
+
+// WriteReport writes the report on the provided io.Writer in the given Format.
+// Like PrintReport, WriteReport waits for the goroutines to finish and can only be called after
+// the loadGenerationChannel and responseChannel are closed.
+func (reporter *Reporter) WriteReport(writer io.Writer, format Format) error {
+	reporter.waitForCompletion()
+	return writeInFormat(writer, reporter.report, format)
+}
+
+// Report returns the Report.
+// Like PrintReport, Report waits for the goroutines to finish and can only be called after
+// the loadGenerationChannel and responseChannel are closed.
+func (reporter *Reporter) Report() Report {
+	reporter.waitForCompletion()
+	return *reporter.report
+}
+
+// waitForCompletion waits for the goroutines to finish, and completes the report with the metrics
+// that are collected by both the goroutines (for example: TimeSeries).
+func (reporter *Reporter) waitForCompletion() {
 	<-reporter.loadMetricsDoneChannel
 	if reporter.responseMetricsDoneChannel != nil {
 		<-reporter.responseMetricsDoneChannel
 	}
-	_ = write(writer, reporter.report)
+	reporter.completeReport.Do(func() {
+		reporter.report.TimeSeries = reporter.timeSeries.timeSeries()
+		reporter.report.Stages = reporter.stages.stageMetrics()
+		reporter.report.Targets = reporter.targets.targetMetrics()
+	})
+}
+
+// AddConnectionMetrics adds the metrics of the connections to the LoadMetrics.
+// Like PrintReport, AddConnectionMetrics waits for the goroutines to finish and can only be called after
+// the loadGenerationChannel and responseChannel are closed.
+func (reporter *Reporter) AddConnectionMetrics(connections []ConnectionMetrics) {
+	reporter.waitForCompletion()
+	reporter.report.Load.Connections = connections
+	reporter.report.Load.TotalReconnects = 0
+	for _, connection := range connections {
+		reporter.report.Load.TotalReconnects += connection.Reconnects
+	}
+}
+
+// AddDialMetrics adds the metrics of the dials of the connections to the LoadMetrics.
+// Like PrintReport, AddDialMetrics waits for the goroutines to finish and can only be called after
+// the loadGenerationChannel and responseChannel are closed.
+func (reporter *Reporter) AddDialMetrics(dials DialMetrics) {
+	reporter.waitForCompletion()
+	reporter.report.Load.Dial = dials
 }
 
 // TotalLoadReportedTillNow returns the total load that has reporter so far.
@@ -128,19 +266,36 @@ func (reporter *Reporter) TotalLoadReportedTillNow() uint64 {
 	return reporter.totalLoadReportedTillNow.Load()
 }
 
+// TotalLoadErrorsReportedTillNow returns the total load that has been reported with an error so far.
+func (reporter *Reporter) TotalLoadErrorsReportedTillNow() uint64 {
+	return reporter.totalLoadErrorsTillNow.Load()
+}
+
 // collectLoadMetrics runs a goroutine that reports the LoadMetrics.
 func (reporter *Reporter) collectLoadMetrics() {
 	go func() {
 		totalGeneratedLoad := uint(0)
 		for load := range reporter.loadGenerationChannel {
+			reporter.stages.addLoad(load)
+			reporter.targets.addLoad(load)
+			reporter.liveMetrics.addLoad(load)
+			if load.Dropped {
+				reporter.report.Load.DroppedCount++
+				continue
+			}
 			totalGeneratedLoad++
 			reporter.totalLoadReportedTillNow.Add(1)
+			reporter.timeSeries.addLoad(load)
 
 			if load.ConnectionId != NilConnectionId {
 				reporter.report.Load.uniqueConnectionIds[load.ConnectionId] = true
 			}
+			if load.Late {
+				reporter.report.Load.LateCount++
+			}
 
 			if load.Err != nil {
+				reporter.totalLoadErrorsTillNow.Add(1)
 				reporter.report.Load.ErrorCount++
 				reporter.report.Load.ErrorCountByType[load.Err.Error()]++
 			} else {
@@ -177,11 +332,25 @@ func (reporter *Reporter) collectLoadMetrics() {
 }
 
 // collectResponseMetrics runs a goroutine that reports the ResponseMetrics.
+// The latency of a response that is bucketed in the TimeSeries is only recorded in its window, and the latency
+// of all the responses is the merge of the windows and the responses that are not bucketed.
 func (reporter *Reporter) collectResponseMetrics() {
 	go func() {
 		totalResponses := 0
+		latencies := newLatencyRecorder()
+		correctedLatencies := newLatencyRecorder()
 		for response := range reporter.responseChannel {
 			totalResponses++
+			recordedInTimeSeries := reporter.timeSeries.addResponse(response)
+			reporter.stages.addResponse(response)
+			reporter.targets.addResponse(response)
+			reporter.liveMetrics.addResponse(response)
+			if response.LatencyMeasured {
+				if !recordedInTimeSeries {
+					latencies.record(response.Latency)
+				}
+				correctedLatencies.record(response.CorrectedLatency)
+			}
 
 			if response.Err != nil {
 				reporter.report.Response.ErrorCount++
@@ -218,6 +387,9 @@ func (reporter *Reporter) collectResponseMetrics() {
 		timeToCompleteResponses := reporter.report.Response.LatestSuccessfulResponseReceivedTime.
 			Sub(reporter.report.Response.EarliestSuccessfulResponseReceivedTime)
 		reporter.report.Response.TotalTime = timeToCompleteResponses
+		latencies.merge(reporter.timeSeries.latency())
+		reporter.report.Response.Latency = latencies.metrics()
+		reporter.report.Response.CorrectedLatency = correctedLatencies.metrics()
 
 		close(reporter.responseMetricsDoneChannel)
 	}()
@@ -427,3 +427,217 @@ func TestPrintsTheReportWithLoadAndResponseMetricsTogether(t *testing.T) {
 	assert.True(t, strings.Contains(output, "ErrorCount: 0"))
 	assert.True(t, strings.Contains(output, "TotalResponses: 1"))
 }
+
+func TestReportWithLatencyInReceivingResponse(t *testing.T) {
+	responseChannel := make(chan SubjectServerResponse, 3)
+	reporter := NewResponseMetricsCollectingReporter(nil, responseChannel)
+	reporter.Run()
+
+	responseChannel <- SubjectServerResponse{
+		Latency:         10 * time.Millisecond,
+		LatencyMeasured: true,
+	}
+	responseChannel <- SubjectServerResponse{
+		Latency:         20 * time.Millisecond,
+		LatencyMeasured: true,
+	}
+	responseChannel <- SubjectServerResponse{
+		PayloadLengthBytes: 10,
+	}
+	time.Sleep(2 * time.Millisecond)
+	close(responseChannel)
+
+	time.Sleep(2 * time.Millisecond)
+
+	assert.Equal(t, uint(2), reporter.report.Response.Latency.TotalSamples)
+	assert.InEpsilon(t, 10*time.Millisecond, reporter.report.Response.Latency.P50, 0.01)
+	assert.Equal(t, 20*time.Millisecond, reporter.report.Response.Latency.Max)
+}
+
+func TestReportWithLatencyMergedFromTheTimeSeries(t *testing.T) {
+	responseChannel := make(chan SubjectServerResponse, 3)
+	reporter := NewResponseMetricsCollectingReporter(nil, responseChannel)
+	reporter.Run()
+
+	responseChannel <- SubjectServerResponse{
+		ResponseTime:    time.Now(),
+		Latency:         10 * time.Millisecond,
+		LatencyMeasured: true,
+	}
+	responseChannel <- SubjectServerResponse{
+		ResponseTime:    time.Now().Add(2 * time.Second),
+		Latency:         30 * time.Millisecond,
+		LatencyMeasured: true,
+	}
+	responseChannel <- SubjectServerResponse{
+		Latency:         20 * time.Millisecond,
+		LatencyMeasured: true,
+	}
+	time.Sleep(2 * time.Millisecond)
+	close(responseChannel)
+
+	time.Sleep(2 * time.Millisecond)
+
+	assert.Equal(t, uint(3), reporter.report.Response.Latency.TotalSamples)
+	assert.InEpsilon(t, 20*time.Millisecond, reporter.report.Response.Latency.P50, 0.01)
+	assert.Equal(t, 30*time.Millisecond, reporter.report.Response.Latency.Max)
+}
+
+func TestReportWithCorrectedLatencyInReceivingResponse(t *testing.T) {
+	responseChannel := make(chan SubjectServerResponse, 2)
+	reporter := NewResponseMetricsCollectingReporter(nil, responseChannel)
+	reporter.Run()
+
+	responseChannel <- SubjectServerResponse{
+		Latency:          10 * time.Millisecond,
+		CorrectedLatency: 10 * time.Millisecond,
+		LatencyMeasured:  true,
+	}
+	responseChannel <- SubjectServerResponse{
+		Latency:          20 * time.Millisecond,
+		CorrectedLatency: time.Second,
+		LatencyMeasured:  true,
+	}
+	time.Sleep(2 * time.Millisecond)
+	close(responseChannel)
+
+	time.Sleep(2 * time.Millisecond)
+
+	assert.Equal(t, 20*time.Millisecond, reporter.report.Response.Latency.Max)
+	assert.Equal(t, uint(2), reporter.report.Response.CorrectedLatency.TotalSamples)
+	assert.InEpsilon(t, 10*time.Millisecond, reporter.report.Response.CorrectedLatency.P50, 0.01)
+	assert.Equal(t, time.Second, reporter.report.Response.CorrectedLatency.Max)
+}
+
+func TestWritesTheReportWithLoadMetricsAsJson(t *testing.T) {
+	loadGenerationChannel := make(chan LoadGenerationResponse, 1)
+	reporter := NewLoadGenerationMetricsCollectingReporter(loadGenerationChannel)
+	reporter.Run()
+
+	loadGenerationChannel <- LoadGenerationResponse{
+		Err: errors.New("test error"),
+	}
+	time.Sleep(2 * time.Millisecond)
+	close(loadGenerationChannel)
+
+	buffer := &bytes.Buffer{}
+	err := reporter.WriteReport(buffer, JsonFormat)
+	assert.Nil(t, err)
+
+	output := string(buffer.Bytes())
+	assert.True(t, strings.Contains(output, `"TotalRequests": 1`))
+	assert.True(t, strings.Contains(output, `"test error": 1`))
+}
+
+func TestReportWithTimeSeries(t *testing.T) {
+	loadGenerationChannel := make(chan LoadGenerationResponse, 2)
+	responseChannel := make(chan SubjectServerResponse, 1)
+	reporter := NewResponseMetricsCollectingReporterWithOptions(
+		loadGenerationChannel,
+		responseChannel,
+		ReporterOptions{TimeSeriesWindow: 100 * time.Millisecond},
+	)
+	reporter.Run()
+
+	now := time.Now()
+	loadGenerationChannel <- LoadGenerationResponse{
+		PayloadLengthBytes: 10,
+		LoadGenerationTime: now,
+	}
+	loadGenerationChannel <- LoadGenerationResponse{
+		PayloadLengthBytes: 10,
+		LoadGenerationTime: now.Add(150 * time.Millisecond),
+	}
+	responseChannel <- SubjectServerResponse{
+		PayloadLengthBytes: 10,
+		ResponseTime:       now.Add(150 * time.Millisecond),
+	}
+	close(loadGenerationChannel)
+	close(responseChannel)
+
+	buffer := &bytes.Buffer{}
+	reporter.PrintReport(buffer)
+
+	timeSeries := reporter.report.TimeSeries
+	assert.Equal(t, 100*time.Millisecond, timeSeries.WindowSize)
+	assert.Equal(t, 2, len(timeSeries.Windows))
+	assert.Equal(t, uint(1), timeSeries.Windows[0].RequestsSent)
+	assert.Equal(t, uint(1), timeSeries.Windows[1].RequestsSent)
+	assert.Equal(t, uint(1), timeSeries.Windows[1].Responses)
+	assert.True(t, strings.Contains(string(buffer.Bytes()), "TimeSeries (window: 100ms)"))
+}
+
+func TestReportWithTotalLoadErrorsReportedTillNow(t *testing.T) {
+	loadGenerationChannel := make(chan LoadGenerationResponse, 3)
+	reporter := NewLoadGenerationMetricsCollectingReporter(loadGenerationChannel)
+	reporter.Run()
+
+	loadGenerationChannel <- LoadGenerationResponse{
+		PayloadLengthBytes: 15,
+	}
+	loadGenerationChannel <- LoadGenerationResponse{
+		Err: errors.New("test error"),
+	}
+	loadGenerationChannel <- LoadGenerationResponse{
+		Err: errors.New("test error"),
+	}
+	time.Sleep(2 * time.Millisecond)
+	close(loadGenerationChannel)
+
+	assert.Equal(t, uint64(3), reporter.TotalLoadReportedTillNow())
+	assert.Equal(t, uint64(2), reporter.TotalLoadErrorsReportedTillNow())
+}
+
+func TestReportWithDroppedAndLateRequests(t *testing.T) {
+	loadGenerationChannel := make(chan LoadGenerationResponse, 3)
+	reporter := NewLoadGenerationMetricsCollectingReporter(loadGenerationChannel)
+	reporter.Run()
+
+	loadGenerationChannel <- LoadGenerationResponse{
+		PayloadLengthBytes: 15,
+	}
+	loadGenerationChannel <- LoadGenerationResponse{
+		PayloadLengthBytes: 15,
+		Late:               true,
+	}
+	loadGenerationChannel <- LoadGenerationResponse{
+		Dropped:      true,
+		ConnectionId: NilConnectionId,
+	}
+	time.Sleep(2 * time.Millisecond)
+	close(loadGenerationChannel)
+
+	buffer := &bytes.Buffer{}
+	reporter.PrintReport(buffer)
+
+	assert.Equal(t, uint(2), reporter.report.Load.TotalRequests)
+	assert.Equal(t, uint(2), reporter.report.Load.SuccessCount)
+	assert.Equal(t, uint(1), reporter.report.Load.DroppedCount)
+	assert.Equal(t, uint(1), reporter.report.Load.LateCount)
+
+	output := string(buffer.Bytes())
+	assert.True(t, strings.Contains(output, "DroppedCount: 1"))
+	assert.True(t, strings.Contains(output, "LateCount: 1"))
+}
+
+func TestReportWithConnectionMetrics(t *testing.T) {
+	loadGenerationChannel := make(chan LoadGenerationResponse, 1)
+	reporter := NewLoadGenerationMetricsCollectingReporter(loadGenerationChannel)
+	reporter.Run()
+
+	loadGenerationChannel <- LoadGenerationResponse{
+		PayloadLengthBytes: 15,
+	}
+	close(loadGenerationChannel)
+
+	reporter.AddConnectionMetrics([]ConnectionMetrics{
+		{ConnectionId: 0, Reconnects: 2, Downtime: 10 * time.Millisecond},
+		{ConnectionId: 1, Downtime: 5 * time.Millisecond},
+	})
+	report := reporter.Report()
+
+	assert.Equal(t, uint(1), report.Load.TotalRequests)
+	assert.Equal(t, uint(2), report.Load.TotalReconnects)
+	assert.Equal(t, 2, len(report.Load.Connections))
+	assert.Equal(t, 5*time.Millisecond, report.Load.Connections[1].Downtime)
+}
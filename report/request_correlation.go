@@ -0,0 +1,141 @@
+package report
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrResponseTooShortForRequestId is the error that is returned when the response does not have enough
+// bytes to contain the request id.
+var ErrResponseTooShortForRequestId = errors.New("response is too short to contain the request id")
+
+// ErrResponseTimeout is the error of a request whose response is not read within the in-flight timeout of the
+// ResponseReader, the request is then evicted from InFlightRequests.
+var ErrResponseTimeout = errors.New("response is not read within the in-flight timeout")
+
+// RequestIdExtractor extracts the request id from the response returned by the target server.
+// The request id is the one that was generated by workers.RequestId and embedded in the request payload
+// by the payload.PayloadGenerator.
+// Extracting the request id allows blast to correlate responses with requests, even if the target
+// server sends the responses out of order.
+type RequestIdExtractor interface {
+	Extract(response []byte) (uint64, error)
+}
+
+// OffsetRequestIdExtractor extracts the request id encoded as an 8 byte unsigned integer at the
+// given offset in the response.
+type OffsetRequestIdExtractor struct {
+	offset    int
+	byteOrder binary.ByteOrder
+}
+
+// NewOffsetRequestIdExtractor creates a new instance of OffsetRequestIdExtractor.
+func NewOffsetRequestIdExtractor(offset int, byteOrder binary.ByteOrder) OffsetRequestIdExtractor {
+	return OffsetRequestIdExtractor{
+		offset:    offset,
+		byteOrder: byteOrder,
+	}
+}
+
+// Extract returns the request id from the response.
+func (extractor OffsetRequestIdExtractor) Extract(response []byte) (uint64, error) {
+	if len(response) < extractor.offset+8 {
+		return 0, ErrResponseTooShortForRequestId
+	}
+	return extractor.byteOrder.Uint64(response[extractor.offset : extractor.offset+8]), nil
+}
+
+// InFlightRequests tracks the send time of the requests that are waiting for their responses.
+// Workers add the send time of each request and the ResponseReader removes it when the
+// response with the same request id is read.
+// Along with the actual send time, InFlightRequests also tracks the intended send time of each request,
+// which is the time at which the request was supposed to be sent as per the rate schedule. Measuring the
+// latency from the intended send time corrects the coordinated omission: a stall in sending the requests
+// (for example, a blocked write during a GC pause of the server) is then included in the latency of all
+// the requests that were delayed by the stall.
+type InFlightRequests struct {
+	lock     sync.Mutex
+	requests map[uint64]inFlightRequest
+}
+
+// inFlightRequest represents the send times of a single in-flight request.
+type inFlightRequest struct {
+	sendTime         time.Time
+	intendedSendTime time.Time
+}
+
+// NewInFlightRequests creates a new instance of InFlightRequests.
+func NewInFlightRequests() *InFlightRequests {
+	return &InFlightRequests{
+		requests: make(map[uint64]inFlightRequest),
+	}
+}
+
+// Add records the send time of the request identified by requestId.
+// The intended send time of the request is the same as its send time.
+func (inFlightRequests *InFlightRequests) Add(requestId uint64, sendTime time.Time) {
+	inFlightRequests.AddWithIntendedSendTime(requestId, sendTime, sendTime)
+}
+
+// AddWithIntendedSendTime records the send time and the intended send time of the request identified
+// by requestId.
+func (inFlightRequests *InFlightRequests) AddWithIntendedSendTime(
+	requestId uint64,
+	sendTime time.Time,
+	intendedSendTime time.Time,
+) {
+	inFlightRequests.lock.Lock()
+	defer inFlightRequests.lock.Unlock()
+
+	inFlightRequests.requests[requestId] = inFlightRequest{
+		sendTime:         sendTime,
+		intendedSendTime: intendedSendTime,
+	}
+}
+
+// Remove removes the request identified by requestId and returns its send time.
+// The returned bool is false if the request is not in-flight.
+func (inFlightRequests *InFlightRequests) Remove(requestId uint64) (time.Time, bool) {
+	sendTime, _, ok := inFlightRequests.RemoveWithIntendedSendTime(requestId)
+	return sendTime, ok
+}
+
+// RemoveWithIntendedSendTime removes the request identified by requestId and returns its send time and
+// its intended send time.
+// The returned bool is false if the request is not in-flight.
+func (inFlightRequests *InFlightRequests) RemoveWithIntendedSendTime(requestId uint64) (time.Time, time.Time, bool) {
+	inFlightRequests.lock.Lock()
+	defer inFlightRequests.lock.Unlock()
+
+	request, ok := inFlightRequests.requests[requestId]
+	if ok {
+		delete(inFlightRequests.requests, requestId)
+	}
+	return request.sendTime, request.intendedSendTime, ok
+}
+
+// RemoveSentBefore removes the requests that were sent before the given time (the requests whose responses
+// have timed out), and returns the number of removed requests.
+func (inFlightRequests *InFlightRequests) RemoveSentBefore(sendTime time.Time) int {
+	inFlightRequests.lock.Lock()
+	defer inFlightRequests.lock.Unlock()
+
+	removed := 0
+	for requestId, request := range inFlightRequests.requests {
+		if request.sendTime.Before(sendTime) {
+			delete(inFlightRequests.requests, requestId)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Size returns the number of in-flight requests.
+func (inFlightRequests *InFlightRequests) Size() int {
+	inFlightRequests.lock.Lock()
+	defer inFlightRequests.lock.Unlock()
+
+	return len(inFlightRequests.requests)
+}
@@ -0,0 +1,86 @@
+package report
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractsTheRequestIdAtTheOffset(t *testing.T) {
+	response := make([]byte, 12)
+	binary.BigEndian.PutUint64(response[4:], 100)
+
+	requestId, err := NewOffsetRequestIdExtractor(4, binary.BigEndian).Extract(response)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(100), requestId)
+}
+
+func TestExtractsTheRequestIdInLittleEndian(t *testing.T) {
+	response := make([]byte, 8)
+	binary.LittleEndian.PutUint64(response, 200)
+
+	requestId, err := NewOffsetRequestIdExtractor(0, binary.LittleEndian).Extract(response)
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(200), requestId)
+}
+
+func TestExtractsTheRequestIdFromAShortResponse(t *testing.T) {
+	_, err := NewOffsetRequestIdExtractor(4, binary.BigEndian).Extract(make([]byte, 10))
+	assert.Equal(t, ErrResponseTooShortForRequestId, err)
+}
+
+func TestAddsAndRemovesAnInFlightRequest(t *testing.T) {
+	inFlightRequests := NewInFlightRequests()
+	now := time.Now()
+	inFlightRequests.Add(1, now)
+
+	sendTime, ok := inFlightRequests.Remove(1)
+	assert.True(t, ok)
+	assert.Equal(t, now, sendTime)
+	assert.Equal(t, 0, inFlightRequests.Size())
+}
+
+func TestRemovesANonExistingInFlightRequest(t *testing.T) {
+	inFlightRequests := NewInFlightRequests()
+	_, ok := inFlightRequests.Remove(1)
+	assert.False(t, ok)
+}
+
+func TestAddsAndRemovesAnInFlightRequestWithIntendedSendTime(t *testing.T) {
+	inFlightRequests := NewInFlightRequests()
+	now := time.Now()
+	inFlightRequests.AddWithIntendedSendTime(1, now, now.Add(-time.Second))
+
+	sendTime, intendedSendTime, ok := inFlightRequests.RemoveWithIntendedSendTime(1)
+	assert.True(t, ok)
+	assert.Equal(t, now, sendTime)
+	assert.Equal(t, now.Add(-time.Second), intendedSendTime)
+	assert.Equal(t, 0, inFlightRequests.Size())
+}
+
+func TestAddsAnInFlightRequestWithTheSendTimeAsTheIntendedSendTime(t *testing.T) {
+	inFlightRequests := NewInFlightRequests()
+	now := time.Now()
+	inFlightRequests.Add(1, now)
+
+	sendTime, intendedSendTime, ok := inFlightRequests.RemoveWithIntendedSendTime(1)
+	assert.True(t, ok)
+	assert.Equal(t, now, sendTime)
+	assert.Equal(t, now, intendedSendTime)
+}
+
+func TestRemovesTheInFlightRequestsSentBeforeATime(t *testing.T) {
+	inFlightRequests := NewInFlightRequests()
+	now := time.Now()
+	inFlightRequests.Add(1, now.Add(-2*time.Second))
+	inFlightRequests.Add(2, now.Add(-time.Second))
+	inFlightRequests.Add(3, now)
+
+	assert.Equal(t, 2, inFlightRequests.RemoveSentBefore(now.Add(-500*time.Millisecond)))
+	assert.Equal(t, 1, inFlightRequests.Size())
+
+	_, ok := inFlightRequests.Remove(3)
+	assert.True(t, ok)
+}
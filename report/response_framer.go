@@ -0,0 +1,182 @@
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// MaxResponseFrameSizeBytes is the maximum size of a single response that a length-prefixed ResponseFramer accepts.
+// It guards against allocating huge buffers when the stream is not framed the way blast expects.
+const MaxResponseFrameSizeBytes = 64 * 1024 * 1024
+
+// ErrResponseFrameTooLarge is the error that is returned when the size of a response exceeds the
+// maximum size supported by the ResponseFramer.
+var ErrResponseFrameTooLarge = errors.New("response frame is too large")
+
+// ErrUnsupportedLengthPrefixSize is the error that is returned when the length prefix is neither 2 nor 4 bytes.
+var ErrUnsupportedLengthPrefixSize = errors.New("length prefix must be either 2 or 4 bytes")
+
+// ErrInvalidResponseSize is the error that is returned when the size of the responses of FixedSizeResponseFramer
+// is not positive.
+var ErrInvalidResponseSize = errors.New("response size must be greater than zero")
+
+// ErrEmptyDelimiter is the error that is returned when the delimiter of DelimiterResponseFramer is empty.
+var ErrEmptyDelimiter = errors.New("delimiter cannot be empty")
+
+// ResponseFramer reads a single complete response (/message) from the stream.
+// TCP does not preserve message boundaries, a single read from the connection may return a part of a
+// response or multiple responses. ResponseFramer defines the boundaries of the responses, so that each
+// response that blast counts is one complete logical message.
+// The returned byte slice contains the entire message as it was read from the stream, including the
+// length prefix or the delimiter (if any).
+type ResponseFramer interface {
+	Frame(reader *bufio.Reader) ([]byte, error)
+}
+
+// FixedSizeResponseFramer frames the responses of a fixed size.
+type FixedSizeResponseFramer struct {
+	sizeBytes int
+}
+
+// LengthPrefixedResponseFramer frames the responses that are prefixed with their length.
+// The length prefix is either 2 or 4 bytes, encoded in the given byte order.
+// The length does not include the size of the prefix.
+type LengthPrefixedResponseFramer struct {
+	prefixSizeBytes int
+	byteOrder       binary.ByteOrder
+}
+
+// VarintLengthPrefixedResponseFramer frames the responses that are prefixed with their length,
+// encoded as an unsigned varint (the encoding used by protobuf for delimited messages).
+// The length does not include the size of the prefix.
+type VarintLengthPrefixedResponseFramer struct{}
+
+// DelimiterResponseFramer frames the responses that end with a delimiter.
+// The maximum size of a delimited response is limited by the size of the buffer of the bufio.Reader.
+type DelimiterResponseFramer struct {
+	delimiter []byte
+}
+
+// NewFixedSizeResponseFramer creates a new instance of FixedSizeResponseFramer.
+// It returns ErrInvalidResponseSize if the sizeBytes is not positive, a framer of zero bytes would frame
+// empty responses without reading from the stream.
+func NewFixedSizeResponseFramer(sizeBytes int64) (FixedSizeResponseFramer, error) {
+	if sizeBytes <= 0 {
+		return FixedSizeResponseFramer{}, ErrInvalidResponseSize
+	}
+	return FixedSizeResponseFramer{sizeBytes: int(sizeBytes)}, nil
+}
+
+// NewLengthPrefixedResponseFramer creates a new instance of LengthPrefixedResponseFramer.
+func NewLengthPrefixedResponseFramer(
+	prefixSizeBytes int,
+	byteOrder binary.ByteOrder,
+) (LengthPrefixedResponseFramer, error) {
+	if prefixSizeBytes != 2 && prefixSizeBytes != 4 {
+		return LengthPrefixedResponseFramer{}, ErrUnsupportedLengthPrefixSize
+	}
+	return LengthPrefixedResponseFramer{
+		prefixSizeBytes: prefixSizeBytes,
+		byteOrder:       byteOrder,
+	}, nil
+}
+
+// NewVarintLengthPrefixedResponseFramer creates a new instance of VarintLengthPrefixedResponseFramer.
+func NewVarintLengthPrefixedResponseFramer() VarintLengthPrefixedResponseFramer {
+	return VarintLengthPrefixedResponseFramer{}
+}
+
+// NewDelimiterResponseFramer creates a new instance of DelimiterResponseFramer.
+func NewDelimiterResponseFramer(delimiter []byte) (DelimiterResponseFramer, error) {
+	if len(delimiter) == 0 {
+		return DelimiterResponseFramer{}, ErrEmptyDelimiter
+	}
+	return DelimiterResponseFramer{delimiter: delimiter}, nil
+}
+
+// Frame reads a response of fixed size.
+func (framer FixedSizeResponseFramer) Frame(reader *bufio.Reader) ([]byte, error) {
+	return readFrame(reader, framer.sizeBytes)
+}
+
+// Frame reads the length prefix followed by the response.
+func (framer LengthPrefixedResponseFramer) Frame(reader *bufio.Reader) ([]byte, error) {
+	prefix, err := reader.Peek(framer.prefixSizeBytes)
+	if err != nil {
+		return nil, err
+	}
+	var length uint64
+	if framer.prefixSizeBytes == 2 {
+		length = uint64(framer.byteOrder.Uint16(prefix))
+	} else {
+		length = uint64(framer.byteOrder.Uint32(prefix))
+	}
+	if length > MaxResponseFrameSizeBytes {
+		return nil, ErrResponseFrameTooLarge
+	}
+	return readFrame(reader, framer.prefixSizeBytes+int(length))
+}
+
+// Frame reads the varint length prefix followed by the response.
+func (framer VarintLengthPrefixedResponseFramer) Frame(reader *bufio.Reader) ([]byte, error) {
+	for prefixSizeBytes := 1; prefixSizeBytes <= binary.MaxVarintLen64; prefixSizeBytes++ {
+		prefix, err := reader.Peek(prefixSizeBytes)
+		if err != nil {
+			return nil, err
+		}
+		if prefix[prefixSizeBytes-1]&0x80 != 0 {
+			continue
+		}
+		length, _ := binary.Uvarint(prefix)
+		if length > MaxResponseFrameSizeBytes {
+			return nil, ErrResponseFrameTooLarge
+		}
+		return readFrame(reader, prefixSizeBytes+int(length))
+	}
+	return nil, ErrResponseFrameTooLarge
+}
+
+// Frame reads the response till (and including) the delimiter.
+func (framer DelimiterResponseFramer) Frame(reader *bufio.Reader) ([]byte, error) {
+	peekSizeBytes := 1
+	for {
+		if reader.Buffered() > peekSizeBytes {
+			peekSizeBytes = reader.Buffered()
+		}
+		buffered, err := reader.Peek(peekSizeBytes)
+		if index := bytes.Index(buffered, framer.delimiter); index >= 0 {
+			return readFrame(reader, index+len(framer.delimiter))
+		}
+		if errors.Is(err, bufio.ErrBufferFull) {
+			return nil, ErrResponseFrameTooLarge
+		}
+		if err != nil {
+			return nil, err
+		}
+		peekSizeBytes = len(buffered) + 1
+	}
+}
+
+// readFrame reads sizeBytes from the reader.
+// If the frame fits in the buffer of the reader, the frame is peeked before it is consumed. This ensures that
+// a partially received frame is not lost if the read fails (for example: read deadline is exceeded), and
+// the frame can be read completely in the next attempt.
+func readFrame(reader *bufio.Reader, sizeBytes int) ([]byte, error) {
+	frame := make([]byte, sizeBytes)
+	if sizeBytes <= reader.Size() {
+		buffered, err := reader.Peek(sizeBytes)
+		if err != nil {
+			return nil, err
+		}
+		copy(frame, buffered)
+		_, _ = reader.Discard(sizeBytes)
+		return frame, nil
+	}
+	if _, err := io.ReadFull(reader, frame); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
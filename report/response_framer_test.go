@@ -0,0 +1,200 @@
+package report
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TimeoutError struct{}
+
+func (err TimeoutError) Error() string   { return "timeout" }
+func (err TimeoutError) Timeout() bool   { return true }
+func (err TimeoutError) Temporary() bool { return true }
+
+// ChunkedReader returns one chunk per Read and TimeoutError if a chunk is nil.
+type ChunkedReader struct {
+	chunks [][]byte
+}
+
+func (reader *ChunkedReader) Read(buffer []byte) (int, error) {
+	if len(reader.chunks) == 0 {
+		return 0, io.EOF
+	}
+	chunk := reader.chunks[0]
+	reader.chunks = reader.chunks[1:]
+	if chunk == nil {
+		return 0, TimeoutError{}
+	}
+	return copy(buffer, chunk), nil
+}
+
+func TestFramesFixedSizeResponsesThatAreCoalesced(t *testing.T) {
+	reader := bufio.NewReader(bytes.NewReader([]byte("HelloWorldBlastWorld")))
+	framer, err := NewFixedSizeResponseFramer(10)
+	assert.Nil(t, err)
+
+	response, err := framer.Frame(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, "HelloWorld", string(response))
+
+	response, err = framer.Frame(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, "BlastWorld", string(response))
+
+	_, err = framer.Frame(reader)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestCreatesAFixedSizeResponseFramerWithAZeroSize(t *testing.T) {
+	_, err := NewFixedSizeResponseFramer(0)
+	assert.Equal(t, ErrInvalidResponseSize, err)
+}
+
+func TestCreatesAFixedSizeResponseFramerWithANegativeSize(t *testing.T) {
+	_, err := NewFixedSizeResponseFramer(-1)
+	assert.Equal(t, ErrInvalidResponseSize, err)
+}
+
+func TestFramesAFixedSizeResponseThatIsSplit(t *testing.T) {
+	reader := bufio.NewReader(&ChunkedReader{chunks: [][]byte{[]byte("Hello"), []byte("World")}})
+
+	framer, err := NewFixedSizeResponseFramer(10)
+	assert.Nil(t, err)
+
+	response, err := framer.Frame(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, "HelloWorld", string(response))
+}
+
+func TestFramesAFixedSizeResponseThatIsSplitByATimeout(t *testing.T) {
+	reader := bufio.NewReader(&ChunkedReader{chunks: [][]byte{[]byte("Hello"), nil, []byte("World")}})
+	framer, err := NewFixedSizeResponseFramer(10)
+	assert.Nil(t, err)
+
+	_, err = framer.Frame(reader)
+	assert.Equal(t, TimeoutError{}, err)
+
+	response, err := framer.Frame(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, "HelloWorld", string(response))
+}
+
+func TestFramesAFixedSizeResponseLargerThanTheBuffer(t *testing.T) {
+	content := bytes.Repeat([]byte("a"), 100)
+	reader := bufio.NewReaderSize(bytes.NewReader(content), 16)
+
+	framer, err := NewFixedSizeResponseFramer(100)
+	assert.Nil(t, err)
+
+	response, err := framer.Frame(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, content, response)
+}
+
+func TestFramesLengthPrefixedResponses(t *testing.T) {
+	tests := []struct {
+		prefixSizeBytes int
+		byteOrder       binary.ByteOrder
+	}{
+		{prefixSizeBytes: 2, byteOrder: binary.BigEndian},
+		{prefixSizeBytes: 2, byteOrder: binary.LittleEndian},
+		{prefixSizeBytes: 4, byteOrder: binary.BigEndian},
+		{prefixSizeBytes: 4, byteOrder: binary.LittleEndian},
+	}
+	for _, test := range tests {
+		var stream []byte
+		for _, message := range []string{"Hello", "BlastWorld"} {
+			prefix := make([]byte, test.prefixSizeBytes)
+			if test.prefixSizeBytes == 2 {
+				test.byteOrder.PutUint16(prefix, uint16(len(message)))
+			} else {
+				test.byteOrder.PutUint32(prefix, uint32(len(message)))
+			}
+			stream = append(stream, prefix...)
+			stream = append(stream, message...)
+		}
+
+		framer, err := NewLengthPrefixedResponseFramer(test.prefixSizeBytes, test.byteOrder)
+		assert.Nil(t, err)
+
+		reader := bufio.NewReader(bytes.NewReader(stream))
+		response, err := framer.Frame(reader)
+		assert.Nil(t, err)
+		assert.Equal(t, "Hello", string(response[test.prefixSizeBytes:]))
+
+		response, err = framer.Frame(reader)
+		assert.Nil(t, err)
+		assert.Equal(t, "BlastWorld", string(response[test.prefixSizeBytes:]))
+	}
+}
+
+func TestFramesALengthPrefixedResponseThatIsTooLarge(t *testing.T) {
+	framer, err := NewLengthPrefixedResponseFramer(4, binary.BigEndian)
+	assert.Nil(t, err)
+
+	_, err = framer.Frame(bufio.NewReader(bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff})))
+	assert.Equal(t, ErrResponseFrameTooLarge, err)
+}
+
+func TestCreatesALengthPrefixedResponseFramerWithUnsupportedPrefixSize(t *testing.T) {
+	_, err := NewLengthPrefixedResponseFramer(3, binary.BigEndian)
+	assert.Equal(t, ErrUnsupportedLengthPrefixSize, err)
+}
+
+func TestFramesVarintLengthPrefixedResponses(t *testing.T) {
+	largeMessage := bytes.Repeat([]byte("a"), 300)
+
+	var stream []byte
+	stream = binary.AppendUvarint(stream, 5)
+	stream = append(stream, "Hello"...)
+	stream = binary.AppendUvarint(stream, uint64(len(largeMessage)))
+	stream = append(stream, largeMessage...)
+
+	reader := bufio.NewReader(bytes.NewReader(stream))
+	framer := NewVarintLengthPrefixedResponseFramer()
+
+	response, err := framer.Frame(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, "Hello", string(response[1:]))
+
+	response, err = framer.Frame(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, largeMessage, response[2:])
+}
+
+func TestFramesDelimitedResponses(t *testing.T) {
+	framer, err := NewDelimiterResponseFramer([]byte("\r\n"))
+	assert.Nil(t, err)
+
+	reader := bufio.NewReader(&ChunkedReader{chunks: [][]byte{[]byte("OK\r\nHel"), []byte("lo\r"), nil, []byte("\n")}})
+	response, err := framer.Frame(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, "OK\r\n", string(response))
+
+	_, err = framer.Frame(reader)
+	assert.True(t, errors.As(err, &TimeoutError{}))
+
+	response, err = framer.Frame(reader)
+	assert.Nil(t, err)
+	assert.Equal(t, "Hello\r\n", string(response))
+}
+
+func TestFramesADelimitedResponseThatIsLargerThanTheBuffer(t *testing.T) {
+	framer, err := NewDelimiterResponseFramer([]byte("\n"))
+	assert.Nil(t, err)
+
+	reader := bufio.NewReaderSize(bytes.NewReader(bytes.Repeat([]byte("a"), 100)), 16)
+	_, err = framer.Frame(reader)
+	assert.Equal(t, ErrResponseFrameTooLarge, err)
+}
+
+func TestCreatesADelimiterResponseFramerWithEmptyDelimiter(t *testing.T) {
+	_, err := NewDelimiterResponseFramer(nil)
+	assert.Equal(t, ErrEmptyDelimiter, err)
+}
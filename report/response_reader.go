@@ -1,11 +1,13 @@
 package report
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
 	"io"
 	"net"
 	"os"
+	"sync"
 	"sync/atomic"
 	"time"
 )
@@ -14,52 +16,170 @@ import (
 const NilConnectionId int = -1
 
 // LoadGenerationResponse represents the load generated on the target server.
+// Dropped and Late are only relevant for the open load model, where the requests are scheduled at
+// a constant arrival rate. Dropped is true if the scheduled request could not be sent because no worker
+// was available, LoadGenerationTime is then the scheduled time. Late is true if the request was sent
+// later than its scheduled time.
+// Target is the target server of the request, it is empty if the load is sent to a single target server.
 type LoadGenerationResponse struct {
 	Err                error
 	PayloadLengthBytes int64
 	LoadGenerationTime time.Time
 	ConnectionId       int
+	RequestId          uint64
+	Dropped            bool
+	Late               bool
+	Target             string
 }
 
 // SubjectServerResponse represents the response read from the target server.
+// Latency is only available (LatencyMeasured is true) if the response could be correlated with its request.
+// Latency is measured from the actual send time of the request, whereas CorrectedLatency is measured from
+// the intended send time of the request (corrected for the coordinated omission).
+// Target is the target server of the response, it is empty if the load is sent to a single target server.
 type SubjectServerResponse struct {
 	Err                error
 	ResponseTime       time.Time
 	PayloadLengthBytes int64
+	Latency            time.Duration
+	CorrectedLatency   time.Duration
+	LatencyMeasured    bool
+	Target             string
 }
 
 // ResponseReader reads the response from the specified net.Conn.
+// Each response is framed by the ResponseFramer on a stream (tcp or unix) connection, whereas each datagram
+// is a single response on a datagram (udp) connection.
+// Each response is successful, unless it is rejected by the (optional) ResponseValidator.
+// If the ResponseReader measures the latency, the in-flight requests whose responses are not read within the
+// in-flight timeout are evicted from InFlightRequests, and each is reported as a failed response with
+// ErrResponseTimeout (see WithInFlightTimeout).
 type ResponseReader struct {
-	responseSizeBytes       int64
+	responseFramer          ResponseFramer
+	responseValidator       ResponseValidator
 	readDeadline            time.Duration
+	inFlightTimeout         time.Duration
+	startEviction           sync.Once
+	requestIdExtractor      RequestIdExtractor
+	inFlightRequests        *InFlightRequests
 	readTotalResponses      atomic.Uint64
 	readSuccessfulResponses atomic.Uint64
 	stopChannel             chan struct{}
 	responseChannel         chan SubjectServerResponse
+	lock                    sync.Mutex
+	closed                  bool
+	connections             map[net.Conn]struct{}
+	goroutines              sync.WaitGroup
 }
 
-// NewResponseReader creates a new instance of ResponseReader.
+// readBufferSizeBytes is the size of the buffer used for reading responses from each connection.
+const readBufferSizeBytes = 64 * 1024
+
+// maxDatagramSizeBytes is the maximum size of a datagram (response) read from a udp connection.
+const maxDatagramSizeBytes = 64 * 1024
+
+// NewResponseReader creates a new instance of ResponseReader that reads responses of fixed size.
 // All the read responses are sent to responseChannel.
+// It returns ErrInvalidResponseSize if the responseSizeBytes is not positive.
 func NewResponseReader(
 	responseSizeBytes int64,
 	readDeadline time.Duration,
 	responseChannel chan SubjectServerResponse,
+) (*ResponseReader, error) {
+	return NewResponseReaderWithRequestIdExtractor(responseSizeBytes, readDeadline, nil, responseChannel)
+}
+
+// NewResponseReaderWithRequestIdExtractor creates a new instance of ResponseReader that measures the latency
+// of each response.
+// The request id is extracted from each response using the requestIdExtractor and matched with the send time
+// recorded (by the workers) in InFlightRequests.
+// A nil requestIdExtractor disables latency measurement.
+// It returns ErrInvalidResponseSize if the responseSizeBytes is not positive.
+func NewResponseReaderWithRequestIdExtractor(
+	responseSizeBytes int64,
+	readDeadline time.Duration,
+	requestIdExtractor RequestIdExtractor,
+	responseChannel chan SubjectServerResponse,
+) (*ResponseReader, error) {
+	responseFramer, err := NewFixedSizeResponseFramer(responseSizeBytes)
+	if err != nil {
+		return nil, err
+	}
+	return NewResponseReaderFullyLoaded(
+		responseFramer,
+		readDeadline,
+		requestIdExtractor,
+		responseChannel,
+	), nil
+}
+
+// NewResponseReaderFullyLoaded creates a new instance of ResponseReader that frames the responses using
+// the responseFramer.
+// A nil requestIdExtractor disables latency measurement.
+func NewResponseReaderFullyLoaded(
+	responseFramer ResponseFramer,
+	readDeadline time.Duration,
+	requestIdExtractor RequestIdExtractor,
+	responseChannel chan SubjectServerResponse,
 ) *ResponseReader {
+	var inFlightRequests *InFlightRequests
+	if requestIdExtractor != nil {
+		inFlightRequests = NewInFlightRequests()
+	}
 	return &ResponseReader{
-		responseSizeBytes: responseSizeBytes,
-		readDeadline:      readDeadline,
-		stopChannel:       make(chan struct{}),
-		responseChannel:   responseChannel,
+		responseFramer:     responseFramer,
+		readDeadline:       readDeadline,
+		requestIdExtractor: requestIdExtractor,
+		inFlightRequests:   inFlightRequests,
+		stopChannel:        make(chan struct{}),
+		responseChannel:    responseChannel,
+		connections:        make(map[net.Conn]struct{}),
 	}
 }
 
+// WithResponseValidator sets the ResponseValidator that classifies each response as successful or failed,
+// it must be set before the ResponseReader starts reading.
+// A response rejected by the ResponseValidator is reported with the error returned by the ResponseValidator,
+// and is not counted in TotalSuccessfulResponsesRead. Its latency is still measured.
+func (responseReader *ResponseReader) WithResponseValidator(responseValidator ResponseValidator) *ResponseReader {
+	responseReader.responseValidator = responseValidator
+	return responseReader
+}
+
+// WithInFlightTimeout sets the duration after which an in-flight request (whose response is not read) is
+// evicted from InFlightRequests and reported as a failed response with ErrResponseTimeout. It must be set
+// before the ResponseReader starts reading.
+// The read deadline is the in-flight timeout if it is not set, and the in-flight requests are not evicted if
+// neither is set. Each request is then counted exactly once: the read calls that time out (the read deadline)
+// are not reported, and a response whose request is not in-flight (for example: it is read after its request is
+// evicted) is discarded.
+func (responseReader *ResponseReader) WithInFlightTimeout(inFlightTimeout time.Duration) *ResponseReader {
+	responseReader.inFlightTimeout = inFlightTimeout
+	return responseReader
+}
+
 // StartReading runs a goroutine that reads from the provided net.Conn.
-// It keeps on reading from the connection until either of the two happen:
-// 1) Reading from the connection returns an io.EOF error
-// 2) ResponseReader gets stopped
+// It keeps on reading from the connection until either of the three happen:
+// 1) Reading from the connection returns an io.EOF error, or the connection is closed (for example: when it is
+// churned or re-established by the workers.WorkerGroup)
+// 2) The stream can not be framed anymore (for example: ErrResponseFrameTooLarge)
+// 3) ResponseReader gets stopped
 // ResponseReader implements one goroutine for each new connection created by the workers.WorkerGroup.
 func (responseReader *ResponseReader) StartReading(connection net.Conn) {
+	responseReader.StartReadingFromTarget(connection, "")
+}
+
+// StartReadingFromTarget runs a goroutine that reads from the provided net.Conn (see StartReading), and
+// reports the responses with the given target server.
+// The connection is closed without reading from it, if the ResponseReader is already closed.
+func (responseReader *ResponseReader) StartReadingFromTarget(connection net.Conn, target string) {
+	if !responseReader.track(connection) {
+		_ = connection.Close()
+		return
+	}
+	responseReader.startEviction.Do(responseReader.evictTimedOutRequests)
 	go func(connection net.Conn) {
+		defer responseReader.untrack(connection)
 		defer func() {
 			_ = connection.Close()
 			if err := recover(); err != nil {
@@ -67,6 +187,7 @@ func (responseReader *ResponseReader) StartReading(connection net.Conn) {
 			}
 		}()
 
+		readResponse := responseReader.responseReaderOf(connection)
 		for {
 			select {
 			case <-responseReader.stopChannel:
@@ -75,24 +196,45 @@ func (responseReader *ResponseReader) StartReading(connection net.Conn) {
 				if responseReader.readDeadline != time.Duration(0) {
 					_ = connection.SetReadDeadline(time.Now().Add(responseReader.readDeadline))
 				}
-				buffer := make([]byte, responseReader.responseSizeBytes)
-				n, err := connection.Read(buffer)
+				response, err := readResponse()
 
 				if err != nil {
-					if errors.Is(err, io.EOF) {
+					if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, net.ErrClosed) {
 						return
 					}
+					if responseReader.evictsInFlightRequests() && isTimeout(err) {
+						continue
+					}
 					responseReader.readTotalResponses.Add(1)
-					responseReader.responseChannel <- SubjectServerResponse{
-						Err:          err,
-						ResponseTime: time.Now(),
+					if !responseReader.send(SubjectServerResponse{Err: err, ResponseTime: time.Now(), Target: target}) {
+						return
+					}
+					if errors.Is(err, ErrResponseFrameTooLarge) {
+						return
+					}
+				} else {
+					responseTime := time.Now()
+					latency, correctedLatency, correlation := responseReader.latencyOf(response, responseTime)
+					if correlation == notInFlight && responseReader.evictsInFlightRequests() {
+						continue
+					}
+					validationErr := responseReader.validate(response)
+
+					if validationErr == nil {
+						responseReader.readSuccessfulResponses.Add(1)
 					}
-				} else if n > 0 && buffer != nil && len(buffer) > 0 {
-					responseReader.readSuccessfulResponses.Add(1)
 					responseReader.readTotalResponses.Add(1)
-					responseReader.responseChannel <- SubjectServerResponse{
-						ResponseTime:       time.Now(),
-						PayloadLengthBytes: int64(len(buffer)),
+					sent := responseReader.send(SubjectServerResponse{
+						Err:                validationErr,
+						ResponseTime:       responseTime,
+						PayloadLengthBytes: int64(len(response)),
+						Latency:            latency,
+						CorrectedLatency:   correctedLatency,
+						LatencyMeasured:    correlation == correlated,
+						Target:             target,
+					})
+					if !sent {
+						return
 					}
 				}
 			}
@@ -100,9 +242,194 @@ func (responseReader *ResponseReader) StartReading(connection net.Conn) {
 	}(connection)
 }
 
-// Close closes the stopChannel which stops all the goroutines.
+// evictTimedOutRequests runs a goroutine that evicts the in-flight requests whose responses have timed out,
+// till the ResponseReader gets stopped. Each evicted request is reported as a failed response with
+// ErrResponseTimeout.
+func (responseReader *ResponseReader) evictTimedOutRequests() {
+	if !responseReader.evictsInFlightRequests() {
+		return
+	}
+	timeout := responseReader.timeout()
+	responseReader.goroutines.Add(1)
+	go func() {
+		defer responseReader.goroutines.Done()
+		ticker := time.NewTicker(timeout / 2)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-responseReader.stopChannel:
+				return
+			case now := <-ticker.C:
+				evicted := responseReader.inFlightRequests.RemoveSentBefore(now.Add(-timeout))
+				for count := 0; count < evicted; count++ {
+					responseReader.readTotalResponses.Add(1)
+					if !responseReader.send(SubjectServerResponse{Err: ErrResponseTimeout, ResponseTime: now}) {
+						return
+					}
+				}
+			}
+		}
+	}()
+}
+
+// send sends the response on the responseChannel, unless the ResponseReader gets stopped while the
+// responseChannel is full. It returns false if the ResponseReader is stopped.
+func (responseReader *ResponseReader) send(response SubjectServerResponse) bool {
+	select {
+	case responseReader.responseChannel <- response:
+		return true
+	case <-responseReader.stopChannel:
+		return false
+	}
+}
+
+// track adds the connection to the connections that the ResponseReader reads from, and adds its goroutine to
+// the goroutines that Close waits for. It returns false if the ResponseReader is already closed.
+func (responseReader *ResponseReader) track(connection net.Conn) bool {
+	responseReader.lock.Lock()
+	defer responseReader.lock.Unlock()
+
+	if responseReader.closed {
+		return false
+	}
+	responseReader.connections[connection] = struct{}{}
+	responseReader.goroutines.Add(1)
+	return true
+}
+
+// untrack removes the connection from the connections that the ResponseReader reads from, once its goroutine
+// returns.
+func (responseReader *ResponseReader) untrack(connection net.Conn) {
+	responseReader.lock.Lock()
+	delete(responseReader.connections, connection)
+	responseReader.lock.Unlock()
+
+	responseReader.goroutines.Done()
+}
+
+// timeout returns the in-flight timeout, which is the read deadline if the in-flight timeout is not set.
+func (responseReader *ResponseReader) timeout() time.Duration {
+	if responseReader.inFlightTimeout > 0 {
+		return responseReader.inFlightTimeout
+	}
+	return responseReader.readDeadline
+}
+
+// responseReaderOf returns the function that reads the next response from the connection.
+// On a datagram (udp) connection, each datagram is a single response and the ResponseFramer is not used.
+// On a stream (tcp or unix) connection, the responses are framed by the ResponseFramer.
+func (responseReader *ResponseReader) responseReaderOf(connection net.Conn) func() ([]byte, error) {
+	if isDatagramConnection(connection) {
+		buffer := make([]byte, maxDatagramSizeBytes)
+		return func() ([]byte, error) {
+			n, err := connection.Read(buffer)
+			if err != nil {
+				return nil, err
+			}
+			return buffer[:n], nil
+		}
+	}
+	reader := bufio.NewReaderSize(connection, readBufferSizeBytes)
+	return func() ([]byte, error) {
+		return responseReader.responseFramer.Frame(reader)
+	}
+}
+
+// isDatagramConnection returns true if the connection preserves the message boundaries (udp).
+func isDatagramConnection(connection net.Conn) bool {
+	if connection.LocalAddr() == nil {
+		return false
+	}
+	switch connection.LocalAddr().Network() {
+	case "udp", "udp4", "udp6", "unixgram":
+		return true
+	default:
+		return false
+	}
+}
+
+// correlation defines whether a response is correlated with its request.
+// uncorrelated: the request id can not be extracted from the response (or the latency is not measured).
+// correlated: the request of the response is in-flight.
+// notInFlight: the request id of the response is not in-flight, for example: its request has been evicted.
+type correlation uint8
+
+const (
+	uncorrelated correlation = iota
+	correlated
+	notInFlight
+)
+
+// latencyOf returns the time elapsed between sending the request and receiving its response, along with
+// the time elapsed between the intended send time of the request and receiving its response.
+// The latencies are only measured if the response is correlated with an in-flight request.
+func (responseReader *ResponseReader) latencyOf(
+	response []byte,
+	responseTime time.Time,
+) (time.Duration, time.Duration, correlation) {
+	if responseReader.requestIdExtractor == nil {
+		return 0, 0, uncorrelated
+	}
+	requestId, err := responseReader.requestIdExtractor.Extract(response)
+	if err != nil {
+		return 0, 0, uncorrelated
+	}
+	sendTime, intendedSendTime, ok := responseReader.inFlightRequests.RemoveWithIntendedSendTime(requestId)
+	if !ok {
+		return 0, 0, notInFlight
+	}
+	return responseTime.Sub(sendTime), responseTime.Sub(intendedSendTime), correlated
+}
+
+// evictsInFlightRequests returns true if the in-flight requests whose responses time out are evicted (and
+// reported with ErrResponseTimeout).
+func (responseReader *ResponseReader) evictsInFlightRequests() bool {
+	return responseReader.inFlightRequests != nil && responseReader.timeout() > 0
+}
+
+// isTimeout returns true if the error is the timeout of a read call (the read deadline).
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// validate returns the error that classifies the response as failed, it returns nil if the response is
+// successful or if there is no ResponseValidator.
+func (responseReader *ResponseReader) validate(response []byte) error {
+	if responseReader.responseValidator == nil {
+		return nil
+	}
+	return responseReader.responseValidator.Validate(response)
+}
+
+// InFlightRequests returns the InFlightRequests that the workers use for recording the send time of requests.
+// It returns nil if the ResponseReader does not measure latency.
+func (responseReader *ResponseReader) InFlightRequests() *InFlightRequests {
+	return responseReader.inFlightRequests
+}
+
+// Close stops all the goroutines of the ResponseReader: it closes the stopChannel and the connections that are
+// being read, and waits for the goroutines to return. The responseChannel is not written after Close returns,
+// so it can then be closed. Close is idempotent.
 func (responseReader *ResponseReader) Close() {
+	responseReader.lock.Lock()
+	if responseReader.closed {
+		responseReader.lock.Unlock()
+		return
+	}
+	responseReader.closed = true
 	close(responseReader.stopChannel)
+	connections := make([]net.Conn, 0, len(responseReader.connections))
+	for connection := range responseReader.connections {
+		connections = append(connections, connection)
+	}
+	responseReader.lock.Unlock()
+
+	for _, connection := range connections {
+		_ = connection.Close()
+	}
+	responseReader.goroutines.Wait()
 }
 
 // TotalResponsesRead returns the total responses read from the target server.
@@ -0,0 +1,103 @@
+package report
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// ErrEmptyResponsePrefix is the error that is returned when the prefix of PrefixResponseValidator is empty.
+var ErrEmptyResponsePrefix = errors.New("response prefix cannot be empty")
+
+// ErrResponsePrefixMismatch is the error that classifies a response that does not start with the expected prefix.
+var ErrResponsePrefixMismatch = errors.New("response does not start with the expected prefix")
+
+// ErrResponseRegexMismatch is the error that classifies a response that does not match the expected regular
+// expression.
+var ErrResponseRegexMismatch = errors.New("response does not match the expected pattern")
+
+// ErrResponseTooShortForStatusByte is the error that classifies a response that is too short to contain the
+// status byte.
+var ErrResponseTooShortForStatusByte = errors.New("response is too short to contain the status byte")
+
+// ErrUnexpectedStatusByte is the error that classifies a response with an unexpected status byte.
+// The returned error wraps ErrUnexpectedStatusByte and includes the actual status byte, so that the responses
+// are classified (in ResponseMetrics.ErrorCountByType) by their status.
+var ErrUnexpectedStatusByte = errors.New("unexpected status byte")
+
+// ResponseValidator validates each (framed) response read from the target server.
+// A non-empty response does not imply a successful response, for example: a server replying "ERR overloaded".
+// ResponseValidator returns nil if the response is successful, else the error that classifies the failure.
+// The failed responses are counted in ResponseMetrics.ErrorCountByType by the message of the error, so the
+// error messages should be a small, fixed set of classifications.
+type ResponseValidator interface {
+	Validate(response []byte) error
+}
+
+// PrefixResponseValidator validates that the response starts with the given prefix.
+type PrefixResponseValidator struct {
+	prefix []byte
+}
+
+// RegexResponseValidator validates that the response matches the given regular expression.
+type RegexResponseValidator struct {
+	expression *regexp.Regexp
+}
+
+// StatusByteResponseValidator validates that the byte at the given offset in the response is the expected status.
+type StatusByteResponseValidator struct {
+	offset int
+	status byte
+}
+
+// NewPrefixResponseValidator creates a new instance of PrefixResponseValidator.
+func NewPrefixResponseValidator(prefix []byte) (PrefixResponseValidator, error) {
+	if len(prefix) == 0 {
+		return PrefixResponseValidator{}, ErrEmptyResponsePrefix
+	}
+	return PrefixResponseValidator{prefix: prefix}, nil
+}
+
+// NewRegexResponseValidator creates a new instance of RegexResponseValidator, it returns an error if the
+// pattern is not a valid regular expression.
+func NewRegexResponseValidator(pattern string) (RegexResponseValidator, error) {
+	expression, err := regexp.Compile(pattern)
+	if err != nil {
+		return RegexResponseValidator{}, err
+	}
+	return RegexResponseValidator{expression: expression}, nil
+}
+
+// NewStatusByteResponseValidator creates a new instance of StatusByteResponseValidator.
+func NewStatusByteResponseValidator(offset int, status byte) StatusByteResponseValidator {
+	return StatusByteResponseValidator{offset: offset, status: status}
+}
+
+// Validate returns ErrResponsePrefixMismatch if the response does not start with the prefix.
+func (validator PrefixResponseValidator) Validate(response []byte) error {
+	if !bytes.HasPrefix(response, validator.prefix) {
+		return ErrResponsePrefixMismatch
+	}
+	return nil
+}
+
+// Validate returns ErrResponseRegexMismatch if the response does not match the regular expression.
+func (validator RegexResponseValidator) Validate(response []byte) error {
+	if !validator.expression.Match(response) {
+		return ErrResponseRegexMismatch
+	}
+	return nil
+}
+
+// Validate returns ErrResponseTooShortForStatusByte if the response does not contain the offset, and an error
+// wrapping ErrUnexpectedStatusByte if the byte at the offset is not the expected status.
+func (validator StatusByteResponseValidator) Validate(response []byte) error {
+	if validator.offset < 0 || len(response) <= validator.offset {
+		return ErrResponseTooShortForStatusByte
+	}
+	if actual := response[validator.offset]; actual != validator.status {
+		return fmt.Errorf("%w: 0x%02x", ErrUnexpectedStatusByte, actual)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package report
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidatesAResponseWithThePrefix(t *testing.T) {
+	validator, err := NewPrefixResponseValidator([]byte("+OK"))
+	assert.Nil(t, err)
+	assert.Nil(t, validator.Validate([]byte("+OK\r\n")))
+}
+
+func TestValidatesAResponseWithoutThePrefix(t *testing.T) {
+	validator, err := NewPrefixResponseValidator([]byte("+OK"))
+	assert.Nil(t, err)
+	assert.Equal(t, ErrResponsePrefixMismatch, validator.Validate([]byte("ERR overloaded")))
+	assert.Equal(t, ErrResponsePrefixMismatch, validator.Validate([]byte("+O")))
+}
+
+func TestCreatesAPrefixResponseValidatorWithAnEmptyPrefix(t *testing.T) {
+	_, err := NewPrefixResponseValidator(nil)
+	assert.Equal(t, ErrEmptyResponsePrefix, err)
+}
+
+func TestValidatesAResponseMatchingTheRegex(t *testing.T) {
+	validator, err := NewRegexResponseValidator("^(OK|STORED)")
+	assert.Nil(t, err)
+	assert.Nil(t, validator.Validate([]byte("STORED\r\n")))
+}
+
+func TestValidatesAResponseNotMatchingTheRegex(t *testing.T) {
+	validator, err := NewRegexResponseValidator("^(OK|STORED)")
+	assert.Nil(t, err)
+	assert.Equal(t, ErrResponseRegexMismatch, validator.Validate([]byte("ERR overloaded")))
+}
+
+func TestCreatesARegexResponseValidatorWithAnInvalidRegex(t *testing.T) {
+	_, err := NewRegexResponseValidator("(OK")
+	assert.NotNil(t, err)
+}
+
+func TestValidatesAResponseWithTheStatusByte(t *testing.T) {
+	validator := NewStatusByteResponseValidator(2, 0x00)
+	assert.Nil(t, validator.Validate([]byte{0, 3, 0x00, 'O', 'K'}))
+}
+
+func TestValidatesAResponseWithAnUnexpectedStatusByte(t *testing.T) {
+	validator := NewStatusByteResponseValidator(2, 0x00)
+	err := validator.Validate([]byte{0, 3, 0x05, 'O', 'K'})
+
+	assert.True(t, errors.Is(err, ErrUnexpectedStatusByte))
+	assert.Equal(t, "unexpected status byte: 0x05", err.Error())
+}
+
+func TestValidatesAResponseTooShortForTheStatusByte(t *testing.T) {
+	validator := NewStatusByteResponseValidator(2, 0x00)
+	assert.Equal(t, ErrResponseTooShortForStatusByte, validator.Validate([]byte{0, 3}))
+}
@@ -0,0 +1,143 @@
+package report
+
+import (
+	"sync"
+	"time"
+)
+
+// ReportStage identifies a stage of the run (for example, a stage of a load profile) whose metrics are
+// reported separately. The stages are contiguous: the first stage starts at the start of the run, and
+// each stage starts when the previous stage ends.
+type ReportStage struct {
+	Name     string
+	Duration time.Duration
+}
+
+// StageMetrics represents the metrics of a single ReportStage.
+// Start is the offset of the stage from the start of the run.
+// RequestsSent includes both the successful and the failed requests, but not the dropped requests.
+// Responses includes both the successful and the failed responses.
+type StageMetrics struct {
+	Name             string
+	Start            time.Duration
+	Duration         time.Duration
+	RequestsSent     uint
+	RequestErrors    uint
+	DroppedRequests  uint
+	LateRequests     uint
+	Responses        uint
+	ResponseErrors   uint
+	Latency          LatencyMetrics
+	CorrectedLatency LatencyMetrics
+}
+
+// stageCollector buckets the LoadGenerationResponse and SubjectServerResponse in the stages.
+// Events after the end of the last stage belong to the last stage.
+// stageCollector is thread-safe, it is shared by the goroutines of the Reporter.
+type stageCollector struct {
+	lock               sync.Mutex
+	startTime          time.Time
+	stages             []StageMetrics
+	latencies          []*latencyRecorder
+	correctedLatencies []*latencyRecorder
+}
+
+// newStageCollector creates a new instance of stageCollector.
+func newStageCollector(reportStages []ReportStage) *stageCollector {
+	collector := &stageCollector{}
+	start := time.Duration(0)
+	for _, reportStage := range reportStages {
+		collector.stages = append(collector.stages, StageMetrics{
+			Name:     reportStage.Name,
+			Start:    start,
+			Duration: reportStage.Duration,
+		})
+		collector.latencies = append(collector.latencies, newLatencyRecorder())
+		collector.correctedLatencies = append(collector.correctedLatencies, newLatencyRecorder())
+		start += reportStage.Duration
+	}
+	return collector
+}
+
+// start sets the start time of the run.
+func (collector *stageCollector) start(startTime time.Time) {
+	collector.lock.Lock()
+	defer collector.lock.Unlock()
+
+	collector.startTime = startTime
+}
+
+// addLoad adds the LoadGenerationResponse to the stage that contains its LoadGenerationTime.
+func (collector *stageCollector) addLoad(load LoadGenerationResponse) {
+	collector.lock.Lock()
+	defer collector.lock.Unlock()
+
+	index, ok := collector.indexOf(load.LoadGenerationTime)
+	if !ok {
+		return
+	}
+	stage := &collector.stages[index]
+	if load.Dropped {
+		stage.DroppedRequests++
+		return
+	}
+	stage.RequestsSent++
+	if load.Err != nil {
+		stage.RequestErrors++
+	}
+	if load.Late {
+		stage.LateRequests++
+	}
+}
+
+// addResponse adds the SubjectServerResponse to the stage that contains its ResponseTime.
+func (collector *stageCollector) addResponse(response SubjectServerResponse) {
+	collector.lock.Lock()
+	defer collector.lock.Unlock()
+
+	index, ok := collector.indexOf(response.ResponseTime)
+	if !ok {
+		return
+	}
+	stage := &collector.stages[index]
+	stage.Responses++
+	if response.Err != nil {
+		stage.ResponseErrors++
+	}
+	if response.LatencyMeasured {
+		collector.latencies[index].record(response.Latency)
+		collector.correctedLatencies[index].record(response.CorrectedLatency)
+	}
+}
+
+// indexOf returns the index of the stage that contains the given time.
+// Events without time are not bucketed, events before the start time belong to the first stage.
+func (collector *stageCollector) indexOf(eventTime time.Time) (int, bool) {
+	if len(collector.stages) == 0 || eventTime.IsZero() || collector.startTime.IsZero() {
+		return 0, false
+	}
+	offset := eventTime.Sub(collector.startTime)
+	for index, stage := range collector.stages {
+		if offset < stage.Start+stage.Duration {
+			return index, true
+		}
+	}
+	return len(collector.stages) - 1, true
+}
+
+// stageMetrics returns the StageMetrics of all the stages.
+func (collector *stageCollector) stageMetrics() []StageMetrics {
+	collector.lock.Lock()
+	defer collector.lock.Unlock()
+
+	if len(collector.stages) == 0 {
+		return nil
+	}
+	stages := make([]StageMetrics, 0, len(collector.stages))
+	for index, stage := range collector.stages {
+		stage.Latency = collector.latencies[index].metrics()
+		stage.CorrectedLatency = collector.correctedLatencies[index].metrics()
+		stages = append(stages, stage)
+	}
+	return stages
+}
@@ -0,0 +1,92 @@
+package report
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucketsTheLoadInStages(t *testing.T) {
+	startTime := time.Now()
+	collector := newStageCollector([]ReportStage{
+		{Name: "ramp", Duration: time.Second},
+		{Name: "hold", Duration: 2 * time.Second},
+	})
+	collector.start(startTime)
+
+	collector.addLoad(LoadGenerationResponse{LoadGenerationTime: startTime.Add(100 * time.Millisecond)})
+	collector.addLoad(LoadGenerationResponse{LoadGenerationTime: startTime.Add(1500 * time.Millisecond), Late: true})
+	collector.addLoad(LoadGenerationResponse{LoadGenerationTime: startTime.Add(2500 * time.Millisecond), Err: errors.New("test error")})
+	collector.addLoad(LoadGenerationResponse{LoadGenerationTime: startTime.Add(2600 * time.Millisecond), Dropped: true})
+
+	stages := collector.stageMetrics()
+	assert.Equal(t, 2, len(stages))
+
+	assert.Equal(t, "ramp", stages[0].Name)
+	assert.Equal(t, time.Duration(0), stages[0].Start)
+	assert.Equal(t, uint(1), stages[0].RequestsSent)
+
+	assert.Equal(t, "hold", stages[1].Name)
+	assert.Equal(t, time.Second, stages[1].Start)
+	assert.Equal(t, 2*time.Second, stages[1].Duration)
+	assert.Equal(t, uint(2), stages[1].RequestsSent)
+	assert.Equal(t, uint(1), stages[1].RequestErrors)
+	assert.Equal(t, uint(1), stages[1].LateRequests)
+	assert.Equal(t, uint(1), stages[1].DroppedRequests)
+}
+
+func TestBucketsTheResponsesInStagesWithLatency(t *testing.T) {
+	startTime := time.Now()
+	collector := newStageCollector([]ReportStage{
+		{Name: "ramp", Duration: time.Second},
+		{Name: "hold", Duration: time.Second},
+	})
+	collector.start(startTime)
+
+	collector.addResponse(SubjectServerResponse{
+		ResponseTime:     startTime.Add(600 * time.Millisecond),
+		Latency:          5 * time.Millisecond,
+		CorrectedLatency: 50 * time.Millisecond,
+		LatencyMeasured:  true,
+	})
+	collector.addResponse(SubjectServerResponse{
+		ResponseTime: startTime.Add(5 * time.Second),
+		Err:          errors.New("test error"),
+	})
+
+	stages := collector.stageMetrics()
+	assert.Equal(t, uint(1), stages[0].Responses)
+	assert.Equal(t, 5*time.Millisecond, stages[0].Latency.P99)
+	assert.Equal(t, 50*time.Millisecond, stages[0].CorrectedLatency.P99)
+
+	assert.Equal(t, uint(1), stages[1].Responses)
+	assert.Equal(t, uint(1), stages[1].ResponseErrors)
+	assert.Equal(t, uint(0), stages[1].Latency.TotalSamples)
+}
+
+func TestStageMetricsWithoutStages(t *testing.T) {
+	collector := newStageCollector(nil)
+	collector.start(time.Now())
+	collector.addLoad(LoadGenerationResponse{LoadGenerationTime: time.Now()})
+
+	assert.Nil(t, collector.stageMetrics())
+}
+
+func TestTheStagesStartAtTheStartTimeOfTheReporterOptions(t *testing.T) {
+	startTime := time.Now().Add(-time.Second)
+	loadGenerationChannel := make(chan LoadGenerationResponse, 1)
+	reporter := NewLoadGenerationMetricsCollectingReporterWithOptions(loadGenerationChannel, ReporterOptions{
+		Stages:    []ReportStage{{Name: "ramp", Duration: time.Second}, {Name: "hold", Duration: time.Second}},
+		StartTime: startTime,
+	})
+	reporter.Run()
+
+	loadGenerationChannel <- LoadGenerationResponse{LoadGenerationTime: startTime.Add(1500 * time.Millisecond)}
+	close(loadGenerationChannel)
+
+	stages := reporter.Report().Stages
+	assert.Equal(t, uint(0), stages[0].RequestsSent)
+	assert.Equal(t, uint(1), stages[1].RequestsSent)
+}
@@ -0,0 +1,106 @@
+package report
+
+import (
+	"sort"
+	"sync"
+)
+
+// TargetMetrics represents the metrics of a single target server, when the load is distributed across
+// multiple target servers.
+// RequestsSent includes both the successful and the failed requests, but not the dropped requests.
+// Responses includes both the successful and the failed responses.
+type TargetMetrics struct {
+	Target           string
+	RequestsSent     uint
+	RequestErrors    uint
+	PayloadBytes     int64
+	Responses        uint
+	ResponseErrors   uint
+	ErrorCountByType map[string]uint
+	Latency          LatencyMetrics
+}
+
+// targetCollector groups the LoadGenerationResponse and SubjectServerResponse by their target.
+// The events without a target (the run has a single target) are not grouped.
+// targetCollector is thread-safe, it is shared by the goroutines of the Reporter.
+type targetCollector struct {
+	lock      sync.Mutex
+	targets   map[string]*TargetMetrics
+	latencies map[string]*latencyRecorder
+}
+
+// newTargetCollector creates a new instance of targetCollector.
+func newTargetCollector() *targetCollector {
+	return &targetCollector{
+		targets:   make(map[string]*TargetMetrics),
+		latencies: make(map[string]*latencyRecorder),
+	}
+}
+
+// addLoad adds the LoadGenerationResponse to the metrics of its target.
+func (collector *targetCollector) addLoad(load LoadGenerationResponse) {
+	if load.Target == "" || load.Dropped {
+		return
+	}
+	collector.lock.Lock()
+	defer collector.lock.Unlock()
+
+	target := collector.targetOf(load.Target)
+	target.RequestsSent++
+	if load.Err != nil {
+		target.RequestErrors++
+		target.ErrorCountByType[load.Err.Error()]++
+	} else {
+		target.PayloadBytes += load.PayloadLengthBytes
+	}
+}
+
+// addResponse adds the SubjectServerResponse to the metrics of its target.
+func (collector *targetCollector) addResponse(response SubjectServerResponse) {
+	if response.Target == "" {
+		return
+	}
+	collector.lock.Lock()
+	defer collector.lock.Unlock()
+
+	target := collector.targetOf(response.Target)
+	target.Responses++
+	if response.Err != nil {
+		target.ResponseErrors++
+		target.ErrorCountByType[response.Err.Error()]++
+	}
+	if response.LatencyMeasured {
+		collector.latencies[response.Target].record(response.Latency)
+	}
+}
+
+// targetOf returns the TargetMetrics of the target, it expects the lock to be held.
+func (collector *targetCollector) targetOf(name string) *TargetMetrics {
+	target, ok := collector.targets[name]
+	if !ok {
+		target = &TargetMetrics{Target: name, ErrorCountByType: make(map[string]uint)}
+		collector.targets[name] = target
+		collector.latencies[name] = newLatencyRecorder()
+	}
+	return target
+}
+
+// targetMetrics returns the TargetMetrics of all the targets, sorted by the target.
+func (collector *targetCollector) targetMetrics() []TargetMetrics {
+	collector.lock.Lock()
+	defer collector.lock.Unlock()
+
+	if len(collector.targets) == 0 {
+		return nil
+	}
+	targets := make([]TargetMetrics, 0, len(collector.targets))
+	for name, target := range collector.targets {
+		metrics := *target
+		metrics.Latency = collector.latencies[name].metrics()
+		targets = append(targets, metrics)
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].Target < targets[j].Target
+	})
+	return targets
+}
@@ -0,0 +1,58 @@
+package report
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupsTheLoadByTarget(t *testing.T) {
+	collector := newTargetCollector()
+
+	collector.addLoad(LoadGenerationResponse{Target: "tcp://node2:8080", PayloadLengthBytes: 10})
+	collector.addLoad(LoadGenerationResponse{Target: "tcp://node1:8080", PayloadLengthBytes: 10})
+	collector.addLoad(LoadGenerationResponse{Target: "tcp://node1:8080", Err: errors.New("test error")})
+	collector.addLoad(LoadGenerationResponse{Target: "tcp://node1:8080", Dropped: true})
+
+	targets := collector.targetMetrics()
+	assert.Equal(t, 2, len(targets))
+
+	assert.Equal(t, "tcp://node1:8080", targets[0].Target)
+	assert.Equal(t, uint(2), targets[0].RequestsSent)
+	assert.Equal(t, uint(1), targets[0].RequestErrors)
+	assert.Equal(t, int64(10), targets[0].PayloadBytes)
+	assert.Equal(t, uint(1), targets[0].ErrorCountByType["test error"])
+
+	assert.Equal(t, "tcp://node2:8080", targets[1].Target)
+	assert.Equal(t, uint(1), targets[1].RequestsSent)
+	assert.Equal(t, uint(0), targets[1].RequestErrors)
+}
+
+func TestGroupsTheResponsesByTargetWithLatency(t *testing.T) {
+	collector := newTargetCollector()
+
+	collector.addResponse(SubjectServerResponse{Target: "tcp://node1:8080", Latency: 2 * time.Millisecond, LatencyMeasured: true})
+	collector.addResponse(SubjectServerResponse{Target: "tcp://node1:8080", Err: errors.New("test error")})
+	collector.addResponse(SubjectServerResponse{Target: "tcp://node2:8080"})
+
+	targets := collector.targetMetrics()
+	assert.Equal(t, 2, len(targets))
+
+	assert.Equal(t, uint(2), targets[0].Responses)
+	assert.Equal(t, uint(1), targets[0].ResponseErrors)
+	assert.Equal(t, uint(1), targets[0].Latency.TotalSamples)
+	assert.Equal(t, 2*time.Millisecond, targets[0].Latency.P99)
+
+	assert.Equal(t, uint(1), targets[1].Responses)
+	assert.Equal(t, uint(0), targets[1].Latency.TotalSamples)
+}
+
+func TestTargetMetricsWithASingleTarget(t *testing.T) {
+	collector := newTargetCollector()
+	collector.addLoad(LoadGenerationResponse{PayloadLengthBytes: 10})
+	collector.addResponse(SubjectServerResponse{})
+
+	assert.Nil(t, collector.targetMetrics())
+}
@@ -3,6 +3,8 @@ package report
 import (
 	"fmt"
 	"io"
+	"strings"
+	"text/tabwriter"
 	"text/template"
 	"time"
 
@@ -23,7 +25,28 @@ Summary:
     EarliestSuccessfulLoadSendTime: {{ formatTime .Load.EarliestSuccessfulLoadSendTime}}
     LatestSuccessfulLoadSendTime: {{ formatTime .Load.LatestSuccessfulLoadSendTime}}
     TimeToCompleteLoad: {{ formatDuration .Load.TotalTime }}
-
+{{ if or (gt .Load.DroppedCount 0) (gt .Load.LateCount 0) }}    DroppedCount: {{ formatNumberUint .Load.DroppedCount }}
+    LateCount: {{ formatNumberUint .Load.LateCount }}
+{{ end }}{{ if gt (len .Load.Connections) 0 }}    TotalReconnects: {{ formatNumberUint .Load.TotalReconnects }}
+  Connections:
+{{ formatConnections .Load.Connections }}
+{{ end }}{{ if gt .Load.Dial.TotalDials 0 }}  Dials:
+{{ if ne .Load.Dial.Network "" }}    Network: {{ .Load.Dial.Network }}
+{{ end }}    TotalDials: {{ formatNumberUint .Load.Dial.TotalDials }}
+    DialErrorCount: {{ formatNumberUint .Load.Dial.ErrorCount }}
+    p50: {{ formatDuration .Load.Dial.Latency.P50 }}
+    p99: {{ formatDuration .Load.Dial.Latency.P99 }}
+    max: {{ formatDuration .Load.Dial.Latency.Max }}
+{{ if gt .Load.Dial.TotalHandshakes 0 }}    TotalHandshakes: {{ formatNumberUint .Load.Dial.TotalHandshakes }}
+    ResumedHandshakes: {{ formatNumberUint .Load.Dial.ResumedHandshakes }}
+    Handshake p50: {{ formatDuration .Load.Dial.HandshakeLatency.P50 }}
+    Handshake p99: {{ formatDuration .Load.Dial.HandshakeLatency.P99 }}
+    Handshake max: {{ formatDuration .Load.Dial.HandshakeLatency.Max }}
+{{ end }}{{ if gt .Load.Dial.TotalInitializations 0 }}    TotalInitializations: {{ formatNumberUint .Load.Dial.TotalInitializations }}
+    Initialization p50: {{ formatDuration .Load.Dial.InitializationLatency.P50 }}
+    Initialization p99: {{ formatDuration .Load.Dial.InitializationLatency.P99 }}
+    Initialization max: {{ formatDuration .Load.Dial.InitializationLatency.Max }}
+{{ end }}{{ end }}
 {{ if gt (len .Load.ErrorCountByType) 0 }}  Error distribution:{{ range $err, $num := .Load.ErrorCountByType }}
   [{{ $num }}]   {{ $err }}{{ end }}{{ else }}  Error distribution:
   none{{ end }}
@@ -37,10 +60,36 @@ Summary:
     EarliestSuccessfulResponseReceivedTime: {{ formatTime .Response.EarliestSuccessfulResponseReceivedTime }}
     LatestSuccessfulResponseReceivedTime: {{ formatTime .Response.LatestSuccessfulResponseReceivedTime }}
     TimeToGetResponses: {{ formatDuration .Response.TotalTime }}
-  
+{{ if gt .Response.Latency.TotalSamples 0 }}
+  Latency:
+    TotalSamples: {{ formatNumberUint .Response.Latency.TotalSamples }}
+    p50: {{ formatDuration .Response.Latency.P50 }}
+    p90: {{ formatDuration .Response.Latency.P90 }}
+    p95: {{ formatDuration .Response.Latency.P95 }}
+    p99: {{ formatDuration .Response.Latency.P99 }}
+    p99.9: {{ formatDuration .Response.Latency.P999 }}
+    max: {{ formatDuration .Response.Latency.Max }}
+
+  CorrectedLatency (from the intended send time):
+    p50: {{ formatDuration .Response.CorrectedLatency.P50 }}
+    p90: {{ formatDuration .Response.CorrectedLatency.P90 }}
+    p95: {{ formatDuration .Response.CorrectedLatency.P95 }}
+    p99: {{ formatDuration .Response.CorrectedLatency.P99 }}
+    p99.9: {{ formatDuration .Response.CorrectedLatency.P999 }}
+    max: {{ formatDuration .Response.CorrectedLatency.Max }}
+{{ end }}  
 {{ if gt (len .Response.ErrorCountByType) 0 }}  Error distribution:{{ range $err, $num := .Response.ErrorCountByType }} 
   [{{ $num }}]   {{ $err }}{{ end }}{{ else }}  Error distribution:
-  none{{ end }}{{ end }}
+  none{{ end }}{{ end }}{{ if gt (len .TimeSeries.Windows) 0 }}
+
+  TimeSeries (window: {{ formatDuration .TimeSeries.WindowSize }}):
+{{ formatTimeSeries .TimeSeries }}{{ end }}{{ if gt (len .Stages) 0 }}
+
+  Stages:
+{{ formatStages .Stages }}{{ end }}{{ if gt (len .Targets) 0 }}
+
+  Targets:
+{{ formatTargets .Targets }}{{ end }}
 `
 
 var functions = template.FuncMap{
@@ -49,6 +98,10 @@ var functions = template.FuncMap{
 	"formatTime":          formatTime,
 	"formatDuration":      formatDuration,
 	"humanizePayloadSize": humanizePayloadSize,
+	"formatTimeSeries":    formatTimeSeries,
+	"formatStages":        formatStages,
+	"formatConnections":   formatConnections,
+	"formatTargets":       formatTargets,
 }
 
 const timeFormat = "January 02, 2006 15:04:05 MST"
@@ -81,6 +134,120 @@ func formatDuration(duration time.Duration) string {
 	return duration.String()
 }
 
+// formatTimeSeries returns the windows of the TimeSeries as an aligned table, one row per window.
+// Latency columns are NA if the latency was not measured in the window.
+func formatTimeSeries(timeSeries TimeSeries) string {
+	builder := &strings.Builder{}
+	tableWriter := tabwriter.NewWriter(builder, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tableWriter, "    Window\tSent\tErrors\tPayloadSize\tResponses\tResponseErrors\tResponsePayloadSize\tp50\tp99")
+	for _, window := range timeSeries.Windows {
+		p50, p99 := "NA", "NA"
+		if window.Latency.TotalSamples > 0 {
+			p50, p99 = formatDuration(window.Latency.P50), formatDuration(window.Latency.P99)
+		}
+		_, _ = fmt.Fprintf(
+			tableWriter,
+			"    %v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n",
+			formatDuration(window.Start),
+			window.RequestsSent,
+			window.RequestErrors,
+			humanizePayloadSize(window.PayloadLengthBytes),
+			window.Responses,
+			window.ResponseErrors,
+			humanizePayloadSize(window.ResponsePayloadLengthBytes),
+			p50,
+			p99,
+		)
+	}
+	_ = tableWriter.Flush()
+	return strings.TrimSuffix(builder.String(), "\n")
+}
+
+// formatStages returns the StageMetrics as an aligned table, one row per stage.
+// Latency columns are NA if the latency was not measured in the stage.
+func formatStages(stages []StageMetrics) string {
+	builder := &strings.Builder{}
+	tableWriter := tabwriter.NewWriter(builder, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(
+		tableWriter,
+		"    Stage\tStart\tDuration\tSent\tErrors\tDropped\tLate\tResponses\tResponseErrors\tp50\tp99\tCorrectedP99",
+	)
+	for _, stage := range stages {
+		p50, p99, correctedP99 := "NA", "NA", "NA"
+		if stage.Latency.TotalSamples > 0 {
+			p50, p99 = formatDuration(stage.Latency.P50), formatDuration(stage.Latency.P99)
+			correctedP99 = formatDuration(stage.CorrectedLatency.P99)
+		}
+		_, _ = fmt.Fprintf(
+			tableWriter,
+			"    %v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n",
+			stage.Name,
+			formatDuration(stage.Start),
+			formatDuration(stage.Duration),
+			stage.RequestsSent,
+			stage.RequestErrors,
+			stage.DroppedRequests,
+			stage.LateRequests,
+			stage.Responses,
+			stage.ResponseErrors,
+			p50,
+			p99,
+			correctedP99,
+		)
+	}
+	_ = tableWriter.Flush()
+	return strings.TrimSuffix(builder.String(), "\n")
+}
+
+// formatTargets returns the TargetMetrics as an aligned table, one row per target.
+// Latency columns are NA if the latency was not measured for the target.
+func formatTargets(targets []TargetMetrics) string {
+	builder := &strings.Builder{}
+	tableWriter := tabwriter.NewWriter(builder, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(
+		tableWriter,
+		"    Target\tSent\tErrors\tPayloadSize\tResponses\tResponseErrors\tp50\tp99",
+	)
+	for _, target := range targets {
+		p50, p99 := "NA", "NA"
+		if target.Latency.TotalSamples > 0 {
+			p50, p99 = formatDuration(target.Latency.P50), formatDuration(target.Latency.P99)
+		}
+		_, _ = fmt.Fprintf(
+			tableWriter,
+			"    %v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n",
+			target.Target,
+			target.RequestsSent,
+			target.RequestErrors,
+			humanizePayloadSize(target.PayloadBytes),
+			target.Responses,
+			target.ResponseErrors,
+			p50,
+			p99,
+		)
+	}
+	_ = tableWriter.Flush()
+	return strings.TrimSuffix(builder.String(), "\n")
+}
+
+// formatConnections returns the ConnectionMetrics as an aligned table, one row per connection.
+func formatConnections(connections []ConnectionMetrics) string {
+	builder := &strings.Builder{}
+	tableWriter := tabwriter.NewWriter(builder, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tableWriter, "    ConnectionId\tReconnects\tDowntime")
+	for _, connection := range connections {
+		_, _ = fmt.Fprintf(
+			tableWriter,
+			"    %v\t%v\t%v\n",
+			connection.ConnectionId,
+			connection.Reconnects,
+			formatDuration(connection.Downtime),
+		)
+	}
+	_ = tableWriter.Flush()
+	return strings.TrimSuffix(builder.String(), "\n")
+}
+
 // write writes the report to the given writer.
 func write(writer io.Writer, report *Report) error {
 	return newTemplate().Execute(writer, report)
@@ -249,3 +249,467 @@ Summary:
 	assert.Nil(t, err)
 	assert.Equal(t, strings.Trim(expected, " "), strings.Trim(string(buffer.Bytes()), " "))
 }
+
+func TestPrintsTheReportWithLatency(t *testing.T) {
+	expected := `
+Summary:
+  LoadMetrics:
+    TotalConnections: 1
+    TotalRequests: 1000
+    SuccessCount: 1000
+    ErrorCount: 0
+    TotalPayloadSize: 2.0 kB
+    AveragePayloadSize: 20 B
+    EarliestSuccessfulLoadSendTime: NA
+    LatestSuccessfulLoadSendTime: NA
+    TimeToCompleteLoad: 0s
+
+  Error distribution:
+  none
+  
+  ResponseMetrics:
+    TotalResponses: 1000
+    SuccessCount: 1000
+    ErrorCount: 0
+    TotalResponsePayloadSize: 1.8 kB
+    AverageResponsePayloadSize: 18 B 
+    EarliestSuccessfulResponseReceivedTime: NA
+    LatestSuccessfulResponseReceivedTime: NA
+    TimeToGetResponses: 0s
+
+  Latency:
+    TotalSamples: 1000
+    p50: 2ms
+    p90: 5ms
+    p95: 7ms
+    p99: 10ms
+    p99.9: 15ms
+    max: 20ms
+
+  CorrectedLatency (from the intended send time):
+    p50: 3ms
+    p90: 8ms
+    p95: 1s
+    p99: 1.5s
+    p99.9: 2s
+    max: 2s
+  
+  Error distribution:
+  none
+`
+	report := &Report{
+		Load: LoadMetrics{
+			TotalConnections:          1,
+			TotalRequests:             1000,
+			SuccessCount:              1000,
+			ErrorCountByType:          make(map[string]uint),
+			TotalPayloadLengthBytes:   2000,
+			AveragePayloadLengthBytes: 20.0,
+		},
+		Response: ResponseMetrics{
+			TotalResponses:                    1000,
+			SuccessCount:                      1000,
+			ErrorCountByType:                  make(map[string]uint),
+			TotalResponsePayloadLengthBytes:   1800,
+			AverageResponsePayloadLengthBytes: 18.0,
+			IsAvailableForReporting:           true,
+			Latency: LatencyMetrics{
+				TotalSamples: 1000,
+				P50:          2 * time.Millisecond,
+				P90:          5 * time.Millisecond,
+				P95:          7 * time.Millisecond,
+				P99:          10 * time.Millisecond,
+				P999:         15 * time.Millisecond,
+				Max:          20 * time.Millisecond,
+			},
+			CorrectedLatency: LatencyMetrics{
+				TotalSamples: 1000,
+				P50:          3 * time.Millisecond,
+				P90:          8 * time.Millisecond,
+				P95:          time.Second,
+				P99:          1500 * time.Millisecond,
+				P999:         2 * time.Second,
+				Max:          2 * time.Second,
+			},
+		},
+	}
+
+	buffer := &bytes.Buffer{}
+	err := write(buffer, report)
+
+	assert.Nil(t, err)
+	assert.Equal(t, strings.Trim(expected, " "), strings.Trim(string(buffer.Bytes()), " "))
+}
+
+func TestPrintsTheReportWithTimeSeries(t *testing.T) {
+	expected := `
+Summary:
+  LoadMetrics:
+    TotalConnections: 1
+    TotalRequests: 3
+    SuccessCount: 2
+    ErrorCount: 1
+    TotalPayloadSize: 20 B
+    AveragePayloadSize: 10 B
+    EarliestSuccessfulLoadSendTime: NA
+    LatestSuccessfulLoadSendTime: NA
+    TimeToCompleteLoad: 0s
+
+  Error distribution:
+  [1]   load error
+
+
+  TimeSeries (window: 1s):
+    Window  Sent  Errors  PayloadSize  Responses  ResponseErrors  ResponsePayloadSize  p50  p99
+    0s      2     0       20 B         0          0               0 B                  1ms  2ms
+    1s      1     1       0 B          0          0               0 B                  NA   NA
+`
+	report := &Report{
+		Load: LoadMetrics{
+			TotalConnections:          1,
+			TotalRequests:             3,
+			SuccessCount:              2,
+			ErrorCount:                1,
+			ErrorCountByType:          map[string]uint{"load error": 1},
+			TotalPayloadLengthBytes:   20,
+			AveragePayloadLengthBytes: 10,
+		},
+		TimeSeries: TimeSeries{
+			WindowSize: time.Second,
+			Windows: []TimeSeriesWindow{
+				{
+					Start:              0,
+					RequestsSent:       2,
+					PayloadLengthBytes: 20,
+					Latency:            LatencyMetrics{TotalSamples: 2, P50: time.Millisecond, P99: 2 * time.Millisecond},
+				},
+				{
+					Start:         time.Second,
+					RequestsSent:  1,
+					RequestErrors: 1,
+				},
+			},
+		},
+	}
+
+	buffer := &bytes.Buffer{}
+	err := write(buffer, report)
+
+	assert.Nil(t, err)
+	assert.Equal(t, strings.Trim(expected, " "), strings.Trim(string(buffer.Bytes()), " "))
+}
+
+func TestPrintsTheReportWithStages(t *testing.T) {
+	expected := `
+Summary:
+  LoadMetrics:
+    TotalConnections: 1
+    TotalRequests: 3
+    SuccessCount: 3
+    ErrorCount: 0
+    TotalPayloadSize: 30 B
+    AveragePayloadSize: 10 B
+    EarliestSuccessfulLoadSendTime: NA
+    LatestSuccessfulLoadSendTime: NA
+    TimeToCompleteLoad: 0s
+
+  Error distribution:
+  none
+
+
+  Stages:
+    Stage    Start  Duration  Sent  Errors  Dropped  Late  Responses  ResponseErrors  p50  p99  CorrectedP99
+    ramp     0s     1s        1     0       0        0     0          0               1ms  2ms  5ms
+    1s:10@2  1s     1s        2     0       1        1     0          0               NA   NA   NA
+`
+	report := &Report{
+		Load: LoadMetrics{
+			TotalConnections:          1,
+			TotalRequests:             3,
+			SuccessCount:              3,
+			ErrorCountByType:          make(map[string]uint),
+			TotalPayloadLengthBytes:   30,
+			AveragePayloadLengthBytes: 10,
+		},
+		Stages: []StageMetrics{
+			{
+				Name:             "ramp",
+				Duration:         time.Second,
+				RequestsSent:     1,
+				Latency:          LatencyMetrics{TotalSamples: 1, P50: time.Millisecond, P99: 2 * time.Millisecond},
+				CorrectedLatency: LatencyMetrics{TotalSamples: 1, P50: time.Millisecond, P99: 5 * time.Millisecond},
+			},
+			{
+				Name:            "1s:10@2",
+				Start:           time.Second,
+				Duration:        time.Second,
+				RequestsSent:    2,
+				DroppedRequests: 1,
+				LateRequests:    1,
+			},
+		},
+	}
+
+	buffer := &bytes.Buffer{}
+	err := write(buffer, report)
+
+	assert.Nil(t, err)
+	assert.Equal(t, strings.Trim(expected, " "), strings.Trim(string(buffer.Bytes()), " "))
+}
+
+func TestPrintsTheReportWithConnections(t *testing.T) {
+	expected := `
+Summary:
+  LoadMetrics:
+    TotalConnections: 2
+    TotalRequests: 3
+    SuccessCount: 3
+    ErrorCount: 0
+    TotalPayloadSize: 30 B
+    AveragePayloadSize: 10 B
+    EarliestSuccessfulLoadSendTime: NA
+    LatestSuccessfulLoadSendTime: NA
+    TimeToCompleteLoad: 0s
+    TotalReconnects: 3
+  Connections:
+    ConnectionId  Reconnects  Downtime
+    0             1           15ms
+    1             2           1.5s
+
+  Error distribution:
+  none
+
+`
+	report := &Report{
+		Load: LoadMetrics{
+			TotalConnections:          2,
+			TotalRequests:             3,
+			SuccessCount:              3,
+			ErrorCountByType:          make(map[string]uint),
+			TotalPayloadLengthBytes:   30,
+			AveragePayloadLengthBytes: 10,
+			TotalReconnects:           3,
+			Connections: []ConnectionMetrics{
+				{ConnectionId: 0, Reconnects: 1, Downtime: 15 * time.Millisecond},
+				{ConnectionId: 1, Reconnects: 2, Downtime: 1500 * time.Millisecond},
+			},
+		},
+	}
+
+	buffer := &bytes.Buffer{}
+	err := write(buffer, report)
+
+	assert.Nil(t, err)
+	assert.Equal(t, strings.Trim(expected, " "), strings.Trim(string(buffer.Bytes()), " "))
+}
+
+func TestPrintsTheReportWithDials(t *testing.T) {
+	expected := `
+Summary:
+  LoadMetrics:
+    TotalConnections: 1
+    TotalRequests: 3
+    SuccessCount: 3
+    ErrorCount: 0
+    TotalPayloadSize: 30 B
+    AveragePayloadSize: 10 B
+    EarliestSuccessfulLoadSendTime: NA
+    LatestSuccessfulLoadSendTime: NA
+    TimeToCompleteLoad: 0s
+  Dials:
+    TotalDials: 4
+    DialErrorCount: 1
+    p50: 1ms
+    p99: 3ms
+    max: 3ms
+
+  Error distribution:
+  none
+
+`
+	report := &Report{
+		Load: LoadMetrics{
+			TotalConnections:          1,
+			TotalRequests:             3,
+			SuccessCount:              3,
+			ErrorCountByType:          make(map[string]uint),
+			TotalPayloadLengthBytes:   30,
+			AveragePayloadLengthBytes: 10,
+			Dial: DialMetrics{
+				TotalDials: 4,
+				ErrorCount: 1,
+				Latency:    LatencyMetrics{TotalSamples: 4, P50: time.Millisecond, P99: 3 * time.Millisecond, Max: 3 * time.Millisecond},
+			},
+		},
+	}
+
+	buffer := &bytes.Buffer{}
+	err := write(buffer, report)
+
+	assert.Nil(t, err)
+	assert.Equal(t, strings.Trim(expected, " "), strings.Trim(string(buffer.Bytes()), " "))
+}
+
+func TestPrintsTheReportWithDialsAndHandshakes(t *testing.T) {
+	expected := `
+Summary:
+  LoadMetrics:
+    TotalConnections: 1
+    TotalRequests: 3
+    SuccessCount: 3
+    ErrorCount: 0
+    TotalPayloadSize: 30 B
+    AveragePayloadSize: 10 B
+    EarliestSuccessfulLoadSendTime: NA
+    LatestSuccessfulLoadSendTime: NA
+    TimeToCompleteLoad: 0s
+  Dials:
+    Network: tcp
+    TotalDials: 2
+    DialErrorCount: 0
+    p50: 1ms
+    p99: 1ms
+    max: 1ms
+    TotalHandshakes: 2
+    ResumedHandshakes: 1
+    Handshake p50: 2ms
+    Handshake p99: 5ms
+    Handshake max: 5ms
+
+  Error distribution:
+  none
+
+`
+	report := &Report{
+		Load: LoadMetrics{
+			TotalConnections:          1,
+			TotalRequests:             3,
+			SuccessCount:              3,
+			ErrorCountByType:          make(map[string]uint),
+			TotalPayloadLengthBytes:   30,
+			AveragePayloadLengthBytes: 10,
+			Dial: DialMetrics{
+				Network:           "tcp",
+				TotalDials:        2,
+				Latency:           LatencyMetrics{TotalSamples: 2, P50: time.Millisecond, P99: time.Millisecond, Max: time.Millisecond},
+				TotalHandshakes:   2,
+				ResumedHandshakes: 1,
+				HandshakeLatency:  LatencyMetrics{TotalSamples: 2, P50: 2 * time.Millisecond, P99: 5 * time.Millisecond, Max: 5 * time.Millisecond},
+			},
+		},
+	}
+
+	buffer := &bytes.Buffer{}
+	err := write(buffer, report)
+
+	assert.Nil(t, err)
+	assert.Equal(t, strings.Trim(expected, " "), strings.Trim(string(buffer.Bytes()), " "))
+}
+
+func TestPrintsTheReportWithDialsAndInitializations(t *testing.T) {
+	expected := `
+Summary:
+  LoadMetrics:
+    TotalConnections: 1
+    TotalRequests: 3
+    SuccessCount: 3
+    ErrorCount: 0
+    TotalPayloadSize: 30 B
+    AveragePayloadSize: 10 B
+    EarliestSuccessfulLoadSendTime: NA
+    LatestSuccessfulLoadSendTime: NA
+    TimeToCompleteLoad: 0s
+  Dials:
+    TotalDials: 2
+    DialErrorCount: 1
+    p50: 1ms
+    p99: 1ms
+    max: 1ms
+    TotalInitializations: 2
+    Initialization p50: 2ms
+    Initialization p99: 4ms
+    Initialization max: 4ms
+
+  Error distribution:
+  none
+
+`
+	report := &Report{
+		Load: LoadMetrics{
+			TotalConnections:          1,
+			TotalRequests:             3,
+			SuccessCount:              3,
+			ErrorCountByType:          make(map[string]uint),
+			TotalPayloadLengthBytes:   30,
+			AveragePayloadLengthBytes: 10,
+			Dial: DialMetrics{
+				TotalDials:            2,
+				ErrorCount:            1,
+				Latency:               LatencyMetrics{TotalSamples: 2, P50: time.Millisecond, P99: time.Millisecond, Max: time.Millisecond},
+				TotalInitializations:  2,
+				InitializationLatency: LatencyMetrics{TotalSamples: 2, P50: 2 * time.Millisecond, P99: 4 * time.Millisecond, Max: 4 * time.Millisecond},
+			},
+		},
+	}
+
+	buffer := &bytes.Buffer{}
+	err := write(buffer, report)
+
+	assert.Nil(t, err)
+	assert.Equal(t, strings.Trim(expected, " "), strings.Trim(string(buffer.Bytes()), " "))
+}
+
+func TestPrintsTheReportWithTargets(t *testing.T) {
+	expected := `
+Summary:
+  LoadMetrics:
+    TotalConnections: 2
+    TotalRequests: 3
+    SuccessCount: 3
+    ErrorCount: 0
+    TotalPayloadSize: 30 B
+    AveragePayloadSize: 10 B
+    EarliestSuccessfulLoadSendTime: NA
+    LatestSuccessfulLoadSendTime: NA
+    TimeToCompleteLoad: 0s
+
+  Error distribution:
+  none
+
+
+  Targets:
+    Target            Sent  Errors  PayloadSize  Responses  ResponseErrors  p50  p99
+    tcp://node1:8080  2     0       20 B         2          0               1ms  2ms
+    tcp://node2:8080  1     0       10 B         0          0               NA   NA
+`
+	report := &Report{
+		Load: LoadMetrics{
+			TotalConnections:          2,
+			TotalRequests:             3,
+			SuccessCount:              3,
+			ErrorCountByType:          make(map[string]uint),
+			TotalPayloadLengthBytes:   30,
+			AveragePayloadLengthBytes: 10,
+		},
+		Targets: []TargetMetrics{
+			{
+				Target:       "tcp://node1:8080",
+				RequestsSent: 2,
+				PayloadBytes: 20,
+				Responses:    2,
+				Latency:      LatencyMetrics{TotalSamples: 2, P50: time.Millisecond, P99: 2 * time.Millisecond},
+			},
+			{
+				Target:       "tcp://node2:8080",
+				RequestsSent: 1,
+				PayloadBytes: 10,
+			},
+		},
+	}
+
+	buffer := &bytes.Buffer{}
+	err := write(buffer, report)
+
+	assert.Nil(t, err)
+	assert.Equal(t, strings.Trim(expected, " "), strings.Trim(string(buffer.Bytes()), " "))
+}
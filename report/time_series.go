@@ -0,0 +1,168 @@
+package report
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultTimeSeriesWindow is the default size of each window in the TimeSeries.
+const DefaultTimeSeriesWindow = time.Second
+
+// TimeSeries represents the metrics of the run bucketed in windows of equal size (WindowSize).
+// Windows are contiguous: Windows[i] covers the time from StartTime + i*WindowSize till
+// StartTime + (i+1)*WindowSize, even if nothing happened in that window.
+type TimeSeries struct {
+	StartTime  time.Time
+	WindowSize time.Duration
+	Windows    []TimeSeriesWindow
+}
+
+// TimeSeriesWindow represents the metrics of a single window in the TimeSeries.
+// Start is the offset of the window from the start of the run.
+// RequestsSent includes both the successful and the failed requests, and
+// Responses includes both the successful and the failed responses.
+type TimeSeriesWindow struct {
+	Start                      time.Duration
+	RequestsSent               uint
+	RequestErrors              uint
+	PayloadLengthBytes         int64
+	Responses                  uint
+	ResponseErrors             uint
+	ResponsePayloadLengthBytes int64
+	Latency                    LatencyMetrics
+}
+
+// timeSeriesCollector buckets the LoadGenerationResponse and SubjectServerResponse in windows.
+// timeSeriesCollector is thread-safe, it is shared by the goroutines of the Reporter.
+type timeSeriesCollector struct {
+	lock       sync.Mutex
+	startTime  time.Time
+	windowSize time.Duration
+	windows    map[int64]*TimeSeriesWindow
+	latencies  map[int64]*latencyRecorder
+}
+
+// newTimeSeriesCollector creates a new instance of timeSeriesCollector.
+func newTimeSeriesCollector(windowSize time.Duration) *timeSeriesCollector {
+	if windowSize <= 0 {
+		windowSize = DefaultTimeSeriesWindow
+	}
+	return &timeSeriesCollector{
+		windowSize: windowSize,
+		windows:    make(map[int64]*TimeSeriesWindow),
+		latencies:  make(map[int64]*latencyRecorder),
+	}
+}
+
+// start sets the start time of the run.
+func (collector *timeSeriesCollector) start(startTime time.Time) {
+	collector.lock.Lock()
+	defer collector.lock.Unlock()
+
+	collector.startTime = startTime
+}
+
+// addLoad adds the LoadGenerationResponse to the window that contains its LoadGenerationTime.
+func (collector *timeSeriesCollector) addLoad(load LoadGenerationResponse) {
+	collector.lock.Lock()
+	defer collector.lock.Unlock()
+
+	window, ok := collector.windowAt(load.LoadGenerationTime)
+	if !ok {
+		return
+	}
+	window.RequestsSent++
+	if load.Err != nil {
+		window.RequestErrors++
+	} else {
+		window.PayloadLengthBytes += load.PayloadLengthBytes
+	}
+}
+
+// addResponse adds the SubjectServerResponse to the window that contains its ResponseTime.
+// It returns true if the latency of the response is recorded in the window, the latencies of all the windows
+// are then available (merged) from latency.
+func (collector *timeSeriesCollector) addResponse(response SubjectServerResponse) bool {
+	collector.lock.Lock()
+	defer collector.lock.Unlock()
+
+	window, ok := collector.windowAt(response.ResponseTime)
+	if !ok {
+		return false
+	}
+	window.Responses++
+	if response.Err != nil {
+		window.ResponseErrors++
+	} else {
+		window.ResponsePayloadLengthBytes += response.PayloadLengthBytes
+	}
+	if response.LatencyMeasured {
+		collector.latencies[collector.indexOf(response.ResponseTime)].record(response.Latency)
+	}
+	return response.LatencyMeasured
+}
+
+// latency returns the latencies of all the windows, merged in a single latencyRecorder.
+func (collector *timeSeriesCollector) latency() *latencyRecorder {
+	collector.lock.Lock()
+	defer collector.lock.Unlock()
+
+	recorder := newLatencyRecorder()
+	for _, windowLatencies := range collector.latencies {
+		recorder.merge(windowLatencies)
+	}
+	return recorder
+}
+
+// windowAt returns the window that contains the given time.
+// Events without time are not bucketed, and events before the start time belong to the first window.
+func (collector *timeSeriesCollector) windowAt(eventTime time.Time) (*TimeSeriesWindow, bool) {
+	if eventTime.IsZero() || collector.startTime.IsZero() {
+		return nil, false
+	}
+	index := collector.indexOf(eventTime)
+	window, ok := collector.windows[index]
+	if !ok {
+		window = &TimeSeriesWindow{Start: time.Duration(index) * collector.windowSize}
+		collector.windows[index] = window
+		collector.latencies[index] = newLatencyRecorder()
+	}
+	return window, true
+}
+
+// indexOf returns the index of the window that contains the given time.
+func (collector *timeSeriesCollector) indexOf(eventTime time.Time) int64 {
+	index := int64(eventTime.Sub(collector.startTime) / collector.windowSize)
+	if index < 0 {
+		return 0
+	}
+	return index
+}
+
+// timeSeries returns the TimeSeries with contiguous windows.
+func (collector *timeSeriesCollector) timeSeries() TimeSeries {
+	collector.lock.Lock()
+	defer collector.lock.Unlock()
+
+	lastIndex := int64(-1)
+	for index := range collector.windows {
+		if index > lastIndex {
+			lastIndex = index
+		}
+	}
+	windows := make([]TimeSeriesWindow, 0, lastIndex+1)
+	for index := int64(0); index <= lastIndex; index++ {
+		window, ok := collector.windows[index]
+		if !ok {
+			windows = append(windows, TimeSeriesWindow{Start: time.Duration(index) * collector.windowSize})
+			continue
+		}
+		window.Latency = collector.latencies[index].metrics()
+		windows = append(windows, *window)
+	}
+	return TimeSeries{
+		StartTime:  collector.startTime,
+		WindowSize: collector.windowSize,
+		Windows:    windows,
+	}
+}
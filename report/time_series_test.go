@@ -0,0 +1,88 @@
+package report
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBucketsTheLoadInWindows(t *testing.T) {
+	startTime := time.Now()
+	collector := newTimeSeriesCollector(time.Second)
+	collector.start(startTime)
+
+	collector.addLoad(LoadGenerationResponse{LoadGenerationTime: startTime.Add(100 * time.Millisecond), PayloadLengthBytes: 10})
+	collector.addLoad(LoadGenerationResponse{LoadGenerationTime: startTime.Add(900 * time.Millisecond), PayloadLengthBytes: 10})
+	collector.addLoad(LoadGenerationResponse{LoadGenerationTime: startTime.Add(1500 * time.Millisecond), Err: errors.New("test error")})
+
+	timeSeries := collector.timeSeries()
+	assert.Equal(t, time.Second, timeSeries.WindowSize)
+	assert.Equal(t, 2, len(timeSeries.Windows))
+
+	assert.Equal(t, time.Duration(0), timeSeries.Windows[0].Start)
+	assert.Equal(t, uint(2), timeSeries.Windows[0].RequestsSent)
+	assert.Equal(t, uint(0), timeSeries.Windows[0].RequestErrors)
+	assert.Equal(t, int64(20), timeSeries.Windows[0].PayloadLengthBytes)
+
+	assert.Equal(t, time.Second, timeSeries.Windows[1].Start)
+	assert.Equal(t, uint(1), timeSeries.Windows[1].RequestsSent)
+	assert.Equal(t, uint(1), timeSeries.Windows[1].RequestErrors)
+}
+
+func TestBucketsTheResponsesInWindowsWithLatency(t *testing.T) {
+	startTime := time.Now()
+	collector := newTimeSeriesCollector(500 * time.Millisecond)
+	collector.start(startTime)
+
+	collector.addResponse(SubjectServerResponse{
+		ResponseTime:       startTime.Add(600 * time.Millisecond),
+		PayloadLengthBytes: 10,
+		Latency:            5 * time.Millisecond,
+		LatencyMeasured:    true,
+	})
+	collector.addResponse(SubjectServerResponse{
+		ResponseTime: startTime.Add(700 * time.Millisecond),
+		Err:          errors.New("test error"),
+	})
+
+	timeSeries := collector.timeSeries()
+	assert.Equal(t, 2, len(timeSeries.Windows))
+	assert.Equal(t, uint(0), timeSeries.Windows[0].Responses)
+
+	assert.Equal(t, 500*time.Millisecond, timeSeries.Windows[1].Start)
+	assert.Equal(t, uint(2), timeSeries.Windows[1].Responses)
+	assert.Equal(t, uint(1), timeSeries.Windows[1].ResponseErrors)
+	assert.Equal(t, int64(10), timeSeries.Windows[1].ResponsePayloadLengthBytes)
+	assert.Equal(t, uint(1), timeSeries.Windows[1].Latency.TotalSamples)
+	assert.Equal(t, 5*time.Millisecond, timeSeries.Windows[1].Latency.P99)
+}
+
+func TestTimeSeriesHasContiguousWindows(t *testing.T) {
+	startTime := time.Now()
+	collector := newTimeSeriesCollector(time.Second)
+	collector.start(startTime)
+
+	collector.addLoad(LoadGenerationResponse{LoadGenerationTime: startTime.Add(3500 * time.Millisecond)})
+
+	timeSeries := collector.timeSeries()
+	assert.Equal(t, 4, len(timeSeries.Windows))
+	assert.Equal(t, 2*time.Second, timeSeries.Windows[2].Start)
+	assert.Equal(t, uint(0), timeSeries.Windows[2].RequestsSent)
+	assert.Equal(t, uint(1), timeSeries.Windows[3].RequestsSent)
+}
+
+func TestTimeSeriesIgnoresEventsWithoutTime(t *testing.T) {
+	collector := newTimeSeriesCollector(time.Second)
+	collector.start(time.Now())
+
+	collector.addLoad(LoadGenerationResponse{})
+
+	assert.Equal(t, 0, len(collector.timeSeries().Windows))
+}
+
+func TestTimeSeriesWithDefaultWindow(t *testing.T) {
+	collector := newTimeSeriesCollector(0)
+	assert.Equal(t, DefaultTimeSeriesWindow, collector.timeSeries().WindowSize)
+}
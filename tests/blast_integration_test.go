@@ -2,16 +2,26 @@ package tests
 
 import (
 	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
 	blast "github.com/SarthakMakhija/blast-core/cmd"
 	"github.com/SarthakMakhija/blast-core/payload"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"os"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/SarthakMakhija/blast-core/report"
 	"github.com/SarthakMakhija/blast-core/workers"
 )
 
@@ -83,6 +93,18 @@ func TestBlastWithLoadGenerationForMaximumDuration(t *testing.T) {
 	assert.True(t, totalRequestsMade > 1)
 }
 
+func TestBlastWithResponseReadingPanicsWithoutAResponseFramerOrSize(t *testing.T) {
+	groupOptions := workers.NewGroupOptions(
+		1,
+		payload.NewConstantPayloadGenerator([]byte("HelloWorld")),
+		"localhost:10099",
+		time.Second,
+	)
+	assert.Panics(t, func() {
+		blast.NewBlastWithResponseReading(groupOptions, blast.ResponseOptions{TotalResponsesToRead: 1}, false)
+	})
+}
+
 func TestBlastWithLoadGenerationAndResponseReading(t *testing.T) {
 	payloadSizeBytes := int64(10)
 	server, err := NewEchoServer("tcp", "localhost:10003", payloadSizeBytes)
@@ -384,3 +406,543 @@ func extract(textToFind string, regularExpression *regexp.Regexp, buffer []byte)
 	))
 	return asInt
 }
+
+type RequestIdPayloadGenerator struct{}
+
+func (generator RequestIdPayloadGenerator) Generate(requestId uint64) []byte {
+	payload := make([]byte, 8)
+	binary.BigEndian.PutUint64(payload, requestId)
+	return payload
+}
+
+func TestBlastWithLoadGenerationAndResponseReadingWithLatency(t *testing.T) {
+	payloadSizeBytes := int64(8)
+	server, err := NewEchoServer("tcp", "localhost:10010", payloadSizeBytes)
+	assert.Nil(t, err)
+
+	server.accept(t)
+	defer server.stop()
+
+	concurrency, totalRequests := uint(1), uint(20)
+	groupOptions := workers.NewGroupOptionsFullyLoaded(
+		concurrency,
+		1,
+		RequestIdPayloadGenerator{},
+		"localhost:10010",
+		3*time.Second,
+		100,
+		2*time.Second,
+	)
+	responseOptions := blast.ResponseOptions{
+		ResponsePayloadSizeBytes: payloadSizeBytes,
+		TotalResponsesToRead:     totalRequests,
+		ReadingOption:            blast.ReadTotalResponses,
+		ReadDeadline:             100 * time.Millisecond,
+		RequestIdExtractor:       report.NewOffsetRequestIdExtractor(0, binary.BigEndian),
+	}
+	buffer := &bytes.Buffer{}
+	blast.OutputStream = buffer
+
+	blastInstance := blast.NewBlastWithResponseReading(groupOptions, responseOptions, false)
+	blastInstance.WaitForCompletion()
+
+	output := string(buffer.Bytes())
+	assert.True(t, strings.Contains(output, "Latency"))
+	assert.True(t, strings.Contains(output, "p99.9"))
+	assert.True(t, extract("TotalSamples:", regexp.MustCompile("TotalSamples.*"), buffer.Bytes()) >= 1)
+}
+
+func TestBlastWithLoadGenerationAndReportWrittenToFileAsJson(t *testing.T) {
+	payloadSizeBytes := int64(10)
+	server, err := NewEchoServer("tcp", "localhost:10011", payloadSizeBytes)
+	assert.Nil(t, err)
+
+	server.accept(t)
+	defer server.stop()
+
+	reportFile, err := os.CreateTemp(".", "report")
+	assert.Nil(t, err)
+	defer func() {
+		_ = os.Remove(reportFile.Name())
+	}()
+
+	groupOptions := workers.NewGroupOptionsFullyLoaded(
+		10,
+		1,
+		payload.NewConstantPayloadGenerator([]byte("HelloWorld")),
+		"localhost:10011",
+		3*time.Second,
+		10,
+		1*time.Second,
+	)
+	buffer := &bytes.Buffer{}
+	blast.OutputStream = buffer
+
+	reportOptions := blast.ReportOptions{Format: report.JsonFormat, FilePath: reportFile.Name()}
+	blastInstance := blast.NewBlastWithoutResponseReadingWithReportOptions(groupOptions, reportOptions, false)
+	blastInstance.WaitForCompletion()
+
+	assert.True(t, extract("TotalRequests:", regexp.MustCompile("TotalRequests.*"), buffer.Bytes()) >= 1)
+
+	content, err := os.ReadFile(reportFile.Name())
+	assert.Nil(t, err)
+
+	savedReport := &report.Report{}
+	assert.Nil(t, json.Unmarshal(content, savedReport))
+	assert.True(t, savedReport.Load.TotalRequests >= 1)
+	assert.Equal(t, uint(0), savedReport.Load.ErrorCount)
+}
+
+func TestBlastWithLoadGenerationAndReportWrittenToStdoutAsJson(t *testing.T) {
+	payloadSizeBytes := int64(10)
+	server, err := NewEchoServer("tcp", "localhost:10023", payloadSizeBytes)
+	assert.Nil(t, err)
+
+	server.accept(t)
+	defer server.stop()
+
+	groupOptions := workers.NewGroupOptionsFullyLoaded(
+		5,
+		1,
+		payload.NewConstantPayloadGenerator([]byte("HelloWorld")),
+		"localhost:10023",
+		3*time.Second,
+		100,
+		5*time.Second,
+	).WithTotalRequests(20)
+
+	reader, writer, err := os.Pipe()
+	assert.Nil(t, err)
+	stdout := os.Stdout
+	os.Stdout = writer
+	blast.OutputStream = writer
+	defer func() {
+		os.Stdout = stdout
+	}()
+
+	output := make(chan []byte)
+	go func() {
+		content, _ := io.ReadAll(reader)
+		output <- content
+	}()
+
+	reportOptions := blast.ReportOptions{Format: report.JsonFormat}
+	blastInstance := blast.NewBlastWithoutResponseReadingWithReportOptions(groupOptions, reportOptions, false)
+	blastInstance.WaitForCompletion()
+	_ = writer.Close()
+
+	savedReport := &report.Report{}
+	assert.Nil(t, json.Unmarshal(<-output, savedReport))
+	assert.Equal(t, uint(20), savedReport.Load.TotalRequests)
+}
+
+func TestBlastWithLoadGenerationAndResponseReadingWithProgress(t *testing.T) {
+	payloadSizeBytes := int64(10)
+	server, err := NewEchoServer("tcp", "localhost:10012", payloadSizeBytes)
+	assert.Nil(t, err)
+
+	server.accept(t)
+	defer server.stop()
+
+	groupOptions := workers.NewGroupOptionsFullyLoaded(
+		5,
+		1,
+		payload.NewConstantPayloadGenerator([]byte("HelloWorld")),
+		"localhost:10012",
+		3*time.Second,
+		20,
+		1*time.Second,
+	)
+	responseOptions := blast.ResponseOptions{
+		ResponsePayloadSizeBytes: payloadSizeBytes,
+		TotalResponsesToRead:     1000,
+		ReadingOption:            blast.ReadTotalResponses,
+		ReadDeadline:             100 * time.Millisecond,
+	}
+	blast.OutputStream = &bytes.Buffer{}
+	progressBuffer := &bytes.Buffer{}
+	blast.ProgressStream = progressBuffer
+
+	var reported []blast.Progress
+	reportOptions := blast.ReportOptions{
+		ProgressInterval: 100 * time.Millisecond,
+		ProgressCallback: func(progress blast.Progress) {
+			reported = append(reported, progress)
+		},
+	}
+	blastInstance := blast.NewBlastWithResponseReadingWithReportOptions(groupOptions, responseOptions, reportOptions, false)
+	blastInstance.WaitForCompletion()
+
+	assert.True(t, len(reported) >= 2)
+	lastProgress := reported[len(reported)-1]
+	assert.True(t, lastProgress.RequestsSent >= 1)
+	assert.True(t, lastProgress.ResponsesRead >= 1)
+	assert.True(t, lastProgress.ConnectionsAlive >= 1)
+	assert.Equal(t, 0.0, lastProgress.ErrorRate)
+	assert.True(t, strings.Contains(progressBuffer.String(), "[Progress]"))
+}
+
+func TestThroughputSearchWithResponseReading(t *testing.T) {
+	payloadSizeBytes := int64(10)
+	server, err := NewEchoServer("tcp", "localhost:10013", payloadSizeBytes)
+	assert.Nil(t, err)
+
+	server.accept(t)
+	defer server.stop()
+
+	groupOptions := workers.NewGroupOptions(
+		10,
+		payload.NewConstantPayloadGenerator([]byte("HelloWorld")),
+		"localhost:10013",
+		time.Second,
+	)
+	responseOptions := blast.ResponseOptions{
+		ResponsePayloadSizeBytes: payloadSizeBytes,
+	}
+	search, err := blast.NewThroughputSearchWithResponseReading(groupOptions, responseOptions, blast.SearchOptions{
+		StartRate:    50,
+		MaxRate:      100,
+		Precision:    10,
+		StepDuration: 200 * time.Millisecond,
+		Thresholds: blast.SearchThresholds{
+			MaxErrorRate:     0.1,
+			MinResponseRatio: 0.5,
+		},
+	})
+	assert.Nil(t, err)
+
+	result := search.Run()
+	assert.Equal(t, 100.0, result.MaxSustainableRate)
+	assert.Equal(t, 2, len(result.Steps))
+
+	buffer := &bytes.Buffer{}
+	assert.Nil(t, result.Print(buffer))
+	assert.True(t, strings.Contains(buffer.String(), "MaxSustainableRate: 100.00"))
+}
+
+func TestBlastWithLoadGenerationForTotalRequests(t *testing.T) {
+	payloadSizeBytes := int64(10)
+	server, err := NewEchoServer("tcp", "localhost:10014", payloadSizeBytes)
+	assert.Nil(t, err)
+
+	server.accept(t)
+	defer server.stop()
+
+	groupOptions := workers.NewGroupOptionsFullyLoaded(
+		10,
+		1,
+		payload.NewConstantPayloadGenerator([]byte("HelloWorld")),
+		"localhost:10014",
+		3*time.Second,
+		100,
+		time.Minute,
+	).WithTotalRequests(50)
+	blast.OutputStream = &bytes.Buffer{}
+
+	startTime := time.Now()
+	blastInstance := blast.NewBlastWithoutResponseReading(groupOptions, false)
+	blastInstance.WaitForCompletion()
+
+	assert.True(t, time.Since(startTime) < 10*time.Second)
+	assert.Equal(t, uint(50), blastInstance.Report().Load.TotalRequests)
+	assert.Equal(t, uint(0), blastInstance.Report().Load.ErrorCount)
+}
+
+func TestBlastWithLoadGenerationAndResponseReadingForTotalRequests(t *testing.T) {
+	payloadSizeBytes := int64(10)
+	server, err := NewEchoServer("tcp", "localhost:10015", payloadSizeBytes)
+	assert.Nil(t, err)
+
+	server.accept(t)
+	defer server.stop()
+
+	groupOptions := workers.NewGroupOptionsFullyLoaded(
+		10,
+		1,
+		payload.NewConstantPayloadGenerator([]byte("HelloWorld")),
+		"localhost:10015",
+		3*time.Second,
+		100,
+		time.Minute,
+	).WithTotalRequests(50)
+	responseOptions := blast.ResponseOptions{
+		ResponsePayloadSizeBytes: payloadSizeBytes,
+		TotalResponsesToRead:     1000,
+		ReadingOption:            blast.ReadTotalResponses,
+		ReadDeadline:             100 * time.Millisecond,
+		DrainTimeout:             10 * time.Second,
+	}
+	blast.OutputStream = &bytes.Buffer{}
+
+	startTime := time.Now()
+	blastInstance := blast.NewBlastWithResponseReading(groupOptions, responseOptions, false)
+	blastInstance.WaitForCompletion()
+
+	assert.True(t, time.Since(startTime) < 10*time.Second)
+	assert.Equal(t, uint(50), blastInstance.Report().Load.TotalRequests)
+	assert.Equal(t, uint(50), blastInstance.Report().Response.TotalResponses)
+}
+
+func TestBlastWithLoadGenerationAndResponseReadingAcrossMultipleTargets(t *testing.T) {
+	payloadSizeBytes := int64(10)
+	server, err := NewEchoServer("tcp", "localhost:10016", payloadSizeBytes)
+	assert.Nil(t, err)
+
+	server.accept(t)
+	defer server.stop()
+
+	otherServer, err := NewEchoServer("tcp", "localhost:10017", payloadSizeBytes)
+	assert.Nil(t, err)
+
+	otherServer.accept(t)
+	defer otherServer.stop()
+
+	targets, err := workers.ParseTargets([]string{"localhost:10016", "localhost:10017"})
+	assert.Nil(t, err)
+
+	groupOptions := workers.NewGroupOptionsFullyLoaded(
+		10,
+		2,
+		payload.NewConstantPayloadGenerator([]byte("HelloWorld")),
+		"localhost:10016",
+		3*time.Second,
+		100,
+		time.Minute,
+	).WithTotalRequests(40).WithTargets(targets, workers.RoundRobinBalancing)
+	responseOptions := blast.ResponseOptions{
+		ResponsePayloadSizeBytes: payloadSizeBytes,
+		ReadingOption:            blast.ReadTotalResponses,
+		TotalResponsesToRead:     40,
+		ReadDeadline:             100 * time.Millisecond,
+		DrainTimeout:             10 * time.Second,
+	}
+	blast.OutputStream = &bytes.Buffer{}
+
+	blastInstance := blast.NewBlastWithResponseReading(groupOptions, responseOptions, false)
+	blastInstance.WaitForCompletion()
+
+	report := blastInstance.Report()
+	assert.Equal(t, uint(40), report.Load.TotalRequests)
+	assert.Equal(t, 2, len(report.Targets))
+	assert.Equal(t, "tcp://localhost:10016", report.Targets[0].Target)
+	assert.Equal(t, "tcp://localhost:10017", report.Targets[1].Target)
+	assert.Equal(t, report.Load.TotalRequests, report.Targets[0].RequestsSent+report.Targets[1].RequestsSent)
+	assert.Equal(t, report.Response.TotalResponses, report.Targets[0].Responses+report.Targets[1].Responses)
+	assert.True(t, report.Targets[0].RequestsSent > 0)
+	assert.True(t, report.Targets[1].RequestsSent > 0)
+}
+
+func TestRunnerWithResponseReadingForTotalRequests(t *testing.T) {
+	payloadSizeBytes := int64(10)
+	server, err := NewEchoServer("tcp", "localhost:10018", payloadSizeBytes)
+	assert.Nil(t, err)
+
+	server.accept(t)
+	defer server.stop()
+
+	runner, err := blast.NewRunner(blast.RunOptions{
+		Targets:           []string{"localhost:10018"},
+		Concurrency:       10,
+		PayloadGenerator:  payload.NewConstantPayloadGenerator([]byte("HelloWorld")),
+		RequestsPerSecond: 100,
+		MaxDuration:       time.Minute,
+		TotalRequests:     50,
+		ResponseOptions: &blast.ResponseOptions{
+			ResponsePayloadSizeBytes: payloadSizeBytes,
+			ReadDeadline:             100 * time.Millisecond,
+			DrainTimeout:             10 * time.Second,
+		},
+	})
+	assert.Nil(t, err)
+
+	runReport, err := runner.Run(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, uint(50), runReport.Load.TotalRequests)
+	assert.Equal(t, uint(0), runReport.Load.ErrorCount)
+	assert.Equal(t, uint(50), runReport.Response.TotalResponses)
+}
+
+// connectionCounter counts the connections that are open (not closed by the client), along with the peak number
+// of the connections that were open at the same time.
+type connectionCounter struct {
+	open atomic.Int64
+	peak atomic.Int64
+}
+
+// serveAndCountOpenConnections accepts the connections on the listener, discards all the data read from them and
+// counts the connections that are open.
+func serveAndCountOpenConnections(listener net.Listener) *connectionCounter {
+	counter := &connectionCounter{}
+	go func() {
+		for {
+			connection, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			open := counter.open.Add(1)
+			for peak := counter.peak.Load(); open > peak && !counter.peak.CompareAndSwap(peak, open); {
+				peak = counter.peak.Load()
+			}
+			go func(connection net.Conn) {
+				defer func() {
+					_ = connection.Close()
+					counter.open.Add(-1)
+				}()
+				_, _ = io.Copy(io.Discard, connection)
+			}(connection)
+		}
+	}()
+	return counter
+}
+
+func TestRunnerClosesTheConnectionsOnceTheLoadCompletes(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:10021")
+	assert.Nil(t, err)
+	defer func() {
+		_ = listener.Close()
+	}()
+	counter := serveAndCountOpenConnections(listener)
+
+	runner, err := blast.NewRunner(blast.RunOptions{
+		Targets:           []string{"localhost:10021"},
+		Concurrency:       4,
+		Connections:       4,
+		PayloadGenerator:  payload.NewConstantPayloadGenerator([]byte("HelloWorld")),
+		RequestsPerSecond: 100,
+		MaxDuration:       time.Minute,
+		TotalRequests:     20,
+	})
+	assert.Nil(t, err)
+
+	for run := 1; run <= 3; run++ {
+		runReport, err := runner.Run(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, uint(20), runReport.Load.TotalRequests)
+	}
+	assert.Eventually(t, func() bool {
+		return counter.open.Load() == 0
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestThroughputSearchClosesTheConnectionsOfEachStep(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:10022")
+	assert.Nil(t, err)
+	defer func() {
+		_ = listener.Close()
+	}()
+	counter := serveAndCountOpenConnections(listener)
+
+	groupOptions := workers.NewGroupOptionsFullyLoaded(
+		4,
+		2,
+		payload.NewConstantPayloadGenerator([]byte("HelloWorld")),
+		"localhost:10022",
+		3*time.Second,
+		100,
+		time.Second,
+	)
+	search, err := blast.NewThroughputSearch(groupOptions, blast.SearchOptions{
+		StartRate:    50,
+		MaxRate:      400,
+		Precision:    50,
+		StepDuration: 100 * time.Millisecond,
+		Thresholds:   blast.SearchThresholds{MaxErrorRate: 0.5},
+	})
+	assert.Nil(t, err)
+
+	result := search.Run()
+	assert.True(t, len(result.Steps) >= 3)
+	// the server may see the connections of a step closed after the next step has dialed its connections.
+	assert.True(t, counter.peak.Load() <= 4)
+	assert.Eventually(t, func() bool {
+		return counter.open.Load() == 0
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestRunnerIsStoppedWhenTheContextIsDone(t *testing.T) {
+	payloadSizeBytes := int64(10)
+	server, err := NewEchoServer("tcp", "localhost:10019", payloadSizeBytes)
+	assert.Nil(t, err)
+
+	server.accept(t)
+	defer server.stop()
+
+	runner, err := blast.NewRunner(blast.RunOptions{
+		Targets:           []string{"localhost:10019"},
+		Concurrency:       10,
+		PayloadGenerator:  payload.NewConstantPayloadGenerator([]byte("HelloWorld")),
+		RequestsPerSecond: 100,
+		MaxDuration:       time.Minute,
+	})
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	startTime := time.Now()
+	runReport, err := runner.Run(ctx)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, time.Since(startTime) < 10*time.Second)
+	assert.True(t, runReport.Load.TotalRequests > 0)
+}
+
+func TestBlastWithLoadGenerationAndResponseReadingWithLiveMetrics(t *testing.T) {
+	payloadSizeBytes := int64(10)
+	server, err := NewEchoServer("tcp", "localhost:10020", payloadSizeBytes)
+	assert.Nil(t, err)
+
+	server.accept(t)
+	defer server.stop()
+
+	groupOptions := workers.NewGroupOptionsFullyLoaded(
+		10,
+		1,
+		payload.NewConstantPayloadGenerator([]byte("HelloWorld")),
+		"localhost:10020",
+		3*time.Second,
+		100,
+		time.Second,
+	)
+	responseOptions := blast.ResponseOptions{
+		ResponsePayloadSizeBytes: payloadSizeBytes,
+		TotalResponsesToRead:     math.MaxUint32,
+		ReadingOption:            blast.ReadTotalResponses,
+		ReadDeadline:             100 * time.Millisecond,
+	}
+	scrapes := make(chan string, 1)
+	reportOptions := blast.ReportOptions{
+		MetricsAddress:   "localhost:9095",
+		ProgressInterval: 500 * time.Millisecond,
+		ProgressCallback: func(progress blast.Progress) {
+			response, err := http.Get("http://localhost:9095/metrics")
+			if err != nil {
+				return
+			}
+			defer func() {
+				_ = response.Body.Close()
+			}()
+			body, _ := io.ReadAll(response.Body)
+			select {
+			case scrapes <- string(body):
+			default:
+			}
+		},
+	}
+	blast.OutputStream = &bytes.Buffer{}
+	blast.ProgressStream = &bytes.Buffer{}
+
+	blastInstance := blast.NewBlastWithResponseReadingWithReportOptions(groupOptions, responseOptions, reportOptions, false)
+	blastInstance.WaitForCompletion()
+
+	var scrape string
+	select {
+	case scrape = <-scrapes:
+	default:
+		t.Fatal("expected the live metrics to be scraped during the run")
+	}
+	assert.Contains(t, scrape, "blast_requests_sent_total")
+	assert.Contains(t, scrape, "blast_responses_read_total")
+	assert.Contains(t, scrape, "blast_active_connections 1")
+	assert.NotContains(t, scrape, "blast_requests_sent_total 0\n")
+}
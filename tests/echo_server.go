@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"crypto/tls"
 	"net"
 	"sync/atomic"
 	"testing"
@@ -29,6 +30,19 @@ func NewEchoServer(network, address string, payloadSizeBytes int64) (*EchoServer
 	}, nil
 }
 
+func NewTLSEchoServer(network, address string, payloadSizeBytes int64, config *tls.Config) (*EchoServer, error) {
+	listener, err := tls.Listen(network, address, config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EchoServer{
+		listener:         listener,
+		payloadSizeBytes: payloadSizeBytes,
+		stopChannel:      make(chan struct{}),
+	}, nil
+}
+
 func NewEchoServerWithNoWriteback(
 	network, address string,
 	payloadSizeBytes int64,
@@ -94,3 +108,49 @@ func (server *EchoServer) stop() {
 func (server *EchoServer) totalRequestsReceived() uint32 {
 	return server.totalRequests.Load()
 }
+
+type UDPEchoServer struct {
+	connection    net.PacketConn
+	stopChannel   chan struct{}
+	totalRequests atomic.Uint32
+}
+
+func NewUDPEchoServer(address string) (*UDPEchoServer, error) {
+	connection, err := net.ListenPacket("udp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UDPEchoServer{
+		connection:  connection,
+		stopChannel: make(chan struct{}),
+	}, nil
+}
+
+func (server *UDPEchoServer) serve() {
+	go func() {
+		payload := make([]byte, 64*1024)
+		for {
+			select {
+			case <-server.stopChannel:
+				return
+			default:
+				n, address, err := server.connection.ReadFrom(payload)
+				if err != nil {
+					continue
+				}
+				server.totalRequests.Add(1)
+				_, _ = server.connection.WriteTo(payload[:n], address)
+			}
+		}
+	}()
+}
+
+func (server *UDPEchoServer) stop() {
+	close(server.stopChannel)
+	_ = server.connection.Close()
+}
+
+func (server *UDPEchoServer) totalRequestsReceived() uint32 {
+	return server.totalRequests.Load()
+}
@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"encoding/binary"
 	"net"
 	"sort"
 	"testing"
@@ -11,6 +12,11 @@ import (
 	"github.com/SarthakMakhija/blast-core/report"
 )
 
+func TestCreatesAResponseReaderWithAZeroResponseSize(t *testing.T) {
+	_, err := report.NewResponseReader(0, 100*time.Millisecond, make(chan report.SubjectServerResponse))
+	assert.Equal(t, report.ErrInvalidResponseSize, err)
+}
+
 func TestReadsResponseFromASingleConnection(t *testing.T) {
 	payloadSizeBytes := int64(10)
 	server, err := NewEchoServer("tcp", "localhost:9090", payloadSizeBytes)
@@ -29,11 +35,13 @@ func TestReadsResponseFromASingleConnection(t *testing.T) {
 		_ = connection.Close()
 	}()
 
-	responseReader := report.NewResponseReader(
+	responseReader, err := report.NewResponseReader(
 		payloadSizeBytes,
 		100*time.Millisecond,
 		responseChannel,
 	)
+	assert.Nil(t, err)
+	defer responseReader.Close()
 	responseReader.StartReading(connection)
 
 	response := <-responseChannel
@@ -63,11 +71,13 @@ func TestReadsResponseFromTwoConnections(t *testing.T) {
 		_ = otherConnection.Close()
 	}()
 
-	responseReader := report.NewResponseReader(
+	responseReader, err := report.NewResponseReader(
 		payloadSizeBytes,
 		100*time.Millisecond,
 		responseChannel,
 	)
+	assert.Nil(t, err)
+	defer responseReader.Close()
 	responseReader.StartReading(connection)
 	responseReader.StartReading(otherConnection)
 
@@ -94,11 +104,13 @@ func TestTracksTheNumberOfResponsesRead(t *testing.T) {
 		_ = connection.Close()
 	}()
 
-	responseReader := report.NewResponseReader(
+	responseReader, err := report.NewResponseReader(
 		payloadSizeBytes,
 		100*time.Millisecond,
 		responseChannel,
 	)
+	assert.Nil(t, err)
+	defer responseReader.Close()
 	responseReader.StartReading(connection)
 
 	_ = <-responseChannel
@@ -106,6 +118,84 @@ func TestTracksTheNumberOfResponsesRead(t *testing.T) {
 	assert.Equal(t, uint64(1), responseReader.TotalSuccessfulResponsesRead())
 }
 
+func TestReadsCoalescedLengthPrefixedResponsesFromASingleConnection(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:9093")
+	assert.Nil(t, err)
+	defer func() {
+		_ = listener.Close()
+	}()
+
+	go func() {
+		connection, err := listener.Accept()
+		assert.Nil(t, err)
+		_, _ = connection.Write([]byte{0, 5, 'H', 'e', 'l', 'l', 'o', 0, 2, 'O', 'K'})
+	}()
+
+	connection := connectTo(t, "localhost:9093")
+	responseChannel := make(chan report.SubjectServerResponse)
+
+	defer func() {
+		close(responseChannel)
+		_ = connection.Close()
+	}()
+
+	framer, err := report.NewLengthPrefixedResponseFramer(2, binary.BigEndian)
+	assert.Nil(t, err)
+
+	responseReader := report.NewResponseReaderFullyLoaded(
+		framer,
+		100*time.Millisecond,
+		nil,
+		responseChannel,
+	)
+	defer responseReader.Close()
+	responseReader.StartReading(connection)
+
+	responsesLength := captureTwoResponses(t, responseChannel)
+	assert.Equal(t, int64(4), responsesLength[0])
+	assert.Equal(t, int64(7), responsesLength[1])
+	assert.Equal(t, uint64(2), responseReader.TotalSuccessfulResponsesRead())
+}
+
+func TestReadsEachDatagramAsASingleResponse(t *testing.T) {
+	server, err := NewUDPEchoServer("localhost:9094")
+	assert.Nil(t, err)
+
+	server.serve()
+
+	connection, err := net.Dial("udp", "localhost:9094")
+	assert.Nil(t, err)
+
+	responseChannel := make(chan report.SubjectServerResponse)
+
+	defer func() {
+		server.stop()
+		close(responseChannel)
+		_ = connection.Close()
+	}()
+
+	responseReader, err := report.NewResponseReader(
+		4,
+		100*time.Millisecond,
+		responseChannel,
+	)
+	assert.Nil(t, err)
+	defer responseReader.Close()
+	responseReader.StartReading(connection)
+
+	writeTo(t, connection, []byte("HelloWorld"))
+	response := <-responseChannel
+	assert.Nil(t, response.Err)
+	assert.Equal(t, int64(10), response.PayloadLengthBytes)
+
+	writeTo(t, connection, []byte("Blast"))
+	response = <-responseChannel
+	assert.Nil(t, response.Err)
+	assert.Equal(t, int64(5), response.PayloadLengthBytes)
+
+	assert.Equal(t, uint64(2), responseReader.TotalSuccessfulResponsesRead())
+}
+
 func connectTo(t *testing.T, address string) net.Conn {
 	connection, err := net.Dial("tcp", address)
 	assert.Nil(t, err)
@@ -118,6 +208,137 @@ func writeTo(t *testing.T, connection net.Conn, payload []byte) {
 	assert.Nil(t, err)
 }
 
+func TestReadsResponsesRejectedByTheResponseValidator(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:9096")
+	assert.Nil(t, err)
+	defer func() {
+		_ = listener.Close()
+	}()
+
+	go func() {
+		connection, err := listener.Accept()
+		assert.Nil(t, err)
+		_, _ = connection.Write([]byte("+OK\nERR overloaded\n"))
+	}()
+
+	connection := connectTo(t, "localhost:9096")
+	responseChannel := make(chan report.SubjectServerResponse)
+
+	defer func() {
+		close(responseChannel)
+		_ = connection.Close()
+	}()
+
+	framer, err := report.NewDelimiterResponseFramer([]byte("\n"))
+	assert.Nil(t, err)
+	validator, err := report.NewPrefixResponseValidator([]byte("+OK"))
+	assert.Nil(t, err)
+
+	responseReader := report.NewResponseReaderFullyLoaded(
+		framer,
+		100*time.Millisecond,
+		nil,
+		responseChannel,
+	).WithResponseValidator(validator)
+	defer responseReader.Close()
+	responseReader.StartReading(connection)
+
+	response := <-responseChannel
+	assert.Nil(t, response.Err)
+
+	response = <-responseChannel
+	assert.Equal(t, report.ErrResponsePrefixMismatch, response.Err)
+	assert.Equal(t, uint64(2), responseReader.TotalResponsesRead())
+	assert.Equal(t, uint64(1), responseReader.TotalSuccessfulResponsesRead())
+}
+
+func TestReportsTheInFlightRequestsWithoutResponsesAsTimedOut(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:9097")
+	assert.Nil(t, err)
+	defer func() {
+		_ = listener.Close()
+	}()
+
+	go func() {
+		_, _ = listener.Accept()
+	}()
+
+	connection := connectTo(t, "localhost:9097")
+	responseChannel := make(chan report.SubjectServerResponse, 1)
+
+	responseFramer, err := report.NewFixedSizeResponseFramer(10)
+	assert.Nil(t, err)
+
+	responseReader := report.NewResponseReaderFullyLoaded(
+		responseFramer,
+		0,
+		report.NewOffsetRequestIdExtractor(0, binary.BigEndian),
+		responseChannel,
+	).WithInFlightTimeout(50 * time.Millisecond)
+	defer func() {
+		responseReader.Close()
+		_ = connection.Close()
+	}()
+
+	responseReader.InFlightRequests().Add(1, time.Now())
+	responseReader.StartReading(connection)
+
+	response := <-responseChannel
+	assert.Equal(t, report.ErrResponseTimeout, response.Err)
+	assert.Equal(t, uint64(1), responseReader.TotalResponsesRead())
+	assert.Equal(t, 0, responseReader.InFlightRequests().Size())
+}
+
+func TestCountsTheInFlightRequestWithoutResponseOnceWithAReadDeadline(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:9098")
+	assert.Nil(t, err)
+	defer func() {
+		_ = listener.Close()
+	}()
+
+	serverConnections := make(chan net.Conn, 1)
+	go func() {
+		connection, err := listener.Accept()
+		if err == nil {
+			serverConnections <- connection
+		}
+	}()
+
+	connection := connectTo(t, "localhost:9098")
+	responseChannel := make(chan report.SubjectServerResponse, 16)
+
+	responseFramer, err := report.NewFixedSizeResponseFramer(8)
+	assert.Nil(t, err)
+
+	responseReader := report.NewResponseReaderFullyLoaded(
+		responseFramer,
+		20*time.Millisecond,
+		report.NewOffsetRequestIdExtractor(0, binary.BigEndian),
+		responseChannel,
+	)
+	defer func() {
+		responseReader.Close()
+		_ = connection.Close()
+	}()
+
+	responseReader.InFlightRequests().Add(1, time.Now())
+	responseReader.StartReading(connection)
+
+	response := <-responseChannel
+	assert.Equal(t, report.ErrResponseTimeout, response.Err)
+
+	serverConnection := <-serverConnections
+	defer func() {
+		_ = serverConnection.Close()
+	}()
+	_, err = serverConnection.Write(binary.BigEndian.AppendUint64(nil, 1))
+	assert.Nil(t, err)
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(t, uint64(1), responseReader.TotalResponsesRead())
+	assert.Equal(t, 0, len(responseChannel))
+}
+
 func captureTwoResponses(t *testing.T, responseChannel chan report.SubjectServerResponse) []int64 {
 	var responsesLength []int64
 
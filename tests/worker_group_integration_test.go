@@ -1,8 +1,16 @@
 package tests
 
 import (
+	"bufio"
+	"crypto/tls"
+	"fmt"
 	"github.com/SarthakMakhija/blast-core/payload"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
 	"sort"
+	"sync"
 	"testing"
 	"time"
 
@@ -48,16 +56,19 @@ func TestSendsRequestsWithMultipleConnections(t *testing.T) {
 	loadGenerationResponseChannel := workerGroup.Run()
 	uniqueConnectionIds := make(map[int]bool)
 
+	doneChannel := make(chan struct{})
 	go func() {
 		for response := range loadGenerationResponseChannel {
 			uniqueConnectionIds[response.ConnectionId] = true
 			assert.Nil(t, response.Err)
 			assert.Equal(t, int64(10), response.PayloadLengthBytes)
 		}
+		close(doneChannel)
 	}()
 
 	workerGroup.WaitTillDone()
 	close(loadGenerationResponseChannel)
+	<-doneChannel
 
 	connectionIds := make([]int, 0, len(uniqueConnectionIds))
 	for connectionId := range uniqueConnectionIds {
@@ -68,6 +79,788 @@ func TestSendsRequestsWithMultipleConnections(t *testing.T) {
 	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, connectionIds)
 }
 
+func TestSendsRequestsWithOpenLoadModel(t *testing.T) {
+	payloadSizeBytes := int64(10)
+	server, err := NewEchoServer("tcp", "localhost:8099", payloadSizeBytes)
+	assert.Nil(t, err)
+
+	server.accept(t)
+	defer server.stop()
+
+	concurrency := uint(5)
+	groupOptions := workers.NewGroupOptions(concurrency, payload.NewConstantPayloadGenerator([]byte("HelloWorld")), "localhost:8099", 50*time.Millisecond).
+		WithArrivalRate(200)
+	assert.Equal(t, workers.OpenLoadModel, groupOptions.LoadModel())
+	assert.Equal(t, uint(10), groupOptions.ExpectedLoadInTotalDuration())
+
+	workerGroup := workers.NewWorkerGroup(groupOptions)
+	loadGenerationResponseChannel := workerGroup.Run()
+
+	totalRequests := 0
+	doneChannel := make(chan struct{})
+	go func() {
+		for response := range loadGenerationResponseChannel {
+			assert.Nil(t, response.Err)
+			if !response.Dropped {
+				totalRequests++
+			}
+		}
+		close(doneChannel)
+	}()
+
+	workerGroup.WaitTillDone()
+	close(loadGenerationResponseChannel)
+	<-doneChannel
+
+	assert.True(t, totalRequests > 0)
+	assert.True(t, totalRequests <= 10)
+}
+
+func TestSendsRequestsWithLoadProfile(t *testing.T) {
+	payloadSizeBytes := int64(10)
+	server, err := NewEchoServer("tcp", "localhost:8100", payloadSizeBytes)
+	assert.Nil(t, err)
+
+	server.accept(t)
+	defer server.stop()
+
+	profile, err := workers.ParseLoadProfile("50ms:0-400,50ms:400@2")
+	assert.Nil(t, err)
+
+	concurrency := uint(5)
+	groupOptions := workers.NewGroupOptions(concurrency, payload.NewConstantPayloadGenerator([]byte("HelloWorld")), "localhost:8100", time.Second).
+		WithLoadProfile(profile)
+	assert.Equal(t, 100*time.Millisecond, groupOptions.MaxDuration())
+	assert.Equal(t, uint(30), groupOptions.ExpectedLoadInTotalDuration())
+
+	workerGroup := workers.NewWorkerGroup(groupOptions)
+	loadGenerationResponseChannel := workerGroup.Run()
+
+	totalRequests := 0
+	doneChannel := make(chan struct{})
+	go func() {
+		for response := range loadGenerationResponseChannel {
+			assert.Nil(t, response.Err)
+			if !response.Dropped {
+				totalRequests++
+			}
+		}
+		close(doneChannel)
+	}()
+
+	workerGroup.WaitTillDone()
+	close(loadGenerationResponseChannel)
+	<-doneChannel
+
+	assert.True(t, totalRequests > 0)
+	assert.True(t, totalRequests <= 30)
+}
+
+func TestSendsRequestsAfterReconnectingToAServerThatStartsLate(t *testing.T) {
+	concurrency := uint(4)
+	groupOptions := workers.NewGroupOptionsFullyLoaded(
+		concurrency,
+		2,
+		payload.NewConstantPayloadGenerator([]byte("HelloWorld")),
+		"localhost:8101",
+		3*time.Second,
+		100,
+		300*time.Millisecond,
+	).WithReconnectPolicy(workers.ReconnectPolicy{
+		Strategy:       workers.BackoffReconnect,
+		InitialBackoff: 5 * time.Millisecond,
+		MaxBackoff:     20 * time.Millisecond,
+	})
+
+	workerGroup := workers.NewWorkerGroup(groupOptions)
+	loadGenerationResponseChannel := workerGroup.Run()
+
+	time.Sleep(50 * time.Millisecond)
+	server, err := NewEchoServer("tcp", "localhost:8101", int64(10))
+	assert.Nil(t, err)
+
+	server.accept(t)
+	defer server.stop()
+
+	totalRequests := 0
+	doneChannel := make(chan struct{})
+	go func() {
+		for response := range loadGenerationResponseChannel {
+			assert.Nil(t, response.Err)
+			totalRequests++
+		}
+		close(doneChannel)
+	}()
+
+	workerGroup.WaitTillDone()
+	close(loadGenerationResponseChannel)
+	<-doneChannel
+
+	assert.True(t, totalRequests > 0)
+
+	connections := workerGroup.ConnectionMetrics()
+	assert.Equal(t, 2, len(connections))
+	for _, connection := range connections {
+		assert.Equal(t, uint(1), connection.Reconnects)
+		assert.True(t, connection.Downtime >= 40*time.Millisecond)
+	}
+}
+
+func TestSendsRequestsWithConnectionChurn(t *testing.T) {
+	payloadSizeBytes := int64(10)
+	server, err := NewEchoServer("tcp", "localhost:8102", payloadSizeBytes)
+	assert.Nil(t, err)
+
+	server.accept(t)
+	defer server.stop()
+
+	groupOptions := workers.NewGroupOptionsFullyLoaded(
+		2,
+		1,
+		payload.NewConstantPayloadGenerator([]byte("HelloWorld")),
+		"localhost:8102",
+		3*time.Second,
+		100,
+		200*time.Millisecond,
+	).WithChurnPolicy(workers.ChurnPolicy{RequestsPerConnection: 5})
+
+	workerGroup := workers.NewWorkerGroup(groupOptions)
+	loadGenerationResponseChannel := workerGroup.Run()
+
+	totalRequests := 0
+	doneChannel := make(chan struct{})
+	go func() {
+		for response := range loadGenerationResponseChannel {
+			assert.Nil(t, response.Err)
+			totalRequests++
+		}
+		close(doneChannel)
+	}()
+
+	workerGroup.WaitTillDone()
+	close(loadGenerationResponseChannel)
+	<-doneChannel
+
+	dials := workerGroup.DialMetrics()
+	assert.True(t, totalRequests > 5)
+	assert.True(t, dials.TotalDials > 1)
+	assert.Equal(t, uint(0), dials.ErrorCount)
+	assert.Equal(t, dials.TotalDials, dials.Latency.TotalSamples)
+}
+
+func TestSendsRequestsAndReadsResponsesWithTLSConnectionChurn(t *testing.T) {
+	certificate, caFile := selfSignedCertificate(t)
+
+	payloadSizeBytes := int64(10)
+	server, err := NewTLSEchoServer(
+		"tcp",
+		"localhost:8103",
+		payloadSizeBytes,
+		&tls.Config{Certificates: []tls.Certificate{certificate}},
+	)
+	assert.Nil(t, err)
+
+	server.accept(t)
+	defer server.stop()
+
+	tlsConfig, err := workers.TLSOptions{CAFile: caFile, SessionResumption: true}.Config("localhost:8103")
+	assert.Nil(t, err)
+
+	groupOptions := workers.NewGroupOptionsFullyLoaded(
+		2,
+		1,
+		payload.NewConstantPayloadGenerator([]byte("HelloWorld")),
+		"localhost:8103",
+		3*time.Second,
+		100,
+		500*time.Millisecond,
+	).WithChurnPolicy(workers.ChurnPolicy{ConnectionLifetime: 100 * time.Millisecond}).WithTLSConfig(tlsConfig)
+
+	responseChannel := make(chan report.SubjectServerResponse)
+	totalResponses := 0
+	responsesDoneChannel := make(chan struct{})
+	go func() {
+		for range responseChannel {
+			totalResponses++
+		}
+		close(responsesDoneChannel)
+	}()
+
+	responseReader, err := report.NewResponseReader(payloadSizeBytes, 100*time.Millisecond, responseChannel)
+	assert.Nil(t, err)
+
+	workerGroup := workers.NewWorkerGroupWithResponseReader(groupOptions, responseReader)
+	loadGenerationResponseChannel := workerGroup.Run()
+
+	totalRequests := 0
+	doneChannel := make(chan struct{})
+	go func() {
+		for response := range loadGenerationResponseChannel {
+			assert.Nil(t, response.Err)
+			totalRequests++
+		}
+		close(doneChannel)
+	}()
+
+	workerGroup.WaitTillDone()
+	close(loadGenerationResponseChannel)
+	<-doneChannel
+	responseReader.Close()
+	close(responseChannel)
+	<-responsesDoneChannel
+
+	assert.Eventually(t, func() bool {
+		dials := workerGroup.DialMetrics()
+		return dials.TotalDials == dials.TotalHandshakes
+	}, time.Second, 10*time.Millisecond)
+
+	dials := workerGroup.DialMetrics()
+	assert.True(t, totalRequests > 0)
+	assert.True(t, totalResponses > 0)
+	assert.True(t, dials.TotalDials > 1)
+	assert.Equal(t, uint(0), dials.ErrorCount)
+	assert.Equal(t, dials.TotalDials, dials.TotalHandshakes)
+	assert.Equal(t, dials.TotalHandshakes, dials.HandshakeLatency.TotalSamples)
+	assert.True(t, dials.ResumedHandshakes > 0)
+}
+
+func TestFailsTheTLSHandshakeWithAnUntrustedCertificate(t *testing.T) {
+	certificate, _ := selfSignedCertificate(t)
+
+	payloadSizeBytes := int64(10)
+	server, err := NewTLSEchoServer(
+		"tcp",
+		"localhost:8104",
+		payloadSizeBytes,
+		&tls.Config{Certificates: []tls.Certificate{certificate}},
+	)
+	assert.Nil(t, err)
+
+	server.accept(t)
+	defer server.stop()
+
+	tlsConfig, err := workers.TLSOptions{}.Config("localhost:8104")
+	assert.Nil(t, err)
+
+	groupOptions := workers.NewGroupOptions(
+		1,
+		payload.NewConstantPayloadGenerator([]byte("HelloWorld")),
+		"localhost:8104",
+		10*time.Millisecond,
+	).WithTLSConfig(tlsConfig)
+
+	workerGroup := workers.NewWorkerGroup(groupOptions)
+	loadGenerationResponseChannel := workerGroup.Run()
+
+	doneChannel := make(chan struct{})
+	go func() {
+		for range loadGenerationResponseChannel {
+		}
+		close(doneChannel)
+	}()
+
+	workerGroup.WaitTillDone()
+	close(loadGenerationResponseChannel)
+	<-doneChannel
+
+	dials := workerGroup.DialMetrics()
+	assert.Equal(t, uint(1), dials.TotalDials)
+	assert.Equal(t, uint(1), dials.TotalHandshakes)
+	assert.Equal(t, uint(1), dials.ErrorCount)
+}
+
+func TestSendsRequestsOverAUnixDomainSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "blast.sock")
+
+	payloadSizeBytes := int64(10)
+	server, err := NewEchoServer("unix", socketPath, payloadSizeBytes)
+	assert.Nil(t, err)
+
+	server.accept(t)
+	defer server.stop()
+
+	workerGroup := workers.NewWorkerGroup(
+		workers.NewGroupOptions(
+			5,
+			payload.NewConstantPayloadGenerator([]byte("HelloWorld")),
+			"unix://"+socketPath,
+			10*time.Millisecond,
+		),
+	)
+	loadGenerationResponseChannel := workerGroup.Run()
+
+	totalRequests := 0
+	doneChannel := make(chan struct{})
+	go func() {
+		for response := range loadGenerationResponseChannel {
+			assert.Nil(t, response.Err)
+			assert.Equal(t, int64(10), response.PayloadLengthBytes)
+			totalRequests++
+		}
+		close(doneChannel)
+	}()
+
+	workerGroup.WaitTillDone()
+	close(loadGenerationResponseChannel)
+	<-doneChannel
+
+	assert.True(t, totalRequests > 0)
+	assert.Equal(t, "unix", workerGroup.DialMetrics().Network)
+}
+
+func TestSendsRequestsAndReadsResponsesOverUdp(t *testing.T) {
+	server, err := NewUDPEchoServer("localhost:8105")
+	assert.Nil(t, err)
+
+	server.serve()
+	defer server.stop()
+
+	responseChannel := make(chan report.SubjectServerResponse)
+	totalResponses := 0
+	responsesDoneChannel := make(chan struct{})
+	go func() {
+		for response := range responseChannel {
+			assert.Nil(t, response.Err)
+			assert.Equal(t, int64(10), response.PayloadLengthBytes)
+			totalResponses++
+		}
+		close(responsesDoneChannel)
+	}()
+
+	responseReader, err := report.NewResponseReader(4, 100*time.Millisecond, responseChannel)
+	assert.Nil(t, err)
+
+	workerGroup := workers.NewWorkerGroupWithResponseReader(
+		workers.NewGroupOptionsFullyLoaded(
+			2,
+			1,
+			payload.NewConstantPayloadGenerator([]byte("HelloWorld")),
+			"udp://localhost:8105",
+			3*time.Second,
+			50,
+			200*time.Millisecond,
+		),
+		responseReader,
+	)
+	loadGenerationResponseChannel := workerGroup.Run()
+
+	totalRequests := 0
+	doneChannel := make(chan struct{})
+	go func() {
+		for response := range loadGenerationResponseChannel {
+			assert.Nil(t, response.Err)
+			totalRequests++
+		}
+		close(doneChannel)
+	}()
+
+	workerGroup.WaitTillDone()
+	close(loadGenerationResponseChannel)
+	<-doneChannel
+	time.Sleep(20 * time.Millisecond)
+
+	assert.True(t, totalRequests > 0)
+	assert.Equal(t, uint32(totalRequests), server.totalRequestsReceived())
+	assert.Equal(t, "udp", workerGroup.DialMetrics().Network)
+
+	responseReader.Close()
+	close(responseChannel)
+	<-responsesDoneChannel
+	assert.True(t, totalResponses > 0)
+}
+
+func TestSendsRequestsAcrossMultipleTargetsWithConsistentHash(t *testing.T) {
+	payloadSizeBytes := int64(10)
+	server, err := NewEchoServer("tcp", "localhost:8106", payloadSizeBytes)
+	assert.Nil(t, err)
+
+	server.accept(t)
+	defer server.stop()
+
+	otherServer, err := NewEchoServer("tcp", "localhost:8107", payloadSizeBytes)
+	assert.Nil(t, err)
+
+	otherServer.accept(t)
+	defer otherServer.stop()
+
+	targets, err := workers.ParseTargets([]string{"localhost:8106", "localhost:8107"})
+	assert.Nil(t, err)
+
+	groupOptions := workers.NewGroupOptionsFullyLoaded(
+		2,
+		1,
+		payload.NewConstantPayloadGenerator([]byte("HelloWorld")),
+		"localhost:8106",
+		3*time.Second,
+		100,
+		200*time.Millisecond,
+	).WithTargets(targets, workers.ConsistentHashBalancing).WithHashKey(workers.HashKey{Offset: 0, Length: 5})
+
+	workerGroup := workers.NewWorkerGroup(groupOptions)
+	loadGenerationResponseChannel := workerGroup.Run()
+
+	requestsByTarget := make(map[string]int)
+	doneChannel := make(chan struct{})
+	go func() {
+		for response := range loadGenerationResponseChannel {
+			assert.Nil(t, response.Err)
+			requestsByTarget[response.Target]++
+		}
+		close(doneChannel)
+	}()
+
+	workerGroup.WaitTillDone()
+	close(loadGenerationResponseChannel)
+	<-doneChannel
+	time.Sleep(10 * time.Millisecond)
+
+	assert.Equal(t, 1, len(requestsByTarget))
+	for target, requests := range requestsByTarget {
+		assert.True(t, target == "tcp://localhost:8106" || target == "tcp://localhost:8107")
+		assert.True(t, requests > 0)
+	}
+	assert.Equal(t, uint(2), workerGroup.DialMetrics().TotalDials)
+	assert.True(t, server.totalRequestsReceived() == 0 || otherServer.totalRequestsReceived() == 0)
+}
+
+type connectionSequencePayloadGenerator struct {
+	lock                  sync.Mutex
+	sequencesByConnection map[int][]uint64
+	workerIds             map[uint]bool
+}
+
+func (generator *connectionSequencePayloadGenerator) GenerateWithContext(requestContext payload.RequestContext) []byte {
+	generator.lock.Lock()
+	defer generator.lock.Unlock()
+
+	generator.sequencesByConnection[requestContext.ConnectionId] = append(
+		generator.sequencesByConnection[requestContext.ConnectionId],
+		requestContext.ConnectionSequence,
+	)
+	generator.workerIds[requestContext.WorkerId] = true
+	return []byte("HelloWorld")
+}
+
+func TestSendsRequestsWithAContextualPayloadGenerator(t *testing.T) {
+	payloadSizeBytes := int64(10)
+	server, err := NewEchoServer("tcp", "localhost:8108", payloadSizeBytes)
+	assert.Nil(t, err)
+
+	server.accept(t)
+	defer server.stop()
+
+	generator := &connectionSequencePayloadGenerator{
+		sequencesByConnection: make(map[int][]uint64),
+		workerIds:             make(map[uint]bool),
+	}
+	groupOptions := workers.NewGroupOptionsFullyLoaded(
+		4,
+		2,
+		nil,
+		"localhost:8108",
+		3*time.Second,
+		1000,
+		time.Second,
+	).WithContextualPayloadGenerator(generator).WithTotalRequests(20)
+
+	workerGroup := workers.NewWorkerGroup(groupOptions)
+	loadGenerationResponseChannel := workerGroup.Run()
+
+	go func() {
+		for response := range loadGenerationResponseChannel {
+			assert.Nil(t, response.Err)
+		}
+	}()
+
+	workerGroup.WaitTillDone()
+	close(loadGenerationResponseChannel)
+
+	totalRequests := 0
+	assert.Equal(t, 2, len(generator.sequencesByConnection))
+	for _, sequences := range generator.sequencesByConnection {
+		sort.Slice(sequences, func(i, j int) bool {
+			return sequences[i] < sequences[j]
+		})
+		for index, sequence := range sequences {
+			assert.Equal(t, uint64(index+1), sequence)
+		}
+		totalRequests += len(sequences)
+	}
+	assert.Equal(t, 20, totalRequests)
+	assert.Equal(t, 4, len(generator.workerIds))
+}
+
+func TestSendsRequestsWithThePayloadsOfACorpus(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:8111")
+	assert.Nil(t, err)
+	defer func() {
+		_ = listener.Close()
+	}()
+
+	var lock sync.Mutex
+	countByPayload := make(map[string]int)
+	go func() {
+		connection, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() {
+			_ = connection.Close()
+		}()
+		reader := bufio.NewReader(connection)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			lock.Lock()
+			countByPayload[line]++
+			lock.Unlock()
+		}
+	}()
+
+	filePath := filepath.Join(t.TempDir(), "payloads")
+	assert.Nil(t, os.WriteFile(filePath, []byte("GET a\nSET b\n"), 0644))
+	corpus, err := payload.LoadPayloadCorpus(filePath, payload.LinesCorpusFormat)
+	assert.Nil(t, err)
+
+	groupOptions := workers.NewGroupOptionsFullyLoaded(
+		2,
+		1,
+		payload.NewCorpusPayloadGenerator(corpus, payload.SequentialSelection, 0),
+		"localhost:8111",
+		3*time.Second,
+		1000,
+		time.Second,
+	).WithTotalRequests(20)
+
+	workerGroup := workers.NewWorkerGroup(groupOptions)
+	loadGenerationResponseChannel := workerGroup.Run()
+
+	go func() {
+		for response := range loadGenerationResponseChannel {
+			assert.Nil(t, response.Err)
+		}
+	}()
+
+	workerGroup.WaitTillDone()
+	close(loadGenerationResponseChannel)
+
+	assert.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return countByPayload["GET a\n"] == 10 && countByPayload["SET b\n"] == 10
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestSendsRequestsWithThePayloadsOfATemplate(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:8112")
+	assert.Nil(t, err)
+	defer func() {
+		_ = listener.Close()
+	}()
+
+	var lock sync.Mutex
+	var lines []string
+	go func() {
+		connection, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() {
+			_ = connection.Close()
+		}()
+		reader := bufio.NewReader(connection)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			lock.Lock()
+			lines = append(lines, line)
+			lock.Unlock()
+		}
+	}()
+
+	generator, err := payload.NewTemplatePayloadGenerator(
+		[]byte("REQ {{requestId}} {{connectionId}} {{counter}}\n"),
+		payload.TemplateData{},
+		0,
+	)
+	assert.Nil(t, err)
+
+	groupOptions := workers.NewGroupOptionsFullyLoaded(
+		2,
+		1,
+		nil,
+		"localhost:8112",
+		3*time.Second,
+		1000,
+		time.Second,
+	).WithContextualPayloadGenerator(generator).WithTotalRequests(10)
+
+	workerGroup := workers.NewWorkerGroup(groupOptions)
+	loadGenerationResponseChannel := workerGroup.Run()
+
+	go func() {
+		for response := range loadGenerationResponseChannel {
+			assert.Nil(t, response.Err)
+		}
+	}()
+
+	workerGroup.WaitTillDone()
+	close(loadGenerationResponseChannel)
+
+	assert.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(lines) == 10
+	}, time.Second, 10*time.Millisecond)
+
+	lock.Lock()
+	defer lock.Unlock()
+	requestIds := make(map[string]bool)
+	for _, line := range lines {
+		var requestId string
+		var connectionId, counter int
+		_, err := fmt.Sscanf(line, "REQ %s %d %d\n", &requestId, &connectionId, &counter)
+		assert.Nil(t, err)
+		assert.True(t, counter >= 1 && counter <= 10)
+		requestIds[requestId] = true
+	}
+	assert.Equal(t, 10, len(requestIds))
+}
+
+type sessionPayloadGenerator struct {
+	lock     sync.Mutex
+	sessions map[string]int
+}
+
+func (generator *sessionPayloadGenerator) GenerateWithContext(requestContext payload.RequestContext) []byte {
+	generator.lock.Lock()
+	defer generator.lock.Unlock()
+
+	session, _ := requestContext.Session.([]byte)
+	generator.sessions[string(session)]++
+	return []byte("HelloWorld")
+}
+
+// serveHandshake accepts the connections, reads the handshake and writes the ack followed by the connection
+// number (starting at 1), and then reads (and discards) the requests.
+func serveHandshake(listener net.Listener, ack string) {
+	go func() {
+		for connectionNumber := 1; ; connectionNumber++ {
+			connection, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(connection net.Conn, connectionNumber int) {
+				defer func() {
+					_ = connection.Close()
+				}()
+				reader := bufio.NewReader(connection)
+				handshake, err := reader.ReadString('\n')
+				if err != nil || handshake != "AUTH\n" {
+					return
+				}
+				_, _ = connection.Write([]byte(fmt.Sprintf("%v %d\n", ack, connectionNumber)))
+				_, _ = io.Copy(io.Discard, reader)
+			}(connection, connectionNumber)
+		}
+	}()
+}
+
+func TestSendsRequestsOnTheConnectionsInitializedWithAHandshake(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:8109")
+	assert.Nil(t, err)
+	defer func() {
+		_ = listener.Close()
+	}()
+	serveHandshake(listener, "+OK")
+
+	ackFramer, err := report.NewDelimiterResponseFramer([]byte("\n"))
+	assert.Nil(t, err)
+	ackValidator, err := report.NewPrefixResponseValidator([]byte("+OK"))
+	assert.Nil(t, err)
+	initializer, err := workers.NewHandshakeConnectionInitializer([]byte("AUTH\n"), ackFramer, ackValidator)
+	assert.Nil(t, err)
+
+	generator := &sessionPayloadGenerator{sessions: make(map[string]int)}
+	groupOptions := workers.NewGroupOptionsFullyLoaded(
+		4,
+		2,
+		nil,
+		"localhost:8109",
+		3*time.Second,
+		1000,
+		time.Second,
+	).WithContextualPayloadGenerator(generator).
+		WithConnectionInitializer(initializer, time.Second).
+		WithTotalRequests(20)
+
+	workerGroup := workers.NewWorkerGroup(groupOptions)
+	loadGenerationResponseChannel := workerGroup.Run()
+
+	go func() {
+		for response := range loadGenerationResponseChannel {
+			assert.Nil(t, response.Err)
+		}
+	}()
+
+	workerGroup.WaitTillDone()
+	close(loadGenerationResponseChannel)
+
+	assert.Equal(t, 2, len(generator.sessions))
+	assert.Equal(t, 20, generator.sessions["+OK 1\n"]+generator.sessions["+OK 2\n"])
+	assert.Equal(t, uint(2), workerGroup.DialMetrics().TotalInitializations)
+	assert.Equal(t, uint(0), workerGroup.DialMetrics().ErrorCount)
+}
+
+func TestFailsTheHandshakeOfTheConnectionsWithAnUnexpectedAck(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:8110")
+	assert.Nil(t, err)
+	defer func() {
+		_ = listener.Close()
+	}()
+	serveHandshake(listener, "ERR unauthorized")
+
+	ackFramer, err := report.NewDelimiterResponseFramer([]byte("\n"))
+	assert.Nil(t, err)
+	ackValidator, err := report.NewPrefixResponseValidator([]byte("+OK"))
+	assert.Nil(t, err)
+	initializer, err := workers.NewHandshakeConnectionInitializer([]byte("AUTH\n"), ackFramer, ackValidator)
+	assert.Nil(t, err)
+
+	groupOptions := workers.NewGroupOptionsFullyLoaded(
+		2,
+		1,
+		payload.NewConstantPayloadGenerator([]byte("HelloWorld")),
+		"localhost:8110",
+		3*time.Second,
+		1000,
+		time.Second,
+	).WithConnectionInitializer(initializer, time.Second).WithTotalRequests(4)
+
+	workerGroup := workers.NewWorkerGroup(groupOptions)
+	loadGenerationResponseChannel := workerGroup.Run()
+
+	go func() {
+		for response := range loadGenerationResponseChannel {
+			assert.Equal(t, workers.ErrNilConnection, response.Err)
+		}
+	}()
+
+	workerGroup.WaitTillDone()
+	close(loadGenerationResponseChannel)
+
+	dialMetrics := workerGroup.DialMetrics()
+	assert.True(t, dialMetrics.TotalInitializations > 0)
+	assert.Equal(t, dialMetrics.TotalInitializations, dialMetrics.ErrorCount)
+	for errorType := range dialMetrics.ErrorCountByType {
+		assert.Contains(t, errorType, workers.ErrConnectionInitialization.Error())
+	}
+}
+
 func TestSendsARequestAndReadsResponseWithSingleConnection(t *testing.T) {
 	payloadSizeBytes, responseSizeBytes := int64(10), int64(10)
 	server, err := NewEchoServer("tcp", "localhost:8082", payloadSizeBytes)
@@ -78,13 +871,17 @@ func TestSendsARequestAndReadsResponseWithSingleConnection(t *testing.T) {
 	concurrency, totalRequests := uint(10), uint(20)
 	responseChannel := make(chan report.SubjectServerResponse)
 
+	responseReader, err := report.NewResponseReader(responseSizeBytes, 100*time.Millisecond, responseChannel)
+	assert.Nil(t, err)
+
 	defer func() {
 		server.stop()
+		responseReader.Close()
 		close(responseChannel)
 	}()
 
 	workerGroup := workers.NewWorkerGroupWithResponseReader(
-		workers.NewGroupOptions(concurrency, payload.NewConstantPayloadGenerator([]byte("HelloWorld")), "localhost:8082", 2*time.Millisecond), report.NewResponseReader(responseSizeBytes, 100*time.Millisecond, responseChannel),
+		workers.NewGroupOptions(concurrency, payload.NewConstantPayloadGenerator([]byte("HelloWorld")), "localhost:8082", 2*time.Millisecond), responseReader,
 	)
 	loadGenerationResponseChannel := workerGroup.Run()
 
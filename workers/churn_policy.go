@@ -0,0 +1,79 @@
+package workers
+
+import (
+	"sync"
+	"time"
+)
+
+// churnTick is the interval at which the connectionChurner checks the lifetime of the connections and
+// churns the connections that are due as per ChurnPolicy.NewConnectionsPerSecond.
+const churnTick = 10 * time.Millisecond
+
+// ChurnPolicy defines how the WorkerGroup churns (closes and re-opens) the connections, to load the connection
+// setup and teardown paths of the target server.
+// RequestsPerConnection: a connection is churned after the given number of requests are sent on it.
+// ConnectionLifetime: a connection is churned once it has been open for the given duration.
+// NewConnectionsPerSecond: the connections are churned one after the other (round-robin) at the given rate.
+// Each of them is disabled if it is zero, and they can be combined.
+// A churned connection is re-opened before it is closed, so its workers do not stop sending requests, but the
+// responses in flight on the closed connection are not read.
+type ChurnPolicy struct {
+	RequestsPerConnection   uint
+	ConnectionLifetime      time.Duration
+	NewConnectionsPerSecond float64
+}
+
+// enabled returns true if the connections are to be churned.
+func (policy ChurnPolicy) enabled() bool {
+	return policy.RequestsPerConnection > 0 || policy.ConnectionLifetime > 0 || policy.NewConnectionsPerSecond > 0
+}
+
+// connectionChurner churns the connections that exceed the ChurnPolicy.ConnectionLifetime, and the connections
+// that are due as per ChurnPolicy.NewConnectionsPerSecond.
+// The connections that exceed ChurnPolicy.RequestsPerConnection are churned by the reconnectingConnection itself.
+type connectionChurner struct {
+	policy      ChurnPolicy
+	connections []*reconnectingConnection
+	stopChannel chan struct{}
+}
+
+// run runs the connectionChurner.
+func (churner connectionChurner) run(wg *sync.WaitGroup) {
+	go func() {
+		defer wg.Done()
+		churner.churnConnections()
+	}()
+}
+
+// churnConnections churns the connections till the stopChannel is closed.
+func (churner connectionChurner) churnConnections() {
+	if len(churner.connections) == 0 ||
+		(churner.policy.ConnectionLifetime <= 0 && churner.policy.NewConnectionsPerSecond <= 0) {
+		return
+	}
+	ticker := time.NewTicker(churnTick)
+	defer ticker.Stop()
+
+	startTime := time.Now()
+	churnedConnections := uint64(0)
+	for {
+		select {
+		case <-churner.stopChannel:
+			return
+		case now := <-ticker.C:
+			if churner.policy.ConnectionLifetime > 0 {
+				for _, connection := range churner.connections {
+					if connection.age() >= churner.policy.ConnectionLifetime {
+						connection.churn()
+					}
+				}
+			}
+			if churner.policy.NewConnectionsPerSecond > 0 {
+				dueConnections := uint64(now.Sub(startTime).Seconds() * churner.policy.NewConnectionsPerSecond)
+				for ; churnedConnections < dueConnections; churnedConnections++ {
+					churner.connections[churnedConnections%uint64(len(churner.connections))].churn()
+				}
+			}
+		}
+	}
+}
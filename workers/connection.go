@@ -0,0 +1,97 @@
+package workers
+
+import (
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+)
+
+// trackedConnection is a net.Conn that keeps track of the connections that are alive.
+// A connection is considered alive from the time it is established till the time it is closed.
+// session is the state returned by the ConnectionInitializer, it is nil if the connection is not initialized.
+// registry (if any) is the connectionRegistry the connection is removed from, once it is closed.
+type trackedConnection struct {
+	net.Conn
+	closeOnce        sync.Once
+	connectionsAlive *atomic.Int64
+	session          any
+	registry         *connectionRegistry
+}
+
+// connectionRegistry holds the connections that are alive, so that they can be closed once the workers are done.
+type connectionRegistry struct {
+	lock        sync.Mutex
+	connections map[*trackedConnection]struct{}
+}
+
+// newTrackedConnection creates a new instance of trackedConnection and marks it alive.
+func newTrackedConnection(connection net.Conn, connectionsAlive *atomic.Int64) *trackedConnection {
+	connectionsAlive.Add(1)
+	return &trackedConnection{
+		Conn:             connection,
+		connectionsAlive: connectionsAlive,
+	}
+}
+
+// Close closes the underlying connection.
+// The connection is marked not alive only once, even if Close is called multiple times.
+func (connection *trackedConnection) Close() error {
+	connection.closeOnce.Do(func() {
+		connection.connectionsAlive.Add(-1)
+		if connection.registry != nil {
+			connection.registry.remove(connection)
+		}
+	})
+	return connection.Conn.Close()
+}
+
+// newConnectionRegistry creates a new instance of connectionRegistry.
+func newConnectionRegistry() *connectionRegistry {
+	return &connectionRegistry{connections: make(map[*trackedConnection]struct{})}
+}
+
+// add adds the connection to the registry, the connection is removed from the registry once it is closed.
+func (registry *connectionRegistry) add(connection *trackedConnection) {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	connection.registry = registry
+	registry.connections[connection] = struct{}{}
+}
+
+// remove removes the connection from the registry.
+func (registry *connectionRegistry) remove(connection *trackedConnection) {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	delete(registry.connections, connection)
+}
+
+// closeAll closes all the connections in the registry.
+func (registry *connectionRegistry) closeAll() {
+	registry.lock.Lock()
+	connections := make([]*trackedConnection, 0, len(registry.connections))
+	for connection := range registry.connections {
+		connections = append(connections, connection)
+	}
+	registry.lock.Unlock()
+
+	for _, connection := range connections {
+		_ = connection.Close()
+	}
+}
+
+// sessionOf returns the session of the connection (see ConnectionInitializer).
+// The session of a reconnectingConnection is the session of its current net.Conn. It is nil if the connection is
+// not initialized, is down, or is a hashRoutingConnection (the connection of a request is decided after its
+// payload is generated).
+func sessionOf(connection io.WriteCloser) any {
+	if reconnecting, ok := connection.(*reconnectingConnection); ok {
+		connection = reconnecting.current()
+	}
+	if tracked, ok := connection.(*trackedConnection); ok {
+		return tracked.session
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package workers
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/SarthakMakhija/blast-core/report"
+)
+
+// ErrConnectionInitialization is the error that is wrapped by the errors of the ConnectionInitializer, so that
+// the failed initializations are distinguishable from the failed dials in report.DialMetrics.
+var ErrConnectionInitialization = errors.New("connection initialization failed")
+
+// ErrEmptyHandshake is the error that is returned when the handshake of HandshakeConnectionInitializer is empty.
+var ErrEmptyHandshake = errors.New("handshake cannot be empty")
+
+// ConnectionInitializer initializes each connection after it is established (and after the TLS handshake, if any),
+// before the workers send the requests on it and before the responses are read from it. For example: a server
+// may require an authentication frame, plus an ack, before any requests on a connection.
+// Initialize may write to and read from the connection, the deadline of the connection is set to the
+// initialization timeout (see GroupOptions.WithConnectionInitializer) during Initialize.
+// The returned session is the state of the connection (for example: a session token from the ack), that is
+// available to the payload.ContextualPayloadGenerator in payload.RequestContext.
+// The connection is closed if Initialize returns an error, and the error is reported as a connection error.
+// Initialize is invoked concurrently for the connections, and again each time a connection is re-established.
+type ConnectionInitializer interface {
+	Initialize(connection net.Conn) (session any, err error)
+}
+
+// ConnectionInitializerFunc adapts a function to a ConnectionInitializer.
+type ConnectionInitializerFunc func(connection net.Conn) (any, error)
+
+// Initialize invokes the function.
+func (initializer ConnectionInitializerFunc) Initialize(connection net.Conn) (any, error) {
+	return initializer(connection)
+}
+
+// HandshakeConnectionInitializer writes the handshake on each connection, and reads the ack that is framed
+// by the report.ResponseFramer. The ack is validated by the report.ResponseValidator (if any), and is the
+// session of the connection.
+type HandshakeConnectionInitializer struct {
+	handshake    []byte
+	ackFramer    report.ResponseFramer
+	ackValidator report.ResponseValidator
+}
+
+// NewHandshakeConnectionInitializer creates a new instance of HandshakeConnectionInitializer.
+// A nil ackValidator accepts any ack.
+func NewHandshakeConnectionInitializer(
+	handshake []byte,
+	ackFramer report.ResponseFramer,
+	ackValidator report.ResponseValidator,
+) (HandshakeConnectionInitializer, error) {
+	if len(handshake) == 0 {
+		return HandshakeConnectionInitializer{}, ErrEmptyHandshake
+	}
+	return HandshakeConnectionInitializer{
+		handshake:    handshake,
+		ackFramer:    ackFramer,
+		ackValidator: ackValidator,
+	}, nil
+}
+
+// Initialize writes the handshake and returns the ack read from the connection.
+// The ack is read one byte at a time, so that the bytes after the ack are left for the report.ResponseReader.
+func (initializer HandshakeConnectionInitializer) Initialize(connection net.Conn) (any, error) {
+	if _, err := connection.Write(initializer.handshake); err != nil {
+		return nil, err
+	}
+	ack, err := initializer.ackFramer.Frame(bufio.NewReaderSize(singleByteReader{reader: connection}, 16))
+	if err != nil {
+		return nil, err
+	}
+	if initializer.ackValidator != nil {
+		if err := initializer.ackValidator.Validate(ack); err != nil {
+			return nil, err
+		}
+	}
+	return ack, nil
+}
+
+// singleByteReader reads at most one byte in each Read.
+type singleByteReader struct {
+	reader io.Reader
+}
+
+// Read reads at most one byte from the underlying io.Reader.
+func (reader singleByteReader) Read(buffer []byte) (int, error) {
+	if len(buffer) == 0 {
+		return 0, nil
+	}
+	return reader.reader.Read(buffer[:1])
+}
+
+// initialize initializes the connection using the ConnectionInitializer within the timeout, and records the
+// time taken by the initialization.
+// The connection is closed if the initialization fails, and the returned error wraps ErrConnectionInitialization.
+func initialize(
+	connection net.Conn,
+	initializer ConnectionInitializer,
+	timeout time.Duration,
+	dialRecorder *report.DialRecorder,
+) (any, error) {
+	initializationStartTime := time.Now()
+	if timeout > 0 {
+		_ = connection.SetDeadline(initializationStartTime.Add(timeout))
+	}
+	session, err := initializer.Initialize(connection)
+	if err != nil {
+		err = fmt.Errorf("%w: %v", ErrConnectionInitialization, err)
+	}
+	dialRecorder.RecordInitialization(time.Since(initializationStartTime), err)
+	if err != nil {
+		_ = connection.Close()
+		return nil, err
+	}
+	if timeout > 0 {
+		_ = connection.SetDeadline(time.Time{})
+	}
+	return session, nil
+}
@@ -0,0 +1,121 @@
+package workers
+
+import (
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/SarthakMakhija/blast-core/report"
+)
+
+func newAckValidator(t *testing.T) report.ResponseValidator {
+	validator, err := report.NewPrefixResponseValidator([]byte("+OK"))
+	assert.Nil(t, err)
+	return validator
+}
+
+func TestCreatesAHandshakeConnectionInitializerWithAnEmptyHandshake(t *testing.T) {
+	responseFramer, err := report.NewFixedSizeResponseFramer(3)
+	assert.Nil(t, err)
+
+	_, err = NewHandshakeConnectionInitializer(nil, responseFramer, nil)
+	assert.Equal(t, ErrEmptyHandshake, err)
+}
+
+func TestInitializesTheConnectionWithTheHandshake(t *testing.T) {
+	client, server := net.Pipe()
+	defer func() {
+		_ = client.Close()
+		_ = server.Close()
+	}()
+
+	go func() {
+		handshake := make([]byte, 4)
+		_, _ = server.Read(handshake)
+		if string(handshake) == "AUTH" {
+			_, _ = server.Write([]byte("+OK token\nfirst"))
+		}
+	}()
+
+	framer, err := report.NewDelimiterResponseFramer([]byte("\n"))
+	assert.Nil(t, err)
+	initializer, err := NewHandshakeConnectionInitializer([]byte("AUTH"), framer, newAckValidator(t))
+	assert.Nil(t, err)
+
+	session, err := initializer.Initialize(client)
+	assert.Nil(t, err)
+	assert.Equal(t, "+OK token\n", string(session.([]byte)))
+
+	remaining := make([]byte, 5)
+	_, err = client.Read(remaining)
+	assert.Nil(t, err)
+	assert.Equal(t, "first", string(remaining))
+}
+
+func TestInitializesTheConnectionWithAnUnexpectedAck(t *testing.T) {
+	client, server := net.Pipe()
+	defer func() {
+		_ = client.Close()
+		_ = server.Close()
+	}()
+
+	go func() {
+		handshake := make([]byte, 4)
+		_, _ = server.Read(handshake)
+		_, _ = server.Write([]byte("ERR"))
+	}()
+
+	responseFramer, err := report.NewFixedSizeResponseFramer(3)
+	assert.Nil(t, err)
+
+	initializer, err := NewHandshakeConnectionInitializer([]byte("AUTH"), responseFramer, newAckValidator(t))
+	assert.Nil(t, err)
+
+	_, err = initializer.Initialize(client)
+	assert.Equal(t, report.ErrResponsePrefixMismatch, err)
+}
+
+func TestInitializesTheConnectionAndRecordsTheInitialization(t *testing.T) {
+	client, server := net.Pipe()
+	defer func() {
+		_ = server.Close()
+	}()
+
+	recorder := report.NewDialRecorder()
+	initializer := ConnectionInitializerFunc(func(connection net.Conn) (any, error) {
+		return "token", nil
+	})
+	session, err := initialize(client, initializer, time.Second, recorder)
+	assert.Nil(t, err)
+	assert.Equal(t, "token", session)
+
+	metrics := recorder.Metrics()
+	assert.Equal(t, uint(1), metrics.TotalInitializations)
+	assert.Equal(t, uint(0), metrics.ErrorCount)
+}
+
+func TestFailsTheInitializationOfTheConnectionAfterTheTimeout(t *testing.T) {
+	client, server := net.Pipe()
+	defer func() {
+		_ = server.Close()
+	}()
+
+	recorder := report.NewDialRecorder()
+	initializer := ConnectionInitializerFunc(func(connection net.Conn) (any, error) {
+		_, err := connection.Read(make([]byte, 1))
+		return nil, err
+	})
+	_, err := initialize(client, initializer, 10*time.Millisecond, recorder)
+	assert.True(t, errors.Is(err, ErrConnectionInitialization))
+
+	metrics := recorder.Metrics()
+	assert.Equal(t, uint(1), metrics.TotalInitializations)
+	assert.Equal(t, uint(1), metrics.ErrorCount)
+
+	_, err = client.Write([]byte("closed"))
+	assert.True(t, errors.Is(err, net.ErrClosed) || errors.Is(err, io.ErrClosedPipe))
+}
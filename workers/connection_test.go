@@ -0,0 +1,86 @@
+package workers
+
+import (
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrackedConnectionIsAliveTillItIsClosed(t *testing.T) {
+	client, server := net.Pipe()
+	defer func() {
+		_ = server.Close()
+	}()
+
+	var connectionsAlive atomic.Int64
+	connection := newTrackedConnection(client, &connectionsAlive)
+	assert.Equal(t, int64(1), connectionsAlive.Load())
+
+	_ = connection.Close()
+	assert.Equal(t, int64(0), connectionsAlive.Load())
+}
+
+func TestTrackedConnectionIsMarkedNotAliveOnlyOnce(t *testing.T) {
+	client, server := net.Pipe()
+	defer func() {
+		_ = server.Close()
+	}()
+
+	var connectionsAlive atomic.Int64
+	connection := newTrackedConnection(client, &connectionsAlive)
+
+	_ = connection.Close()
+	_ = connection.Close()
+	assert.Equal(t, int64(0), connectionsAlive.Load())
+}
+
+func TestSessionOfATrackedConnection(t *testing.T) {
+	client, server := net.Pipe()
+	defer func() {
+		_ = server.Close()
+		_ = client.Close()
+	}()
+
+	var connectionsAlive atomic.Int64
+	connection := newTrackedConnection(client, &connectionsAlive)
+	connection.session = "token"
+
+	assert.Equal(t, "token", sessionOf(connection))
+	assert.Nil(t, sessionOf(&BytesWriteCloser{}))
+}
+
+func TestConnectionRegistryClosesAllTheConnections(t *testing.T) {
+	client, server := net.Pipe()
+	otherClient, otherServer := net.Pipe()
+	defer func() {
+		_ = server.Close()
+		_ = otherServer.Close()
+	}()
+
+	var connectionsAlive atomic.Int64
+	registry := newConnectionRegistry()
+	registry.add(newTrackedConnection(client, &connectionsAlive))
+	registry.add(newTrackedConnection(otherClient, &connectionsAlive))
+	assert.Equal(t, int64(2), connectionsAlive.Load())
+
+	registry.closeAll()
+	assert.Equal(t, int64(0), connectionsAlive.Load())
+	assert.Equal(t, 0, len(registry.connections))
+}
+
+func TestConnectionRegistryRemovesTheClosedConnection(t *testing.T) {
+	client, server := net.Pipe()
+	defer func() {
+		_ = server.Close()
+	}()
+
+	var connectionsAlive atomic.Int64
+	registry := newConnectionRegistry()
+	connection := newTrackedConnection(client, &connectionsAlive)
+	registry.add(connection)
+
+	_ = connection.Close()
+	assert.Equal(t, 0, len(registry.connections))
+}
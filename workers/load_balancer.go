@@ -0,0 +1,248 @@
+package workers
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math/rand"
+	"sort"
+	"strconv"
+
+	"github.com/SarthakMakhija/blast-core/report"
+)
+
+// virtualNodesPerWeight is the number of the points of a target (per unit of its weight) on the hashRing.
+const virtualNodesPerWeight = 100
+
+// LoadBalancingStrategy defines how the WorkerGroup distributes the load across multiple target servers.
+// RoundRobinBalancing: the connections are assigned to the targets one after the other.
+// WeightedBalancing: the connections are assigned to the targets in proportion to their weights (smooth weighted
+// round-robin).
+// RandomBalancing: each connection is assigned to a random target.
+// ConsistentHashBalancing: each connection is opened with all the targets, and each request is sent to the target
+// that owns the key of its payload (HashKey) on a consistent hash ring. The requests with the same key are always
+// sent to the same target, and the share of each target is in proportion to its weight.
+type LoadBalancingStrategy uint8
+
+const (
+	RoundRobinBalancing LoadBalancingStrategy = iota
+	WeightedBalancing
+	RandomBalancing
+	ConsistentHashBalancing
+)
+
+// ParseLoadBalancingStrategy returns the LoadBalancingStrategy identified by the name: roundrobin, weighted,
+// random or hash.
+func ParseLoadBalancingStrategy(name string) (LoadBalancingStrategy, error) {
+	switch name {
+	case "roundrobin":
+		return RoundRobinBalancing, nil
+	case "weighted":
+		return WeightedBalancing, nil
+	case "random":
+		return RandomBalancing, nil
+	case "hash":
+		return ConsistentHashBalancing, nil
+	default:
+		return RoundRobinBalancing, fmt.Errorf("unsupported load balancing strategy %v", name)
+	}
+}
+
+// String returns the name of the LoadBalancingStrategy.
+func (strategy LoadBalancingStrategy) String() string {
+	switch strategy {
+	case WeightedBalancing:
+		return "weighted"
+	case RandomBalancing:
+		return "random"
+	case ConsistentHashBalancing:
+		return "hash"
+	default:
+		return "roundrobin"
+	}
+}
+
+// HashKey identifies the key of a payload for the ConsistentHashBalancing strategy: Length bytes starting at
+// Offset. The key extends till the end of the payload if Length is zero, or if the payload is shorter than
+// Offset+Length (the key is then the bytes that are available from Offset). The entire payload is the key if
+// the payload is not longer than Offset.
+type HashKey struct {
+	Offset int
+	Length int
+}
+
+// of returns the key of the payload.
+func (key HashKey) of(payload []byte) []byte {
+	if key.Offset < 0 || key.Offset >= len(payload) {
+		return payload
+	}
+	if key.Length <= 0 || key.Offset+key.Length > len(payload) {
+		return payload[key.Offset:]
+	}
+	return payload[key.Offset : key.Offset+key.Length]
+}
+
+// targetBalancer assigns the connections to the targets as per the LoadBalancingStrategy.
+type targetBalancer struct {
+	strategy LoadBalancingStrategy
+	targets  []Target
+	sequence []int
+}
+
+// newTargetBalancer creates a new instance of targetBalancer.
+func newTargetBalancer(targets []Target, strategy LoadBalancingStrategy) targetBalancer {
+	balancer := targetBalancer{strategy: strategy, targets: targets}
+	if strategy == WeightedBalancing {
+		balancer.sequence = weightedSequence(targets)
+	}
+	return balancer
+}
+
+// targetOf returns the target of the connection with the given index (starting at 0).
+func (balancer targetBalancer) targetOf(connectionIndex int) Target {
+	switch balancer.strategy {
+	case WeightedBalancing:
+		return balancer.targets[balancer.sequence[connectionIndex%len(balancer.sequence)]]
+	case RandomBalancing:
+		return balancer.targets[rand.Intn(len(balancer.targets))]
+	default:
+		return balancer.targets[connectionIndex%len(balancer.targets)]
+	}
+}
+
+// weightedSequence returns one cycle of the smooth weighted round-robin over the targets: each target appears
+// as many times as its weight, and the appearances of a target are spread across the cycle.
+func weightedSequence(targets []Target) []int {
+	totalWeight := 0
+	for _, target := range targets {
+		totalWeight += int(target.weight())
+	}
+	currentWeights := make([]int, len(targets))
+	sequence := make([]int, 0, totalWeight)
+	for len(sequence) < totalWeight {
+		selected := 0
+		for index, target := range targets {
+			currentWeights[index] += int(target.weight())
+			if currentWeights[index] > currentWeights[selected] {
+				selected = index
+			}
+		}
+		currentWeights[selected] -= totalWeight
+		sequence = append(sequence, selected)
+	}
+	return sequence
+}
+
+// hashRing is the consistent hash ring of the targets, each target has virtualNodesPerWeight points on the
+// ring for each unit of its weight.
+type hashRing struct {
+	points  []uint64
+	targets []int
+}
+
+// newHashRing creates a new instance of hashRing.
+func newHashRing(targets []Target) hashRing {
+	type point struct {
+		hash   uint64
+		target int
+	}
+	var points []point
+	for index, target := range targets {
+		for node := 0; node < int(target.weight())*virtualNodesPerWeight; node++ {
+			points = append(points, point{hash: hashOf([]byte(target.String() + "#" + strconv.Itoa(node))), target: index})
+		}
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].hash < points[j].hash
+	})
+	ring := hashRing{}
+	for _, point := range points {
+		ring.points = append(ring.points, point.hash)
+		ring.targets = append(ring.targets, point.target)
+	}
+	return ring
+}
+
+// targetOf returns the index of the target that owns the key: the target of the first point on the ring at or
+// after the hash of the key.
+func (ring hashRing) targetOf(key []byte) int {
+	hash := hashOf(key)
+	index := sort.Search(len(ring.points), func(i int) bool {
+		return ring.points[i] >= hash
+	})
+	if index == len(ring.points) {
+		index = 0
+	}
+	return ring.targets[index]
+}
+
+// hashOf returns the 64-bit FNV-1a hash of the data, mixed with the finalizer of MurmurHash3 so that the keys
+// that differ in a few bytes are spread across the ring.
+func hashOf(data []byte) uint64 {
+	hash := fnv.New64a()
+	_, _ = hash.Write(data)
+
+	mixed := hash.Sum64()
+	mixed ^= mixed >> 33
+	mixed *= 0xff51afd7ed558ccd
+	mixed ^= mixed >> 33
+	mixed *= 0xc4ceb9fe1a85ec53
+	mixed ^= mixed >> 33
+	return mixed
+}
+
+// hashRoutingConnection is the connection shared by the workers of a connection with the
+// ConsistentHashBalancing strategy. It holds a connection with each target, and routes each request to the
+// connection with the target that owns the key of the request payload.
+// A connection with a target is nil if it could not be established.
+type hashRoutingConnection struct {
+	ring          hashRing
+	key           HashKey
+	connections   []io.WriteCloser
+	connectionIds []int
+	targets       []string
+}
+
+// newHashRoutingConnection creates a new instance of hashRoutingConnection.
+func newHashRoutingConnection(ring hashRing, key HashKey) *hashRoutingConnection {
+	return &hashRoutingConnection{ring: ring, key: key}
+}
+
+// add adds the connection (nil if it could not be established) with the next target.
+func (routing *hashRoutingConnection) add(connection io.WriteCloser, connectionId int, target string) {
+	if connection == nil {
+		connectionId = report.NilConnectionId
+	}
+	routing.connections = append(routing.connections, connection)
+	routing.connectionIds = append(routing.connectionIds, connectionId)
+	routing.targets = append(routing.targets, target)
+}
+
+// route returns the connection (nil if it could not be established), the connection id and the target of the
+// request payload.
+func (routing *hashRoutingConnection) route(payload []byte) (io.WriteCloser, int, string) {
+	index := routing.ring.targetOf(routing.key.of(payload))
+	return routing.connections[index], routing.connectionIds[index], routing.targets[index]
+}
+
+// Write writes the payload on the connection with the target of the payload.
+func (routing *hashRoutingConnection) Write(payload []byte) (int, error) {
+	connection, _, _ := routing.route(payload)
+	if connection == nil {
+		return 0, ErrNilConnection
+	}
+	return connection.Write(payload)
+}
+
+// Close closes the connections with all the targets.
+func (routing *hashRoutingConnection) Close() error {
+	var err error
+	for _, connection := range routing.connections {
+		if connection != nil {
+			if closeErr := connection.Close(); closeErr != nil {
+				err = closeErr
+			}
+		}
+	}
+	return err
+}
@@ -0,0 +1,126 @@
+package workers
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLoadBalancingStrategy(t *testing.T) {
+	strategy, err := ParseLoadBalancingStrategy("weighted")
+	assert.Nil(t, err)
+	assert.Equal(t, WeightedBalancing, strategy)
+	assert.Equal(t, "weighted", strategy.String())
+
+	strategy, err = ParseLoadBalancingStrategy("hash")
+	assert.Nil(t, err)
+	assert.Equal(t, ConsistentHashBalancing, strategy)
+}
+
+func TestParseAnUnsupportedLoadBalancingStrategy(t *testing.T) {
+	_, err := ParseLoadBalancingStrategy("leastconn")
+	assert.Error(t, err)
+}
+
+func TestHashKeyOfAPayload(t *testing.T) {
+	payload := []byte("user-42:HelloWorld")
+
+	assert.Equal(t, []byte("user-42"), HashKey{Offset: 0, Length: 7}.of(payload))
+	assert.Equal(t, []byte("HelloWorld"), HashKey{Offset: 8}.of(payload))
+	assert.Equal(t, []byte("HelloWorld"), HashKey{Offset: 8, Length: 20}.of(payload))
+	assert.Equal(t, payload, HashKey{Offset: 30}.of(payload))
+}
+
+func TestRoundRobinTargets(t *testing.T) {
+	targets := []Target{{Network: "tcp", Address: "node1:8080"}, {Network: "tcp", Address: "node2:8080"}}
+	balancer := newTargetBalancer(targets, RoundRobinBalancing)
+
+	assert.Equal(t, targets[0], balancer.targetOf(0))
+	assert.Equal(t, targets[1], balancer.targetOf(1))
+	assert.Equal(t, targets[0], balancer.targetOf(2))
+}
+
+func TestWeightedTargets(t *testing.T) {
+	targets := []Target{
+		{Network: "tcp", Address: "node1:8080", Weight: 3},
+		{Network: "tcp", Address: "node2:8080"},
+	}
+	balancer := newTargetBalancer(targets, WeightedBalancing)
+
+	var addresses []string
+	for index := 0; index < 8; index++ {
+		addresses = append(addresses, balancer.targetOf(index).Address)
+	}
+	assert.Equal(
+		t,
+		[]string{"node1:8080", "node1:8080", "node2:8080", "node1:8080", "node1:8080", "node1:8080", "node2:8080", "node1:8080"},
+		addresses,
+	)
+}
+
+func TestRandomTargets(t *testing.T) {
+	targets := []Target{{Network: "tcp", Address: "node1:8080"}, {Network: "tcp", Address: "node2:8080"}}
+	balancer := newTargetBalancer(targets, RandomBalancing)
+
+	for index := 0; index < 10; index++ {
+		assert.Contains(t, targets, balancer.targetOf(index))
+	}
+}
+
+func TestHashRingRoutesTheSameKeyToTheSameTarget(t *testing.T) {
+	ring := newHashRing([]Target{
+		{Network: "tcp", Address: "node1:8080"},
+		{Network: "tcp", Address: "node2:8080"},
+		{Network: "tcp", Address: "node3:8080"},
+	})
+	assert.Equal(t, ring.targetOf([]byte("user-42")), ring.targetOf([]byte("user-42")))
+}
+
+func TestHashRingDistributesTheKeysAcrossTheTargets(t *testing.T) {
+	ring := newHashRing([]Target{
+		{Network: "tcp", Address: "node1:8080"},
+		{Network: "tcp", Address: "node2:8080", Weight: 3},
+	})
+	keysByTarget := make(map[int]int)
+	key := make([]byte, 8)
+	for index := uint64(0); index < 10000; index++ {
+		binary.BigEndian.PutUint64(key, index)
+		keysByTarget[ring.targetOf(key)]++
+	}
+	assert.True(t, keysByTarget[0] > 1500 && keysByTarget[0] < 3500)
+	assert.True(t, keysByTarget[1] > 6500 && keysByTarget[1] < 8500)
+}
+
+func TestHashRoutingConnectionRoutesThePayload(t *testing.T) {
+	targets := []Target{{Network: "tcp", Address: "node1:8080"}, {Network: "tcp", Address: "node2:8080"}}
+	ring := newHashRing(targets)
+	routing := newHashRoutingConnection(ring, HashKey{Offset: 0, Length: 7})
+
+	connections := []*StallingWriteCloser{{}, {}}
+	routing.add(connections[0], 0, "tcp://node1:8080")
+	routing.add(connections[1], 1, "tcp://node2:8080")
+
+	payload := []byte("user-42:HelloWorld")
+	_, connectionId, target := routing.route(payload)
+	assert.Equal(t, ring.targetOf([]byte("user-42")), connectionId)
+	assert.Equal(t, targets[connectionId].String(), target)
+
+	_, err := routing.Write(payload)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, connections[connectionId].writes)
+	assert.Equal(t, 0, connections[1-connectionId].writes)
+	assert.Nil(t, routing.Close())
+}
+
+func TestHashRoutingConnectionWithAnUnestablishedConnection(t *testing.T) {
+	routing := newHashRoutingConnection(newHashRing([]Target{{Network: "tcp", Address: "node1:8080"}}), HashKey{})
+	routing.add(nil, 0, "tcp://node1:8080")
+
+	connection, connectionId, _ := routing.route([]byte("HelloWorld"))
+	assert.Nil(t, connection)
+	assert.Equal(t, -1, connectionId)
+
+	_, err := routing.Write([]byte("HelloWorld"))
+	assert.ErrorIs(t, err, ErrNilConnection)
+}
@@ -0,0 +1,242 @@
+package workers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SarthakMakhija/blast-core/report"
+)
+
+// ErrEmptyLoadProfile is the error that is returned when the LoadProfile does not have any LoadStage.
+var ErrEmptyLoadProfile = errors.New("load profile must have at least one stage")
+
+// LoadStage defines a single stage of the LoadProfile.
+// The arrival rate (requests per second across all the workers) changes linearly from StartRate to EndRate
+// over the Duration of the stage: the stage is a ramp if the rates differ, and a steady state if they are equal.
+// Workers is the number of workers that send the requests in the stage, all the workers
+// (GroupOptions.concurrency) send the requests if it is zero.
+// Name identifies the stage in the report, the String representation of the stage is used if it is empty.
+type LoadStage struct {
+	Name      string
+	Duration  time.Duration
+	StartRate float64
+	EndRate   float64
+	Workers   uint
+}
+
+// LoadProfile is a sequence of LoadStages, for example: ramp up from 0 to 5000 requests per second over 60s,
+// hold 5000 requests per second for 10m, step to 8000 requests per second for 2m and ramp down to 0 over 30s.
+// The stages run one after the other, and the total duration of the LoadProfile is the sum of the durations
+// of all the stages.
+type LoadProfile struct {
+	stages []LoadStage
+}
+
+// NewLoadProfile creates a new instance of LoadProfile.
+// It returns an error if there are no stages, or if any stage has a non-positive duration or a negative rate.
+func NewLoadProfile(stages ...LoadStage) (LoadProfile, error) {
+	if len(stages) == 0 {
+		return LoadProfile{}, ErrEmptyLoadProfile
+	}
+	profileStages := make([]LoadStage, 0, len(stages))
+	for index, stage := range stages {
+		if stage.Duration <= 0 {
+			return LoadProfile{}, fmt.Errorf("stage %d: duration must be greater than zero", index+1)
+		}
+		if stage.StartRate < 0 || stage.EndRate < 0 {
+			return LoadProfile{}, fmt.Errorf("stage %d: rate cannot be smaller than zero", index+1)
+		}
+		if stage.Name == "" {
+			stage.Name = stage.String()
+		}
+		profileStages = append(profileStages, stage)
+	}
+	return LoadProfile{stages: profileStages}, nil
+}
+
+// ParseLoadProfile parses the LoadProfile from its description.
+// The description is a comma separated list of stages, each stage is of the form: duration:rate[@workers]
+// or duration:startRate-endRate[@workers].
+// For example: "60s:0-5000,10m:5000,2m:8000@100,30s:8000-0" ramps up from 0 to 5000 requests per second
+// over 60s, holds 5000 requests per second for 10m, steps to 8000 requests per second with 100 workers for 2m
+// and ramps down to 0 over 30s.
+func ParseLoadProfile(description string) (LoadProfile, error) {
+	var stages []LoadStage
+	for index, stageDescription := range strings.Split(description, ",") {
+		stage, err := parseLoadStage(strings.TrimSpace(stageDescription))
+		if err != nil {
+			return LoadProfile{}, fmt.Errorf("stage %d: %w", index+1, err)
+		}
+		stages = append(stages, stage)
+	}
+	return NewLoadProfile(stages...)
+}
+
+// parseLoadStage parses a single LoadStage of the form: duration:startRate[-endRate][@workers].
+func parseLoadStage(description string) (LoadStage, error) {
+	durationAndRates := strings.SplitN(description, ":", 2)
+	if len(durationAndRates) != 2 {
+		return LoadStage{}, fmt.Errorf("%q must be of the form duration:rate[@workers]", description)
+	}
+	duration, err := time.ParseDuration(durationAndRates[0])
+	if err != nil {
+		return LoadStage{}, fmt.Errorf("invalid duration %q", durationAndRates[0])
+	}
+
+	rates, workers, hasWorkers := strings.Cut(durationAndRates[1], "@")
+	stage := LoadStage{Duration: duration}
+	if hasWorkers {
+		parsedWorkers, err := strconv.ParseUint(workers, 10, 0)
+		if err != nil || parsedWorkers == 0 {
+			return LoadStage{}, fmt.Errorf("invalid workers %q", workers)
+		}
+		stage.Workers = uint(parsedWorkers)
+	}
+
+	startRate, endRate, isRamp := strings.Cut(rates, "-")
+	if stage.StartRate, err = strconv.ParseFloat(startRate, 64); err != nil {
+		return LoadStage{}, fmt.Errorf("invalid rate %q", startRate)
+	}
+	stage.EndRate = stage.StartRate
+	if isRamp {
+		if stage.EndRate, err = strconv.ParseFloat(endRate, 64); err != nil {
+			return LoadStage{}, fmt.Errorf("invalid rate %q", endRate)
+		}
+	}
+	return stage, nil
+}
+
+// String returns the representation of the LoadStage in the form accepted by ParseLoadProfile.
+func (stage LoadStage) String() string {
+	rates := strconv.FormatFloat(stage.StartRate, 'f', -1, 64)
+	if stage.EndRate != stage.StartRate {
+		rates = rates + "-" + strconv.FormatFloat(stage.EndRate, 'f', -1, 64)
+	}
+	if stage.Workers > 0 {
+		return fmt.Sprintf("%v:%v@%d", stage.Duration, rates, stage.Workers)
+	}
+	return fmt.Sprintf("%v:%v", stage.Duration, rates)
+}
+
+// Stages returns the LoadStages.
+func (profile LoadProfile) Stages() []LoadStage {
+	return profile.stages
+}
+
+// TotalDuration returns the sum of the durations of all the stages.
+func (profile LoadProfile) TotalDuration() time.Duration {
+	totalDuration := time.Duration(0)
+	for _, stage := range profile.stages {
+		totalDuration += stage.Duration
+	}
+	return totalDuration
+}
+
+// MaxWorkers returns the maximum number of workers required by any stage, zero if all the stages use
+// all the workers.
+func (profile LoadProfile) MaxWorkers() uint {
+	maxWorkers := uint(0)
+	for _, stage := range profile.stages {
+		if stage.Workers > maxWorkers {
+			maxWorkers = stage.Workers
+		}
+	}
+	return maxWorkers
+}
+
+// ReportStages returns the stages of the LoadProfile for the report.Reporter, so that the metrics of each
+// stage are reported separately.
+func (profile LoadProfile) ReportStages() []report.ReportStage {
+	stages := make([]report.ReportStage, 0, len(profile.stages))
+	for _, stage := range profile.stages {
+		stages = append(stages, report.ReportStage{Name: stage.Name, Duration: stage.Duration})
+	}
+	return stages
+}
+
+// requestsDueBy returns the total number of requests that are due in the elapsed duration since the start.
+func (profile LoadProfile) requestsDueBy(elapsed time.Duration) uint64 {
+	requests := 0.0
+	for _, stage := range profile.stages {
+		if elapsed <= 0 {
+			break
+		}
+		inStage := elapsed
+		if inStage > stage.Duration {
+			inStage = stage.Duration
+		}
+		requests += stage.requestsDueBy(inStage)
+		elapsed -= inStage
+	}
+	return uint64(requests)
+}
+
+// scheduledAt returns the offset from the start at which the nth request is scheduled.
+// The offset is the solution of requestsDueBy(offset) = n in the stage that contains the nth request.
+func (profile LoadProfile) scheduledAt(request uint64) time.Duration {
+	stageStart := time.Duration(0)
+	remainingRequests := float64(request)
+	for _, stage := range profile.stages {
+		stageRequests := stage.requestsDueBy(stage.Duration)
+		if remainingRequests <= stageRequests {
+			return stageStart + stage.offsetOf(remainingRequests)
+		}
+		remainingRequests -= stageRequests
+		stageStart += stage.Duration
+	}
+	return stageStart
+}
+
+// maxRate returns the maximum arrival rate of any stage.
+func (profile LoadProfile) maxRate() float64 {
+	maxRate := 0.0
+	for _, stage := range profile.stages {
+		maxRate = math.Max(maxRate, math.Max(stage.StartRate, stage.EndRate))
+	}
+	return maxRate
+}
+
+// workersAt returns the number of workers of the stage that contains the elapsed duration since the start.
+func (profile LoadProfile) workersAt(elapsed time.Duration) uint {
+	for _, stage := range profile.stages {
+		if elapsed < stage.Duration {
+			return stage.Workers
+		}
+		elapsed -= stage.Duration
+	}
+	return profile.stages[len(profile.stages)-1].Workers
+}
+
+// requestsDueBy returns the number of requests that are due in the elapsed duration since the start of the stage.
+// It is the area under the (linear) rate: startRate*t + (endRate-startRate)*t^2/(2*duration).
+func (stage LoadStage) requestsDueBy(elapsed time.Duration) float64 {
+	seconds := elapsed.Seconds()
+	return stage.StartRate*seconds + stage.slope()*seconds*seconds/2
+}
+
+// offsetOf returns the offset from the start of the stage by which the given number of requests are due.
+// The discriminant is clamped to zero, since the floating point error makes it slightly negative for the last
+// request of a stage that ramps down to zero (and its square root would be NaN).
+func (stage LoadStage) offsetOf(requests float64) time.Duration {
+	if requests <= 0 {
+		return 0
+	}
+	slope := stage.slope()
+	var seconds float64
+	if slope == 0 {
+		seconds = requests / stage.StartRate
+	} else {
+		discriminant := math.Max(stage.StartRate*stage.StartRate+2*slope*requests, 0)
+		seconds = (-stage.StartRate + math.Sqrt(discriminant)) / slope
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// slope returns the change in the rate per second.
+func (stage LoadStage) slope() float64 {
+	return (stage.EndRate - stage.StartRate) / stage.Duration.Seconds()
+}
@@ -0,0 +1,110 @@
+package workers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/SarthakMakhija/blast-core/report"
+)
+
+func TestParsesALoadProfile(t *testing.T) {
+	profile, err := ParseLoadProfile("60s:0-5000,10m:5000,2m:8000@100,30s:8000-0")
+	assert.Nil(t, err)
+
+	assert.Equal(t, []LoadStage{
+		{Name: "1m0s:0-5000", Duration: time.Minute, StartRate: 0, EndRate: 5000},
+		{Name: "10m0s:5000", Duration: 10 * time.Minute, StartRate: 5000, EndRate: 5000},
+		{Name: "2m0s:8000@100", Duration: 2 * time.Minute, StartRate: 8000, EndRate: 8000, Workers: 100},
+		{Name: "30s:8000-0", Duration: 30 * time.Second, StartRate: 8000, EndRate: 0},
+	}, profile.Stages())
+	assert.Equal(t, 13*time.Minute+30*time.Second, profile.TotalDuration())
+	assert.Equal(t, uint(100), profile.MaxWorkers())
+}
+
+func TestParsesAnInvalidLoadProfile(t *testing.T) {
+	for _, description := range []string{"", "60s", "abc:100", "60s:abc", "60s:100-abc", "60s:100@0", "0s:100", "60s:-100"} {
+		_, err := ParseLoadProfile(description)
+		assert.Error(t, err, description)
+	}
+}
+
+func TestCreatesALoadProfileWithoutStages(t *testing.T) {
+	_, err := NewLoadProfile()
+	assert.Equal(t, ErrEmptyLoadProfile, err)
+}
+
+func TestCreatesALoadProfileWithNamedStages(t *testing.T) {
+	profile, err := NewLoadProfile(
+		LoadStage{Name: "warmup", Duration: time.Second, StartRate: 0, EndRate: 10},
+		LoadStage{Duration: time.Second, StartRate: 10, EndRate: 10},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, []report.ReportStage{
+		{Name: "warmup", Duration: time.Second},
+		{Name: "1s:10", Duration: time.Second},
+	}, profile.ReportStages())
+}
+
+func TestRequestsDueInALoadProfile(t *testing.T) {
+	profile, err := NewLoadProfile(
+		LoadStage{Duration: 10 * time.Second, StartRate: 0, EndRate: 100},
+		LoadStage{Duration: 10 * time.Second, StartRate: 100, EndRate: 100},
+	)
+	assert.Nil(t, err)
+
+	assert.Equal(t, uint64(0), profile.requestsDueBy(0))
+	assert.Equal(t, uint64(125), profile.requestsDueBy(5*time.Second))
+	assert.Equal(t, uint64(500), profile.requestsDueBy(10*time.Second))
+	assert.Equal(t, uint64(1000), profile.requestsDueBy(15*time.Second))
+	assert.Equal(t, uint64(1500), profile.requestsDueBy(time.Minute))
+}
+
+func TestScheduledTimeOfRequestsInALoadProfile(t *testing.T) {
+	profile, err := NewLoadProfile(
+		LoadStage{Duration: 10 * time.Second, StartRate: 0, EndRate: 100},
+		LoadStage{Duration: 10 * time.Second, StartRate: 100, EndRate: 100},
+	)
+	assert.Nil(t, err)
+
+	assert.InDelta(t, float64(5*time.Second), float64(profile.scheduledAt(125)), float64(time.Millisecond))
+	assert.InDelta(t, float64(10*time.Second), float64(profile.scheduledAt(500)), float64(time.Millisecond))
+	assert.InDelta(t, float64(15*time.Second), float64(profile.scheduledAt(1000)), float64(time.Millisecond))
+}
+
+func TestScheduledTimeOfRequestsInARampDownStage(t *testing.T) {
+	profile, err := NewLoadProfile(
+		LoadStage{Duration: 10 * time.Second, StartRate: 11, EndRate: 0},
+	)
+	assert.Nil(t, err)
+
+	assert.InDelta(t, float64(10*time.Second), float64(profile.scheduledAt(55)), float64(time.Millisecond))
+	for request := uint64(1); request <= 55; request++ {
+		assert.LessOrEqual(t, profile.scheduledAt(request-1), profile.scheduledAt(request))
+	}
+}
+
+func TestWorkersInALoadProfile(t *testing.T) {
+	profile, err := NewLoadProfile(
+		LoadStage{Duration: 10 * time.Second, StartRate: 100, EndRate: 100, Workers: 5},
+		LoadStage{Duration: 10 * time.Second, StartRate: 100, EndRate: 100},
+	)
+	assert.Nil(t, err)
+
+	assert.Equal(t, uint(5), profile.workersAt(0))
+	assert.Equal(t, uint(5), profile.workersAt(9*time.Second))
+	assert.Equal(t, uint(0), profile.workersAt(10*time.Second))
+	assert.Equal(t, uint(0), profile.workersAt(time.Minute))
+}
+
+func TestGroupOptionsWithLoadProfile(t *testing.T) {
+	profile, err := ParseLoadProfile("10s:0-100,10s:100")
+	assert.Nil(t, err)
+
+	groupOptions := NewGroupOptions(10, nil, "localhost:8080", time.Second).WithLoadProfile(profile)
+	assert.Equal(t, OpenLoadModel, groupOptions.LoadModel())
+	assert.Equal(t, 20*time.Second, groupOptions.MaxDuration())
+	assert.Equal(t, uint(1500), groupOptions.ExpectedLoadInTotalDuration())
+	assert.Equal(t, &profile, groupOptions.LoadProfile())
+}
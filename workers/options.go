@@ -1,7 +1,9 @@
 package workers
 
 import (
+	"crypto/tls"
 	"github.com/SarthakMakhija/blast-core/payload"
+	"sync/atomic"
 	"time"
 
 	"github.com/SarthakMakhija/blast-core/report"
@@ -9,28 +11,71 @@ import (
 
 const dialTimeout = 3 * time.Second
 
+// inactiveWorkerPollInterval is the interval at which a Worker that is not active in the current stage of
+// the LoadProfile (or whose connection is being re-established) checks if it has become active.
+const inactiveWorkerPollInterval = 10 * time.Millisecond
+
+// lateSendThreshold is the delay after the scheduled time of a request (OpenLoadModel) beyond which
+// the request is reported as late.
+const lateSendThreshold = 10 * time.Millisecond
+
+// LoadModel defines how the WorkerGroup paces the requests.
+// ClosedLoadModel: each Worker sends GroupOptions.requestsPerSecond requests per second. A Worker sends its
+// next request only after the previous request is written, so a server that stays slow lowers the offered load.
+// OpenLoadModel: the requests are issued at GroupOptions.arrivalRate requests per second (across all the
+// workers) from a shared schedule, independent of how many workers are blocked. If no Worker is available
+// to send a scheduled request, the request is dropped and reported as dropped.
+// A LoadProfile also uses the OpenLoadModel, with the arrival rate (and the number of workers) of its
+// current stage.
+type LoadModel uint8
+
+const (
+	ClosedLoadModel LoadModel = iota
+	OpenLoadModel
+)
+
 // GroupOptions defines the configuration options for the WorkerGroup.
 type GroupOptions struct {
-	concurrency       uint
-	connections       uint
-	payloadGenerator  payload.PayloadGenerator
-	targetAddress     string
-	requestsPerSecond float64
-	maxDuration       time.Duration
-	dialTimeout       time.Duration
+	concurrency           uint
+	connections           uint
+	payloadGenerator      payload.ContextualPayloadGenerator
+	targetAddress         string
+	requestsPerSecond     float64
+	maxDuration           time.Duration
+	dialTimeout           time.Duration
+	loadModel             LoadModel
+	arrivalRate           float64
+	loadProfile           *LoadProfile
+	totalRequests         uint
+	reconnectPolicy       ReconnectPolicy
+	churnPolicy           ChurnPolicy
+	tlsConfig             *tls.Config
+	connectionInitializer ConnectionInitializer
+	initializationTimeout time.Duration
+	targets               []Target
+	loadBalancing         LoadBalancingStrategy
+	hashKey               HashKey
 }
 
 // WorkerOptions defines the configuration options for a running Worker.
 type WorkerOptions struct {
 	maxDuration            time.Duration
-	payloadGenerator       payload.PayloadGenerator
+	payloadGenerator       payload.ContextualPayloadGenerator
 	targetAddress          string
 	requestsPerSecond      float64
 	stopChannel            chan struct{}
 	loadGenerationResponse chan report.LoadGenerationResponse
+	inFlightRequests       *report.InFlightRequests
+	sendSlots              chan time.Time
+	lateSendThreshold      time.Duration
+	activeWorkers          *atomic.Uint64
+	totalRequests          uint64
+	startTime              time.Time
 }
 
 // NewGroupOptionsFullyLoaded creates a new instance of GroupOptions.
+// The payloadGenerator is adapted to a payload.ContextualPayloadGenerator, a nil payloadGenerator must be
+// replaced using WithContextualPayloadGenerator.
 func NewGroupOptionsFullyLoaded(
 	concurrency uint,
 	connections uint,
@@ -40,15 +85,18 @@ func NewGroupOptionsFullyLoaded(
 	requestsPerSecond float64,
 	maxDuration time.Duration,
 ) GroupOptions {
-	return GroupOptions{
+	groupOptions := GroupOptions{
 		concurrency:       concurrency,
 		connections:       connections,
-		payloadGenerator:  payloadGenerator,
 		targetAddress:     targetAddress,
 		requestsPerSecond: requestsPerSecond,
 		maxDuration:       maxDuration,
 		dialTimeout:       dialTimeout,
 	}
+	if payloadGenerator != nil {
+		groupOptions.payloadGenerator = payload.NewPayloadGeneratorAdapter(payloadGenerator)
+	}
+	return groupOptions
 }
 
 // NewGroupOptions creates a new instance of GroupOptions.
@@ -89,12 +137,154 @@ func NewGroupOptionsWithConnections(
 	)
 }
 
+// WithContextualPayloadGenerator returns a copy of GroupOptions that generates the payload of each request from its
+// payload.RequestContext, instead of using the payload.PayloadGenerator.
+func (groupOptions GroupOptions) WithContextualPayloadGenerator(
+	payloadGenerator payload.ContextualPayloadGenerator,
+) GroupOptions {
+	groupOptions.payloadGenerator = payloadGenerator
+	return groupOptions
+}
+
+// WithArrivalRate returns a copy of GroupOptions that uses the OpenLoadModel, issuing arrivalRate requests
+// per second across all the workers. requestsPerSecond (per worker) is not used in the OpenLoadModel.
+func (groupOptions GroupOptions) WithArrivalRate(arrivalRate float64) GroupOptions {
+	groupOptions.loadModel = OpenLoadModel
+	groupOptions.arrivalRate = arrivalRate
+	return groupOptions
+}
+
+// WithMaxDuration returns a copy of GroupOptions with the given maximum duration.
+func (groupOptions GroupOptions) WithMaxDuration(maxDuration time.Duration) GroupOptions {
+	groupOptions.maxDuration = maxDuration
+	return groupOptions
+}
+
+// WithLoadProfile returns a copy of GroupOptions that follows the LoadProfile, using the OpenLoadModel.
+// The maximum duration is the total duration of the LoadProfile, and requestsPerSecond (per worker) and
+// arrivalRate are not used.
+func (groupOptions GroupOptions) WithLoadProfile(loadProfile LoadProfile) GroupOptions {
+	groupOptions.loadModel = OpenLoadModel
+	groupOptions.loadProfile = &loadProfile
+	groupOptions.maxDuration = loadProfile.TotalDuration()
+	return groupOptions
+}
+
+// WithTotalRequests returns a copy of GroupOptions that sends at most totalRequests requests across all the
+// workers. The workers stop once totalRequests are sent, or once the maximum duration has elapsed, whichever
+// is earlier. A totalRequests of zero means no limit.
+func (groupOptions GroupOptions) WithTotalRequests(totalRequests uint) GroupOptions {
+	groupOptions.totalRequests = totalRequests
+	return groupOptions
+}
+
+// WithReconnectPolicy returns a copy of GroupOptions that re-establishes the connections that fail, as per
+// the ReconnectPolicy.
+func (groupOptions GroupOptions) WithReconnectPolicy(policy ReconnectPolicy) GroupOptions {
+	groupOptions.reconnectPolicy = policy
+	return groupOptions
+}
+
+// WithChurnPolicy returns a copy of GroupOptions that churns (closes and re-opens) the connections, as per
+// the ChurnPolicy.
+func (groupOptions GroupOptions) WithChurnPolicy(policy ChurnPolicy) GroupOptions {
+	groupOptions.churnPolicy = policy
+	return groupOptions
+}
+
+// WithTLSConfig returns a copy of GroupOptions that establishes TLS connections with the target server, using
+// the given tls.Config (see TLSOptions.Config).
+// The TLS sessions are resumed, when the connections are re-established or churned, if the tls.Config has a
+// ClientSessionCache.
+func (groupOptions GroupOptions) WithTLSConfig(config *tls.Config) GroupOptions {
+	groupOptions.tlsConfig = config
+	return groupOptions
+}
+
+// WithConnectionInitializer returns a copy of GroupOptions that initializes each connection using the
+// ConnectionInitializer, before the workers send the requests on it.
+// The initialization of a connection fails if it takes longer than the timeout, the dial timeout is used if the
+// timeout is zero.
+func (groupOptions GroupOptions) WithConnectionInitializer(
+	initializer ConnectionInitializer,
+	timeout time.Duration,
+) GroupOptions {
+	groupOptions.connectionInitializer = initializer
+	groupOptions.initializationTimeout = timeout
+	return groupOptions
+}
+
+// WithTargets returns a copy of GroupOptions that distributes the load across the targets as per the
+// LoadBalancingStrategy. The targets replace the target address.
+func (groupOptions GroupOptions) WithTargets(targets []Target, strategy LoadBalancingStrategy) GroupOptions {
+	groupOptions.targets = targets
+	groupOptions.loadBalancing = strategy
+	return groupOptions
+}
+
+// WithHashKey returns a copy of GroupOptions that uses the HashKey of the payloads to route the requests to
+// the targets, with the ConsistentHashBalancing strategy.
+func (groupOptions GroupOptions) WithHashKey(key HashKey) GroupOptions {
+	groupOptions.hashKey = key
+	return groupOptions
+}
+
+// targetList returns the targets, the target address is the only target if the targets are not specified.
+func (groupOptions GroupOptions) targetList() ([]Target, error) {
+	if len(groupOptions.targets) > 0 {
+		return groupOptions.targets, nil
+	}
+	target, err := ParseTarget(groupOptions.targetAddress)
+	if err != nil {
+		return nil, err
+	}
+	return []Target{target}, nil
+}
+
+// TotalRequests returns the limit on the total number of requests, zero if there is no limit.
+func (groupOptions GroupOptions) TotalRequests() uint {
+	return groupOptions.totalRequests
+}
+
+// LoadProfile returns the LoadProfile, nil if GroupOptions does not follow a LoadProfile.
+func (groupOptions GroupOptions) LoadProfile() *LoadProfile {
+	return groupOptions.loadProfile
+}
+
+// LoadModel returns the LoadModel.
+func (groupOptions GroupOptions) LoadModel() LoadModel {
+	return groupOptions.loadModel
+}
+
+// ArrivalRate returns the arrival rate (requests per second across all the workers) of the OpenLoadModel.
+func (groupOptions GroupOptions) ArrivalRate() float64 {
+	return groupOptions.arrivalRate
+}
+
 // ExpectedLoadInTotalDuration returns the expected total load.
+// The expected load does not exceed the total requests, if GroupOptions is configured with the total requests.
 func (groupOptions GroupOptions) ExpectedLoadInTotalDuration() uint {
-	return uint(groupOptions.requestsPerSecond * float64(groupOptions.concurrency) * (groupOptions.maxDuration.Seconds()))
+	var expectedLoad uint
+	if groupOptions.loadModel == OpenLoadModel {
+		expectedLoad = uint(groupOptions.arrivalSchedule().requestsDueBy(groupOptions.maxDuration))
+	} else {
+		expectedLoad = uint(groupOptions.requestsPerSecond * float64(groupOptions.concurrency) * (groupOptions.maxDuration.Seconds()))
+	}
+	if groupOptions.totalRequests > 0 && (expectedLoad == 0 || expectedLoad > groupOptions.totalRequests) {
+		return groupOptions.totalRequests
+	}
+	return expectedLoad
 }
 
 // MaxDuration returns the maximum duration.
 func (groupOptions GroupOptions) MaxDuration() time.Duration {
 	return groupOptions.maxDuration
 }
+
+// arrivalSchedule returns the arrivalSchedule of the OpenLoadModel.
+func (groupOptions GroupOptions) arrivalSchedule() arrivalSchedule {
+	if groupOptions.loadProfile != nil {
+		return *groupOptions.loadProfile
+	}
+	return constantArrivalRate(groupOptions.arrivalRate)
+}
@@ -0,0 +1,102 @@
+package workers
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// defaultInitialReconnectBackoff is the wait before the first reconnect attempt of the BackoffReconnect strategy,
+// if ReconnectPolicy.InitialBackoff is not specified.
+const defaultInitialReconnectBackoff = 100 * time.Millisecond
+
+// defaultMaxReconnectBackoff is the maximum wait between the reconnect attempts of the BackoffReconnect strategy,
+// if ReconnectPolicy.MaxBackoff is not specified.
+const defaultMaxReconnectBackoff = 5 * time.Second
+
+// ReconnectStrategy defines how a connection that fails (at dial time, or later during the run) is re-established.
+// NoReconnect: the connection is not re-established, the workers of a connection that failed at dial time
+// report ErrNilConnection, and the workers of a connection that is lost report the write errors.
+// ImmediateReconnect: the connection is re-dialed without any wait between the attempts.
+// BackoffReconnect: the connection is re-dialed after an exponential backoff with jitter between the attempts.
+type ReconnectStrategy uint8
+
+const (
+	NoReconnect ReconnectStrategy = iota
+	ImmediateReconnect
+	BackoffReconnect
+)
+
+// ParseReconnectStrategy returns the ReconnectStrategy identified by the name: none, immediate or backoff.
+func ParseReconnectStrategy(name string) (ReconnectStrategy, error) {
+	switch name {
+	case "none":
+		return NoReconnect, nil
+	case "immediate":
+		return ImmediateReconnect, nil
+	case "backoff":
+		return BackoffReconnect, nil
+	default:
+		return NoReconnect, fmt.Errorf("unsupported reconnect strategy %v", name)
+	}
+}
+
+// String returns the name of the ReconnectStrategy.
+func (strategy ReconnectStrategy) String() string {
+	switch strategy {
+	case ImmediateReconnect:
+		return "immediate"
+	case BackoffReconnect:
+		return "backoff"
+	default:
+		return "none"
+	}
+}
+
+// ReconnectPolicy defines how the WorkerGroup re-establishes a connection that fails.
+// While a connection is being re-established, its workers do not send requests, and once the connection is
+// re-established, it is swapped under all its workers.
+// InitialBackoff and MaxBackoff are only used by the BackoffReconnect strategy: the wait before the nth attempt
+// is InitialBackoff * 2^(n-1), capped at MaxBackoff, of which a random half is the jitter.
+// defaultInitialReconnectBackoff and defaultMaxReconnectBackoff are used if they are zero.
+// MaxAttempts is the maximum number of attempts to re-establish a connection each time it fails, the attempts
+// are unlimited if it is zero. The connection stays down for the rest of the run once the attempts are exhausted.
+type ReconnectPolicy struct {
+	Strategy       ReconnectStrategy
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	MaxAttempts    uint
+}
+
+// enabled returns true if the connections are to be re-established.
+func (policy ReconnectPolicy) enabled() bool {
+	return policy.Strategy != NoReconnect
+}
+
+// attemptAllowed returns true if the given attempt (starting at 1) does not exceed the MaxAttempts.
+func (policy ReconnectPolicy) attemptAllowed(attempt uint) bool {
+	return policy.MaxAttempts == 0 || attempt <= policy.MaxAttempts
+}
+
+// backoff returns the wait before the given attempt (starting at 1).
+func (policy ReconnectPolicy) backoff(attempt uint) time.Duration {
+	if policy.Strategy != BackoffReconnect {
+		return 0
+	}
+	initialBackoff, maxBackoff := policy.InitialBackoff, policy.MaxBackoff
+	if initialBackoff <= 0 {
+		initialBackoff = defaultInitialReconnectBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxReconnectBackoff
+	}
+	backoff := initialBackoff
+	for step := uint(1); step < attempt && backoff < maxBackoff; step++ {
+		backoff = backoff * 2
+	}
+	if backoff > maxBackoff {
+		backoff = maxBackoff
+	}
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
@@ -0,0 +1,56 @@
+package workers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsesTheReconnectStrategy(t *testing.T) {
+	strategy, err := ParseReconnectStrategy("none")
+	assert.Nil(t, err)
+	assert.Equal(t, NoReconnect, strategy)
+
+	strategy, err = ParseReconnectStrategy("immediate")
+	assert.Nil(t, err)
+	assert.Equal(t, ImmediateReconnect, strategy)
+
+	strategy, err = ParseReconnectStrategy("backoff")
+	assert.Nil(t, err)
+	assert.Equal(t, BackoffReconnect, strategy)
+	assert.Equal(t, "backoff", strategy.String())
+}
+
+func TestParsesAnUnsupportedReconnectStrategy(t *testing.T) {
+	_, err := ParseReconnectStrategy("always")
+	assert.Error(t, err)
+}
+
+func TestReconnectPolicyWithImmediateReconnectDoesNotWait(t *testing.T) {
+	policy := ReconnectPolicy{Strategy: ImmediateReconnect}
+	assert.Equal(t, time.Duration(0), policy.backoff(1))
+	assert.Equal(t, time.Duration(0), policy.backoff(5))
+}
+
+func TestReconnectPolicyWithExponentialBackoffAndJitter(t *testing.T) {
+	policy := ReconnectPolicy{Strategy: BackoffReconnect, InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	firstBackoff := policy.backoff(1)
+	assert.True(t, firstBackoff >= 50*time.Millisecond && firstBackoff <= 100*time.Millisecond)
+
+	thirdBackoff := policy.backoff(3)
+	assert.True(t, thirdBackoff >= 200*time.Millisecond && thirdBackoff <= 400*time.Millisecond)
+
+	tenthBackoff := policy.backoff(10)
+	assert.True(t, tenthBackoff >= 500*time.Millisecond && tenthBackoff <= time.Second)
+}
+
+func TestReconnectPolicyWithMaxAttempts(t *testing.T) {
+	policy := ReconnectPolicy{Strategy: ImmediateReconnect, MaxAttempts: 2}
+	assert.True(t, policy.attemptAllowed(2))
+	assert.False(t, policy.attemptAllowed(3))
+
+	policy = ReconnectPolicy{Strategy: ImmediateReconnect}
+	assert.True(t, policy.attemptAllowed(100))
+}
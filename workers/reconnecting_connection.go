@@ -0,0 +1,260 @@
+package workers
+
+import (
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SarthakMakhija/blast-core/report"
+)
+
+// reconnectingConnection is the connection shared by the workers of a connection, that re-establishes the
+// underlying net.Conn as per the ReconnectPolicy, if the net.Conn fails at dial time or a write on it fails.
+// reconnectingConnection also churns (re-opens and then closes) the underlying net.Conn as per the ChurnPolicy.
+// The re-established (or re-opened) net.Conn is swapped under all the workers, and onConnected is invoked with it
+// (for example, to start reading the responses from it).
+// reconnectingConnection also keeps track of the number of reconnects and the time for which the connection
+// was down (downtime).
+type reconnectingConnection struct {
+	id          int
+	policy      ReconnectPolicy
+	churnPolicy ChurnPolicy
+	dial        func() (net.Conn, error)
+	onConnected func(net.Conn)
+	stopChannel chan struct{}
+	lock        sync.RWMutex
+	connection  net.Conn
+	connectedAt time.Time
+	requests    atomic.Uint64
+	churning    bool
+	lostAt      time.Time
+	reconnects  uint
+	downtime    time.Duration
+	stopped     bool
+}
+
+// newReconnectingConnection creates a new instance of reconnectingConnection.
+// If the connection is nil (it could not be established), the reconnectingConnection starts re-establishing it.
+// The reconnectingConnection stops re-establishing the connection once the stopChannel is closed.
+func newReconnectingConnection(
+	id int,
+	connection net.Conn,
+	policy ReconnectPolicy,
+	churnPolicy ChurnPolicy,
+	dial func() (net.Conn, error),
+	onConnected func(net.Conn),
+	stopChannel chan struct{},
+) *reconnectingConnection {
+	reconnecting := &reconnectingConnection{
+		id:          id,
+		policy:      policy,
+		churnPolicy: churnPolicy,
+		dial:        dial,
+		onConnected: onConnected,
+		stopChannel: stopChannel,
+		connection:  connection,
+	}
+	if connection == nil {
+		reconnecting.lostAt = time.Now()
+		go reconnecting.reconnect()
+	} else {
+		reconnecting.connectedAt = time.Now()
+	}
+	return reconnecting
+}
+
+// Write writes the payload on the current net.Conn.
+// It returns ErrNilConnection if the connection is down, and starts re-establishing the connection if the
+// write fails. The write is retried on the re-opened net.Conn, if the net.Conn was churned during the write.
+// The connection is churned once ChurnPolicy.RequestsPerConnection are written on it.
+func (reconnecting *reconnectingConnection) Write(payload []byte) (int, error) {
+	connection := reconnecting.current()
+	if connection == nil {
+		return 0, ErrNilConnection
+	}
+	written, err := connection.Write(payload)
+	if err != nil {
+		if current := reconnecting.current(); current != nil && current != connection {
+			return current.Write(payload)
+		}
+		reconnecting.connectionLost(connection)
+		return written, err
+	}
+	requestsPerConnection := uint64(reconnecting.churnPolicy.RequestsPerConnection)
+	if requestsPerConnection > 0 && reconnecting.requests.Add(1) >= requestsPerConnection {
+		reconnecting.churn()
+	}
+	return written, err
+}
+
+// Close closes the current net.Conn, if the connection is not down.
+func (reconnecting *reconnectingConnection) Close() error {
+	if connection := reconnecting.current(); connection != nil {
+		return connection.Close()
+	}
+	return nil
+}
+
+// available returns true if the connection is not down.
+func (reconnecting *reconnectingConnection) available() bool {
+	return reconnecting.current() != nil
+}
+
+// current returns the current net.Conn, nil if the connection is down.
+func (reconnecting *reconnectingConnection) current() net.Conn {
+	reconnecting.lock.RLock()
+	defer reconnecting.lock.RUnlock()
+	return reconnecting.connection
+}
+
+// connectionLost closes the failed net.Conn and starts re-establishing the connection.
+// The failed net.Conn may be reported by multiple workers, only the first report (while it is still current)
+// starts re-establishing the connection.
+func (reconnecting *reconnectingConnection) connectionLost(connection net.Conn) {
+	reconnecting.lock.Lock()
+	defer reconnecting.lock.Unlock()
+
+	if reconnecting.connection != connection || reconnecting.stopped {
+		return
+	}
+	_ = connection.Close()
+	reconnecting.connection = nil
+	reconnecting.lostAt = time.Now()
+	go reconnecting.reconnect()
+}
+
+// age returns the duration for which the current net.Conn has been open, zero if the connection is down.
+func (reconnecting *reconnectingConnection) age() time.Duration {
+	reconnecting.lock.RLock()
+	defer reconnecting.lock.RUnlock()
+
+	if reconnecting.connection == nil {
+		return 0
+	}
+	return time.Since(reconnecting.connectedAt)
+}
+
+// churn re-opens the connection in a separate goroutine, swaps the re-opened net.Conn under the workers and
+// then closes the previous net.Conn. The previous net.Conn is kept if the connection can not be re-opened.
+// A connection that is down is not churned, it is re-established as per the ReconnectPolicy.
+func (reconnecting *reconnectingConnection) churn() {
+	reconnecting.lock.Lock()
+	defer reconnecting.lock.Unlock()
+
+	if reconnecting.churning || reconnecting.stopped || reconnecting.connection == nil {
+		return
+	}
+	reconnecting.churning = true
+	go func() {
+		connection, err := reconnecting.dial()
+		if err != nil {
+			reconnecting.churned(nil)
+			return
+		}
+		previous, ok := reconnecting.churned(connection)
+		if !ok {
+			_ = connection.Close()
+			return
+		}
+		if previous != nil {
+			_ = previous.Close()
+		}
+		reconnecting.onConnected(connection)
+	}()
+}
+
+// churned swaps the re-opened net.Conn (if any) under the workers, and returns the previous net.Conn.
+// It returns false if the re-opened net.Conn can not be swapped, because the reconnectingConnection is
+// already stopped or the connection is down.
+func (reconnecting *reconnectingConnection) churned(connection net.Conn) (net.Conn, bool) {
+	reconnecting.lock.Lock()
+	defer reconnecting.lock.Unlock()
+
+	reconnecting.churning = false
+	if connection == nil || reconnecting.stopped || reconnecting.connection == nil {
+		return nil, false
+	}
+	previous := reconnecting.connection
+	reconnecting.swap(connection)
+	return previous, true
+}
+
+// swap swaps the current net.Conn, it expects the lock to be held.
+func (reconnecting *reconnectingConnection) swap(connection net.Conn) {
+	reconnecting.connection = connection
+	reconnecting.connectedAt = time.Now()
+	reconnecting.requests.Store(0)
+}
+
+// reconnect re-dials the connection till it is established, or the ReconnectPolicy.MaxAttempts are exhausted,
+// or the stopChannel is closed. The connection is not re-established if the ReconnectPolicy is not enabled.
+func (reconnecting *reconnectingConnection) reconnect() {
+	if !reconnecting.policy.enabled() {
+		return
+	}
+	for attempt := uint(1); reconnecting.policy.attemptAllowed(attempt); attempt++ {
+		select {
+		case <-reconnecting.stopChannel:
+			return
+		case <-time.After(reconnecting.policy.backoff(attempt)):
+		}
+		connection, err := reconnecting.dial()
+		if err != nil {
+			continue
+		}
+		if !reconnecting.reconnected(connection) {
+			_ = connection.Close()
+			return
+		}
+		reconnecting.onConnected(connection)
+		return
+	}
+}
+
+// reconnected swaps the re-established net.Conn under the workers, and returns false if the
+// reconnectingConnection is already stopped.
+func (reconnecting *reconnectingConnection) reconnected(connection net.Conn) bool {
+	reconnecting.lock.Lock()
+	defer reconnecting.lock.Unlock()
+
+	if reconnecting.stopped {
+		return false
+	}
+	reconnecting.swap(connection)
+	reconnecting.reconnects++
+	reconnecting.downtime += time.Since(reconnecting.lostAt)
+	reconnecting.lostAt = time.Time{}
+	return true
+}
+
+// stop stops tracking the downtime, the connection that is down at the time of stop is not re-established.
+func (reconnecting *reconnectingConnection) stop() {
+	reconnecting.lock.Lock()
+	defer reconnecting.lock.Unlock()
+
+	if reconnecting.stopped {
+		return
+	}
+	reconnecting.stopped = true
+	if !reconnecting.lostAt.IsZero() {
+		reconnecting.downtime += time.Since(reconnecting.lostAt)
+		reconnecting.lostAt = time.Time{}
+	}
+}
+
+// metrics returns the report.ConnectionMetrics, including the downtime till now if the connection is down.
+func (reconnecting *reconnectingConnection) metrics() report.ConnectionMetrics {
+	reconnecting.lock.RLock()
+	defer reconnecting.lock.RUnlock()
+
+	downtime := reconnecting.downtime
+	if !reconnecting.lostAt.IsZero() {
+		downtime += time.Since(reconnecting.lostAt)
+	}
+	return report.ConnectionMetrics{
+		ConnectionId: reconnecting.id,
+		Reconnects:   reconnecting.reconnects,
+		Downtime:     downtime,
+	}
+}
@@ -0,0 +1,267 @@
+package workers
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type FailingConnection struct {
+	net.Conn
+	failWrites bool
+	closed     atomic.Bool
+}
+
+func (connection *FailingConnection) Write(payload []byte) (int, error) {
+	if connection.failWrites {
+		return 0, errors.New("connection reset by peer")
+	}
+	return len(payload), nil
+}
+
+func (connection *FailingConnection) Close() error {
+	connection.closed.Store(true)
+	return nil
+}
+
+func TestReconnectingConnectionIsEstablishedAfterAFailedDial(t *testing.T) {
+	stopChannel := make(chan struct{})
+	defer close(stopChannel)
+
+	connected := make(chan net.Conn, 1)
+	connection := newReconnectingConnection(
+		0,
+		nil,
+		ReconnectPolicy{Strategy: ImmediateReconnect},
+		ChurnPolicy{},
+		func() (net.Conn, error) {
+			return &FailingConnection{}, nil
+		},
+		func(connection net.Conn) {
+			connected <- connection
+		},
+		stopChannel,
+	)
+
+	<-connected
+	assert.True(t, connection.available())
+
+	written, err := connection.Write([]byte("payload"))
+	assert.Nil(t, err)
+	assert.Equal(t, 7, written)
+
+	metrics := connection.metrics()
+	assert.Equal(t, uint(1), metrics.Reconnects)
+	assert.True(t, metrics.Downtime > 0)
+}
+
+func TestReconnectingConnectionIsReEstablishedAfterAFailedWrite(t *testing.T) {
+	stopChannel := make(chan struct{})
+	defer close(stopChannel)
+
+	failedConnection := &FailingConnection{failWrites: true}
+	connected := make(chan net.Conn, 1)
+	connection := newReconnectingConnection(
+		1,
+		failedConnection,
+		ReconnectPolicy{Strategy: BackoffReconnect, InitialBackoff: time.Millisecond},
+		ChurnPolicy{},
+		func() (net.Conn, error) {
+			return &FailingConnection{}, nil
+		},
+		func(connection net.Conn) {
+			connected <- connection
+		},
+		stopChannel,
+	)
+
+	_, err := connection.Write([]byte("payload"))
+	assert.Error(t, err)
+	assert.True(t, failedConnection.closed.Load())
+
+	reconnected := <-connected
+	assert.NotEqual(t, failedConnection, reconnected)
+	assert.True(t, connection.available())
+	assert.Equal(t, uint(1), connection.metrics().Reconnects)
+}
+
+func TestReconnectingConnectionStaysDownAfterTheMaxAttempts(t *testing.T) {
+	stopChannel := make(chan struct{})
+	defer close(stopChannel)
+
+	var attempts atomic.Uint32
+	connection := newReconnectingConnection(
+		0,
+		nil,
+		ReconnectPolicy{Strategy: ImmediateReconnect, MaxAttempts: 3},
+		ChurnPolicy{},
+		func() (net.Conn, error) {
+			attempts.Add(1)
+			return nil, errors.New("connection refused")
+		},
+		func(connection net.Conn) {},
+		stopChannel,
+	)
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, uint32(3), attempts.Load())
+	assert.False(t, connection.available())
+
+	_, err := connection.Write([]byte("payload"))
+	assert.Equal(t, ErrNilConnection, err)
+
+	connection.stop()
+	metrics := connection.metrics()
+	assert.Equal(t, uint(0), metrics.Reconnects)
+	assert.True(t, metrics.Downtime >= 20*time.Millisecond)
+}
+
+func TestReconnectingConnectionIsNotReEstablishedAfterStop(t *testing.T) {
+	stopChannel := make(chan struct{})
+	connection := newReconnectingConnection(
+		0,
+		nil,
+		ReconnectPolicy{Strategy: BackoffReconnect, InitialBackoff: time.Minute, MaxBackoff: time.Minute},
+		ChurnPolicy{},
+		func() (net.Conn, error) {
+			return &FailingConnection{}, nil
+		},
+		func(connection net.Conn) {},
+		stopChannel,
+	)
+	close(stopChannel)
+	connection.stop()
+
+	downtime := connection.metrics().Downtime
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, downtime, connection.metrics().Downtime)
+	assert.False(t, connection.available())
+}
+
+func TestReconnectingConnectionIsChurnedAfterTheRequestsPerConnection(t *testing.T) {
+	stopChannel := make(chan struct{})
+	defer close(stopChannel)
+
+	firstConnection := &FailingConnection{}
+	connected := make(chan net.Conn, 1)
+	connection := newReconnectingConnection(
+		0,
+		firstConnection,
+		ReconnectPolicy{},
+		ChurnPolicy{RequestsPerConnection: 2},
+		func() (net.Conn, error) {
+			return &FailingConnection{}, nil
+		},
+		func(connection net.Conn) {
+			connected <- connection
+		},
+		stopChannel,
+	)
+
+	_, _ = connection.Write([]byte("payload"))
+	_, _ = connection.Write([]byte("payload"))
+
+	churnedConnection := <-connected
+	assert.Equal(t, churnedConnection, connection.current())
+	assert.True(t, firstConnection.closed.Load())
+	assert.Equal(t, uint(0), connection.metrics().Reconnects)
+}
+
+func TestReconnectingConnectionKeepsTheConnectionIfItCanNotBeChurned(t *testing.T) {
+	stopChannel := make(chan struct{})
+	defer close(stopChannel)
+
+	var dials atomic.Uint32
+	firstConnection := &FailingConnection{}
+	connection := newReconnectingConnection(
+		0,
+		firstConnection,
+		ReconnectPolicy{},
+		ChurnPolicy{RequestsPerConnection: 1},
+		func() (net.Conn, error) {
+			dials.Add(1)
+			return nil, errors.New("connection refused")
+		},
+		func(connection net.Conn) {},
+		stopChannel,
+	)
+
+	_, _ = connection.Write([]byte("payload"))
+	time.Sleep(5 * time.Millisecond)
+
+	assert.Equal(t, uint32(1), dials.Load())
+	assert.Equal(t, net.Conn(firstConnection), connection.current())
+	assert.False(t, firstConnection.closed.Load())
+}
+
+func TestConnectionChurnerChurnsTheConnectionsAtTheRate(t *testing.T) {
+	stopChannel := make(chan struct{})
+
+	var dials atomic.Uint32
+	var connections []*reconnectingConnection
+	for id := 0; id < 2; id++ {
+		connections = append(connections, newReconnectingConnection(
+			id,
+			&FailingConnection{},
+			ReconnectPolicy{},
+			ChurnPolicy{NewConnectionsPerSecond: 100},
+			func() (net.Conn, error) {
+				dials.Add(1)
+				return &FailingConnection{}, nil
+			},
+			func(connection net.Conn) {},
+			stopChannel,
+		))
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	connectionChurner{
+		policy:      ChurnPolicy{NewConnectionsPerSecond: 100},
+		connections: connections,
+		stopChannel: stopChannel,
+	}.run(&wg)
+
+	time.Sleep(105 * time.Millisecond)
+	close(stopChannel)
+	wg.Wait()
+
+	assert.InDelta(t, 10, dials.Load(), 4)
+}
+
+func TestConnectionChurnerChurnsTheConnectionsAfterTheirLifetime(t *testing.T) {
+	stopChannel := make(chan struct{})
+
+	firstConnection := &FailingConnection{}
+	connection := newReconnectingConnection(
+		0,
+		firstConnection,
+		ReconnectPolicy{},
+		ChurnPolicy{ConnectionLifetime: 20 * time.Millisecond},
+		func() (net.Conn, error) {
+			return &FailingConnection{}, nil
+		},
+		func(connection net.Conn) {},
+		stopChannel,
+	)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	connectionChurner{
+		policy:      ChurnPolicy{ConnectionLifetime: 20 * time.Millisecond},
+		connections: []*reconnectingConnection{connection},
+		stopChannel: stopChannel,
+	}.run(&wg)
+
+	time.Sleep(50 * time.Millisecond)
+	close(stopChannel)
+	wg.Wait()
+
+	assert.True(t, firstConnection.closed.Load())
+	assert.True(t, connection.age() < 20*time.Millisecond)
+}
@@ -3,6 +3,8 @@ package workers
 import "sync/atomic"
 
 // RequestId generates a unique request id for each request.
+// RequestId is shared by all the workers of a WorkerGroup, so it also enforces the limit on the total
+// number of requests (GroupOptions.totalRequests).
 type RequestId struct {
 	next atomic.Uint64
 }
@@ -16,3 +18,20 @@ func NewRequestId() *RequestId {
 func (requestId *RequestId) Next() uint64 {
 	return requestId.next.Add(1)
 }
+
+// NextWithinLimit creates new request id, if the total request ids created so far are smaller than the limit.
+// The returned bool is false if the limit is reached. A limit of zero means no limit.
+func (requestId *RequestId) NextWithinLimit(limit uint64) (uint64, bool) {
+	if limit == 0 {
+		return requestId.Next(), true
+	}
+	for {
+		current := requestId.next.Load()
+		if current >= limit {
+			return 0, false
+		}
+		if requestId.next.CompareAndSwap(current, current+1) {
+			return current + 1, true
+		}
+	}
+}
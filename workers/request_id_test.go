@@ -4,6 +4,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"testing"
 )
 
@@ -44,3 +45,49 @@ func TestNewRequestIdConcurrently(t *testing.T) {
 
 	assert.Equal(t, expectedRequestIds, requestIds)
 }
+
+func TestNewRequestIdWithinLimit(t *testing.T) {
+	requestId := NewRequestId()
+
+	id, ok := requestId.NextWithinLimit(2)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), id)
+
+	id, ok = requestId.NextWithinLimit(2)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2), id)
+
+	_, ok = requestId.NextWithinLimit(2)
+	assert.False(t, ok)
+}
+
+func TestNewRequestIdWithinLimitConcurrently(t *testing.T) {
+	var wg sync.WaitGroup
+	wg.Add(100)
+
+	var totalRequestIds atomic.Uint64
+	requestId := NewRequestId()
+	for goroutineId := 1; goroutineId <= 100; goroutineId++ {
+		go func() {
+			defer wg.Done()
+			if _, ok := requestId.NextWithinLimit(40); ok {
+				totalRequestIds.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, uint64(40), totalRequestIds.Load())
+}
+
+func TestNewRequestIdWithoutLimit(t *testing.T) {
+	requestId := NewRequestId()
+
+	id, ok := requestId.NextWithinLimit(0)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), id)
+
+	id, ok = requestId.NextWithinLimit(0)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2), id)
+}
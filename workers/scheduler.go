@@ -0,0 +1,162 @@
+package workers
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SarthakMakhija/blast-core/report"
+)
+
+// minimumScheduleTick is the smallest interval at which the arrivalScheduler wakes up.
+// At higher arrival rates, all the requests that became due since the last tick are issued together.
+const minimumScheduleTick = time.Millisecond
+
+// arrivalSchedule defines the arrival rate of the requests over time.
+type arrivalSchedule interface {
+	// requestsDueBy returns the total number of requests that are due in the elapsed duration since the start.
+	requestsDueBy(elapsed time.Duration) uint64
+	// scheduledAt returns the offset from the start at which the nth request is scheduled.
+	scheduledAt(request uint64) time.Duration
+	// maxRate returns the maximum arrival rate (requests per second) of the schedule.
+	maxRate() float64
+	// workersAt returns the number of workers that send the requests at the elapsed duration since the start,
+	// zero if all the workers send the requests.
+	workersAt(elapsed time.Duration) uint
+}
+
+// constantArrivalRate is the arrivalSchedule of the OpenLoadModel, with a constant arrival rate.
+type constantArrivalRate float64
+
+// requestsDueBy returns the total number of requests that are due in the elapsed duration since the start.
+func (arrivalRate constantArrivalRate) requestsDueBy(elapsed time.Duration) uint64 {
+	return uint64(elapsed.Seconds() * float64(arrivalRate))
+}
+
+// scheduledAt returns the offset from the start at which the nth request is scheduled.
+func (arrivalRate constantArrivalRate) scheduledAt(request uint64) time.Duration {
+	return time.Duration(float64(request) / float64(arrivalRate) * float64(time.Second))
+}
+
+// maxRate returns the arrival rate.
+func (arrivalRate constantArrivalRate) maxRate() float64 {
+	return float64(arrivalRate)
+}
+
+// workersAt returns zero, all the workers send the requests.
+func (arrivalRate constantArrivalRate) workersAt(time.Duration) uint {
+	return 0
+}
+
+// arrivalScheduler issues the requests of the OpenLoadModel as per the arrivalSchedule.
+// The nth request is scheduled at startTime + schedule.scheduledAt(n), irrespective of how long the previous
+// requests took to send. The startTime is the start time of the WorkerGroup (before the connections are
+// dialed), which is also the start of the report stages, so the requests that are due while the connections
+// are dialed are issued as soon as the arrivalScheduler runs.
+// Each scheduled request is handed to the workers through sendSlots (an unbuffered channel), so a request is
+// handed only to a Worker that is idle (waiting for a slot). If no Worker is idle, the request is dropped and a
+// report.LoadGenerationResponse with Dropped set to true is sent on the loadGenerationResponse channel.
+// The arrivalScheduler also publishes the number of workers that send the requests in activeWorkers
+// (if it is configured).
+// If the arrivalScheduler is configured with totalRequests, it stops after handing totalRequests requests to the
+// workers and closes sendSlots, so that the workers stop after sending the requests that are already handed to
+// them. The dropped requests do not count towards totalRequests, so the schedule continues past the
+// totalRequests-th request if any request is dropped.
+type arrivalScheduler struct {
+	schedule               arrivalSchedule
+	startTime              time.Time
+	maxDuration            time.Duration
+	sendSlots              chan time.Time
+	activeWorkers          *atomic.Uint64
+	stopChannel            chan struct{}
+	loadGenerationResponse chan report.LoadGenerationResponse
+	totalRequests          uint64
+}
+
+// run runs the arrivalScheduler.
+func (scheduler arrivalScheduler) run(wg *sync.WaitGroup) {
+	go func() {
+		defer wg.Done()
+		scheduler.issueRequests()
+	}()
+}
+
+// issueRequests issues the requests till either the maxDuration has elapsed, or the stopChannel is closed,
+// or the totalRequests are handed to the workers.
+func (scheduler arrivalScheduler) issueRequests() {
+	tick := minimumScheduleTick
+	if maxRate := scheduler.schedule.maxRate(); maxRate > 0 && time.Duration(float64(time.Second)/maxRate) > tick {
+		tick = time.Duration(float64(time.Second) / maxRate)
+	}
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	maxDuration := time.NewTimer(scheduler.maxDuration)
+	defer maxDuration.Stop()
+
+	startTime := scheduler.startTime
+	scheduler.publishActiveWorkers(time.Since(startTime))
+
+	scheduledRequests, handedRequests := uint64(0), uint64(0)
+	for {
+		select {
+		case <-scheduler.stopChannel:
+			return
+		case <-maxDuration.C:
+			return
+		case now := <-ticker.C:
+			elapsed := now.Sub(startTime)
+			scheduler.publishActiveWorkers(elapsed)
+
+			dueRequests := scheduler.schedule.requestsDueBy(elapsed)
+			for scheduledRequests < dueRequests && !scheduler.allHanded(handedRequests) {
+				scheduledRequests++
+				if scheduler.issue(startTime.Add(scheduler.schedule.scheduledAt(scheduledRequests))) {
+					handedRequests++
+				}
+			}
+			if scheduler.allHanded(handedRequests) {
+				close(scheduler.sendSlots)
+				return
+			}
+		}
+	}
+}
+
+// publishActiveWorkers publishes the number of workers that send the requests at the elapsed duration.
+func (scheduler arrivalScheduler) publishActiveWorkers(elapsed time.Duration) {
+	if scheduler.activeWorkers != nil {
+		scheduler.activeWorkers.Store(uint64(scheduler.schedule.workersAt(elapsed)))
+	}
+}
+
+// allHanded returns true if the arrivalScheduler is configured with totalRequests, and the handed requests
+// have reached totalRequests.
+func (scheduler arrivalScheduler) allHanded(handedRequests uint64) bool {
+	return scheduler.totalRequests > 0 && handedRequests >= scheduler.totalRequests
+}
+
+// issue hands the scheduled request to an idle Worker, or reports it as dropped if no Worker is idle.
+// It returns true if the request is handed to a Worker.
+func (scheduler arrivalScheduler) issue(scheduledTime time.Time) bool {
+	select {
+	case scheduler.sendSlots <- scheduledTime:
+		return true
+	default:
+		scheduler.reportDropped(scheduledTime)
+		return false
+	}
+}
+
+// reportDropped reports the scheduled request as dropped.
+// The loadGenerationResponse channel may be closed while blast is stopping, hence the recover.
+func (scheduler arrivalScheduler) reportDropped(scheduledTime time.Time) {
+	defer func() {
+		_ = recover()
+	}()
+	scheduler.loadGenerationResponse <- report.LoadGenerationResponse{
+		Dropped:            true,
+		LoadGenerationTime: scheduledTime,
+		ConnectionId:       report.NilConnectionId,
+	}
+}
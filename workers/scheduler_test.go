@@ -0,0 +1,200 @@
+package workers
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/SarthakMakhija/blast-core/report"
+)
+
+func TestArrivalSchedulerIssuesRequestsAtTheArrivalRate(t *testing.T) {
+	sendSlots := make(chan time.Time, 1000)
+	loadGenerationResponse := make(chan report.LoadGenerationResponse, 1000)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	arrivalScheduler{
+		startTime:              time.Now(),
+		schedule:               constantArrivalRate(1000),
+		maxDuration:            50 * time.Millisecond,
+		sendSlots:              sendSlots,
+		stopChannel:            make(chan struct{}),
+		loadGenerationResponse: loadGenerationResponse,
+	}.run(&wg)
+	wg.Wait()
+
+	assert.InDelta(t, 50, len(sendSlots), 10)
+	assert.Equal(t, 0, len(loadGenerationResponse))
+}
+
+func TestArrivalSchedulerReportsDroppedRequestsIfNoWorkerIsAvailable(t *testing.T) {
+	sendSlots := make(chan time.Time, 1)
+	loadGenerationResponse := make(chan report.LoadGenerationResponse, 1000)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	arrivalScheduler{
+		startTime:              time.Now(),
+		schedule:               constantArrivalRate(1000),
+		maxDuration:            20 * time.Millisecond,
+		sendSlots:              sendSlots,
+		stopChannel:            make(chan struct{}),
+		loadGenerationResponse: loadGenerationResponse,
+	}.run(&wg)
+	wg.Wait()
+	close(loadGenerationResponse)
+
+	assert.Equal(t, 1, len(sendSlots))
+	droppedRequests := 0
+	for response := range loadGenerationResponse {
+		assert.True(t, response.Dropped)
+		assert.Equal(t, report.NilConnectionId, response.ConnectionId)
+		droppedRequests++
+	}
+	assert.True(t, droppedRequests > 0)
+}
+
+func TestArrivalSchedulerDropsTheRequestsWithoutAnIdleWorker(t *testing.T) {
+	sendSlots := make(chan time.Time)
+	loadGenerationResponse := make(chan report.LoadGenerationResponse, 1000)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	arrivalScheduler{
+		startTime:              time.Now(),
+		schedule:               constantArrivalRate(1000),
+		maxDuration:            20 * time.Millisecond,
+		sendSlots:              sendSlots,
+		stopChannel:            make(chan struct{}),
+		loadGenerationResponse: loadGenerationResponse,
+	}.run(&wg)
+	wg.Wait()
+	close(loadGenerationResponse)
+
+	droppedRequests := 0
+	for response := range loadGenerationResponse {
+		assert.True(t, response.Dropped)
+		droppedRequests++
+	}
+	assert.InDelta(t, 20, droppedRequests, 10)
+}
+
+func TestArrivalSchedulerHandsTheRequestsToAnIdleWorker(t *testing.T) {
+	sendSlots := make(chan time.Time)
+	loadGenerationResponse := make(chan report.LoadGenerationResponse, 1000)
+	takenSlots := make(chan int)
+	go func() {
+		taken := 0
+		for range sendSlots {
+			taken++
+		}
+		takenSlots <- taken
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	arrivalScheduler{
+		startTime:              time.Now(),
+		schedule:               constantArrivalRate(100),
+		maxDuration:            time.Minute,
+		sendSlots:              sendSlots,
+		stopChannel:            make(chan struct{}),
+		loadGenerationResponse: loadGenerationResponse,
+		totalRequests:          5,
+	}.run(&wg)
+	wg.Wait()
+
+	assert.Equal(t, 5, <-takenSlots)
+	assert.Equal(t, 0, len(loadGenerationResponse))
+}
+
+func TestArrivalSchedulerStopsOnStopChannel(t *testing.T) {
+	stopChannel := make(chan struct{})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	arrivalScheduler{
+		startTime:              time.Now(),
+		schedule:               constantArrivalRate(10),
+		maxDuration:            time.Minute,
+		sendSlots:              make(chan time.Time, 10),
+		stopChannel:            stopChannel,
+		loadGenerationResponse: make(chan report.LoadGenerationResponse, 10),
+	}.run(&wg)
+	close(stopChannel)
+	wg.Wait()
+}
+
+func TestArrivalSchedulerStopsAfterIssuingTheTotalRequests(t *testing.T) {
+	sendSlots := make(chan time.Time, 100)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	arrivalScheduler{
+		startTime:              time.Now(),
+		schedule:               constantArrivalRate(1000),
+		maxDuration:            time.Minute,
+		sendSlots:              sendSlots,
+		stopChannel:            make(chan struct{}),
+		loadGenerationResponse: make(chan report.LoadGenerationResponse, 100),
+		totalRequests:          20,
+	}.run(&wg)
+	wg.Wait()
+
+	issuedRequests := 0
+	for range sendSlots {
+		issuedRequests++
+	}
+	assert.Equal(t, 20, issuedRequests)
+}
+
+func TestArrivalSchedulerIssuesRequestsFromTheStartTime(t *testing.T) {
+	sendSlots := make(chan time.Time, 1000)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	arrivalScheduler{
+		startTime:              time.Now().Add(-100 * time.Millisecond),
+		schedule:               constantArrivalRate(1000),
+		maxDuration:            10 * time.Millisecond,
+		sendSlots:              sendSlots,
+		stopChannel:            make(chan struct{}),
+		loadGenerationResponse: make(chan report.LoadGenerationResponse, 1000),
+	}.run(&wg)
+	wg.Wait()
+
+	assert.GreaterOrEqual(t, len(sendSlots), 100)
+}
+
+func TestArrivalSchedulerDoesNotCountTheDroppedRequestsTowardsTheTotalRequests(t *testing.T) {
+	sendSlots := make(chan time.Time)
+	loadGenerationResponse := make(chan report.LoadGenerationResponse, 1000)
+	takenSlots := make(chan int)
+	go func() {
+		taken := 0
+		for range sendSlots {
+			taken++
+			time.Sleep(5 * time.Millisecond)
+		}
+		takenSlots <- taken
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	arrivalScheduler{
+		startTime:              time.Now(),
+		schedule:               constantArrivalRate(1000),
+		maxDuration:            time.Minute,
+		sendSlots:              sendSlots,
+		stopChannel:            make(chan struct{}),
+		loadGenerationResponse: loadGenerationResponse,
+		totalRequests:          10,
+	}.run(&wg)
+	wg.Wait()
+
+	assert.Equal(t, 10, <-takenSlots)
+	assert.True(t, len(loadGenerationResponse) > 0)
+}
@@ -0,0 +1,88 @@
+package workers
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ErrEmptySocketPath is the error that is returned when the path of the unix domain socket is empty.
+var ErrEmptySocketPath = errors.New("unix domain socket path cannot be empty")
+
+// Target defines the network and the address of the target server.
+// Network is one of tcp, unix or udp. Address is host:port for tcp and udp, and the path of the socket for unix.
+// Weight is the relative share of the load of the target server, when the load is distributed across multiple
+// target servers with the WeightedBalancing or the ConsistentHashBalancing strategy. A zero Weight is the
+// same as a Weight of 1.
+type Target struct {
+	Network string
+	Address string
+	Weight  uint
+}
+
+// ParseTarget parses the target URL of the form tcp://host:port, unix:///path or udp://host:port, optionally
+// followed by the weight of the target as ?weight=N.
+// A target URL without a scheme (host:port) is a tcp target.
+func ParseTarget(url string) (Target, error) {
+	url, query, _ := strings.Cut(url, "?")
+	var weight uint
+	if query != "" {
+		name, value, _ := strings.Cut(query, "=")
+		parsedWeight, err := strconv.ParseUint(value, 10, 32)
+		if name != "weight" || err != nil || parsedWeight == 0 {
+			return Target{}, fmt.Errorf("invalid target option %v, weight=N (N > 0) is supported", query)
+		}
+		weight = uint(parsedWeight)
+	}
+	network, address, found := strings.Cut(url, "://")
+	if !found {
+		network, address = "tcp", url
+	}
+	switch network {
+	case "tcp", "udp":
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			return Target{}, fmt.Errorf("invalid %v target %v: %w", network, url, err)
+		}
+	case "unix":
+		if address == "" {
+			return Target{}, ErrEmptySocketPath
+		}
+	default:
+		return Target{}, fmt.Errorf("unsupported network %v, supported networks are tcp, unix and udp", network)
+	}
+	return Target{Network: network, Address: address, Weight: weight}, nil
+}
+
+// ParseTargets parses each of the target URLs (see ParseTarget).
+func ParseTargets(urls []string) ([]Target, error) {
+	targets := make([]Target, 0, len(urls))
+	for _, url := range urls {
+		target, err := ParseTarget(url)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+	return targets, nil
+}
+
+// IsDatagram returns true if the target network preserves the message boundaries (udp): each request is sent
+// as a single datagram, and each datagram that is read is a single response.
+func (target Target) IsDatagram() bool {
+	return target.Network == "udp"
+}
+
+// weight returns the Weight of the target, at least 1.
+func (target Target) weight() uint {
+	if target.Weight == 0 {
+		return 1
+	}
+	return target.Weight
+}
+
+// String returns the target URL, including the scheme.
+func (target Target) String() string {
+	return target.Network + "://" + target.Address
+}
@@ -0,0 +1,71 @@
+package workers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseATargetWithoutScheme(t *testing.T) {
+	target, err := ParseTarget("localhost:8080")
+	assert.Nil(t, err)
+	assert.Equal(t, Target{Network: "tcp", Address: "localhost:8080"}, target)
+	assert.False(t, target.IsDatagram())
+}
+
+func TestParseATcpTarget(t *testing.T) {
+	target, err := ParseTarget("tcp://localhost:8080")
+	assert.Nil(t, err)
+	assert.Equal(t, Target{Network: "tcp", Address: "localhost:8080"}, target)
+	assert.Equal(t, "tcp://localhost:8080", target.String())
+}
+
+func TestParseAUdpTarget(t *testing.T) {
+	target, err := ParseTarget("udp://localhost:8125")
+	assert.Nil(t, err)
+	assert.Equal(t, Target{Network: "udp", Address: "localhost:8125"}, target)
+	assert.True(t, target.IsDatagram())
+}
+
+func TestParseAUnixTarget(t *testing.T) {
+	target, err := ParseTarget("unix:///var/run/sidecar.sock")
+	assert.Nil(t, err)
+	assert.Equal(t, Target{Network: "unix", Address: "/var/run/sidecar.sock"}, target)
+	assert.False(t, target.IsDatagram())
+}
+
+func TestParseAUnixTargetWithoutPath(t *testing.T) {
+	_, err := ParseTarget("unix://")
+	assert.ErrorIs(t, err, ErrEmptySocketPath)
+}
+
+func TestParseATcpTargetWithoutPort(t *testing.T) {
+	_, err := ParseTarget("tcp://localhost")
+	assert.Error(t, err)
+}
+
+func TestParseATargetWithAnUnsupportedNetwork(t *testing.T) {
+	_, err := ParseTarget("http://localhost:8080")
+	assert.Error(t, err)
+}
+
+func TestParseATargetWithWeight(t *testing.T) {
+	target, err := ParseTarget("tcp://node1:8080?weight=3")
+	assert.Nil(t, err)
+	assert.Equal(t, Target{Network: "tcp", Address: "node1:8080", Weight: 3}, target)
+	assert.Equal(t, "tcp://node1:8080", target.String())
+}
+
+func TestParseATargetWithAnInvalidWeight(t *testing.T) {
+	_, err := ParseTarget("tcp://node1:8080?weight=0")
+	assert.Error(t, err)
+
+	_, err = ParseTarget("tcp://node1:8080?priority=1")
+	assert.Error(t, err)
+}
+
+func TestParseTargets(t *testing.T) {
+	targets, err := ParseTargets([]string{"node1:8080", "udp://node2:8125"})
+	assert.Nil(t, err)
+	assert.Equal(t, []Target{{Network: "tcp", Address: "node1:8080"}, {Network: "udp", Address: "node2:8125"}}, targets)
+}
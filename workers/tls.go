@@ -0,0 +1,99 @@
+package workers
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// tlsSessionCacheCapacity is the number of the TLS sessions that are cached for resumption.
+const tlsSessionCacheCapacity = 1024
+
+// ErrIncompleteClientCertificate is the error that is returned when only one of the client certificate and
+// the client key is specified.
+var ErrIncompleteClientCertificate = errors.New("both the client certificate and the client key must be specified")
+
+// ErrTLSOverDatagram is the error that is returned when TLS is used with a datagram (udp) target.
+var ErrTLSOverDatagram = errors.New("TLS is not supported over udp")
+
+// TLSOptions defines the options for establishing TLS connections with the target server.
+// CAFile is the path of the PEM encoded CA bundle that verifies the certificate of the target server, the system
+// CA bundle is used if it is empty.
+// CertFile and KeyFile are the paths of the PEM encoded client certificate and key for mutual TLS (mTLS), they are
+// optional but must be specified together.
+// ServerName is the server name indication (SNI), the host of each target is used if it is empty.
+// ServerName (or InsecureSkipVerify) must be specified for a unix domain socket target.
+// InsecureSkipVerify skips the verification of the certificate of the target server.
+// MinVersion is the minimum TLS version (for example: tls.VersionTLS12), the default of crypto/tls is used if
+// it is zero.
+// SessionResumption resumes the TLS sessions when the connections are re-established or churned.
+type TLSOptions struct {
+	CAFile             string
+	CertFile           string
+	KeyFile            string
+	ServerName         string
+	InsecureSkipVerify bool
+	MinVersion         uint16
+	SessionResumption  bool
+}
+
+// ParseTLSVersion returns the TLS version identified by the name: 1.0, 1.1, 1.2 or 1.3.
+func ParseTLSVersion(name string) (uint16, error) {
+	switch name {
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported TLS version %v", name)
+	}
+}
+
+// Config creates the tls.Config for the target address (see ParseTarget).
+// It returns an error if the CA bundle, or the client certificate and key can not be loaded, or if the target
+// is a datagram (udp) target.
+func (options TLSOptions) Config(targetAddress string) (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         options.ServerName,
+		InsecureSkipVerify: options.InsecureSkipVerify,
+		MinVersion:         options.MinVersion,
+	}
+	target, err := ParseTarget(targetAddress)
+	if err != nil {
+		return nil, err
+	}
+	if target.IsDatagram() {
+		return nil, ErrTLSOverDatagram
+	}
+	if options.CAFile != "" {
+		bundle, err := os.ReadFile(options.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		certificatePool := x509.NewCertPool()
+		if !certificatePool.AppendCertsFromPEM(bundle) {
+			return nil, fmt.Errorf("no certificates found in the CA bundle %v", options.CAFile)
+		}
+		config.RootCAs = certificatePool
+	}
+	if (options.CertFile == "") != (options.KeyFile == "") {
+		return nil, ErrIncompleteClientCertificate
+	}
+	if options.CertFile != "" {
+		certificate, err := tls.LoadX509KeyPair(options.CertFile, options.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{certificate}
+	}
+	if options.SessionResumption {
+		config.ClientSessionCache = tls.NewLRUClientSessionCache(tlsSessionCacheCapacity)
+	}
+	return config, nil
+}
@@ -0,0 +1,75 @@
+package workers
+
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTLSVersion(t *testing.T) {
+	version, err := ParseTLSVersion("1.2")
+	assert.Nil(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS12), version)
+
+	version, err = ParseTLSVersion("1.3")
+	assert.Nil(t, err)
+	assert.Equal(t, uint16(tls.VersionTLS13), version)
+}
+
+func TestParseAnUnsupportedTLSVersion(t *testing.T) {
+	_, err := ParseTLSVersion("2.0")
+	assert.Error(t, err)
+}
+
+func TestTLSConfigWithoutServerName(t *testing.T) {
+	config, err := TLSOptions{MinVersion: tls.VersionTLS12}.Config("localhost:8080")
+	assert.Nil(t, err)
+	assert.Equal(t, "", config.ServerName)
+	assert.Equal(t, uint16(tls.VersionTLS12), config.MinVersion)
+	assert.Nil(t, config.ClientSessionCache)
+}
+
+func TestTLSConfigWithServerName(t *testing.T) {
+	config, err := TLSOptions{ServerName: "blast.io", InsecureSkipVerify: true}.Config("localhost:8080")
+	assert.Nil(t, err)
+	assert.Equal(t, "blast.io", config.ServerName)
+	assert.True(t, config.InsecureSkipVerify)
+}
+
+func TestTLSConfigWithSessionResumption(t *testing.T) {
+	config, err := TLSOptions{SessionResumption: true}.Config("localhost:8080")
+	assert.Nil(t, err)
+	assert.NotNil(t, config.ClientSessionCache)
+}
+
+func TestTLSConfigWithAMissingCAFile(t *testing.T) {
+	_, err := TLSOptions{CAFile: filepath.Join(t.TempDir(), "missing.pem")}.Config("localhost:8080")
+	assert.Error(t, err)
+}
+
+func TestTLSConfigWithACAFileWithoutCertificates(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	assert.Nil(t, os.WriteFile(caFile, []byte("not a certificate"), 0600))
+
+	_, err := TLSOptions{CAFile: caFile}.Config("localhost:8080")
+	assert.Error(t, err)
+}
+
+func TestTLSConfigWithAClientCertificateWithoutKey(t *testing.T) {
+	_, err := TLSOptions{CertFile: "client.pem"}.Config("localhost:8080")
+	assert.ErrorIs(t, err, ErrIncompleteClientCertificate)
+}
+
+func TestTLSConfigForAUnixTarget(t *testing.T) {
+	config, err := TLSOptions{}.Config("unix:///var/run/sidecar.sock")
+	assert.Nil(t, err)
+	assert.Equal(t, "", config.ServerName)
+}
+
+func TestTLSConfigForAUdpTarget(t *testing.T) {
+	_, err := TLSOptions{}.Config("udp://localhost:8125")
+	assert.ErrorIs(t, err, ErrTLSOverDatagram)
+}
@@ -4,8 +4,10 @@ import (
 	"errors"
 	"io"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/SarthakMakhija/blast-core/payload"
 	"github.com/SarthakMakhija/blast-core/report"
 )
 
@@ -16,11 +18,21 @@ var ErrNilConnection = errors.New("attempting to send request on a nil connectio
 // Worker sends load on the target connection.
 // connection field is usually a net.Conn.
 // Each connection is also given a unique connection id that is used for reporting.
+// index identifies the Worker in the WorkerGroup, it decides if the Worker is active in a stage of the LoadProfile.
+// The connection is a reconnectingConnection if the WorkerGroup re-establishes the connections that fail, the Worker
+// does not send requests while such a connection is down.
+// target is the name of the target server of the connection, it is empty if the load is sent to a single target.
+// The connection is a hashRoutingConnection if each request is routed to a target by the hash of its key, the
+// connection, the connection id and the target are then decided for each request.
+// connectionSequence is shared by the workers of a connection, it numbers the requests sent on the connection.
 type Worker struct {
-	connection   io.WriteCloser
-	connectionId int
-	options      WorkerOptions
-	requestId    *RequestId
+	index              uint
+	connection         io.WriteCloser
+	connectionId       int
+	connectionSequence *atomic.Uint64
+	target             string
+	options            WorkerOptions
+	requestId          *RequestId
 }
 
 // run runs a Worker.
@@ -35,14 +47,24 @@ func (worker Worker) run(wg *sync.WaitGroup) {
 // sendRequests sends worker.options.requestsPerRun on the connection.
 // Each Worker can be stopped by closing the stopChannel.
 // Each worker also implements throttle if worker.options.requestsPerSecond > 0.
+// The throttle follows a rate schedule: the nth request is intended to be sent at startTime + n/requestsPerSecond.
+// If sending a request stalls (for example, a blocked write), the worker sends the delayed requests without
+// waiting till it catches up with the schedule, and their latency is measured from the intended send time.
+// If the worker is configured with sendSlots (OpenLoadModel), it sends a request for each slot instead.
+// The worker stops once the total requests (worker.options.totalRequests) are sent by all the workers.
+// The worker does not send requests while its connection is down, and restarts the rate schedule once the
+// connection is re-established.
 func (worker Worker) sendRequests() {
-	var throttle <-chan time.Time = nil
+	if worker.options.sendSlots != nil {
+		worker.sendScheduledRequests()
+		return
+	}
+	var interval time.Duration
 	if worker.options.requestsPerSecond > 0 {
-		throttle = time.Tick(
-			time.Duration(1e6/(worker.options.requestsPerSecond)) * time.Microsecond,
-		)
+		interval = time.Duration(1e6/(worker.options.requestsPerSecond)) * time.Microsecond
 	}
 
+	intendedSendTime := time.Now()
 	maxDuration := time.Tick(worker.options.maxDuration)
 	for {
 		select {
@@ -51,36 +73,166 @@ func (worker Worker) sendRequests() {
 		case <-maxDuration:
 			return
 		default:
-			if worker.options.requestsPerSecond > 0 {
-				<-throttle
+			if !worker.connectionAvailable() {
+				time.Sleep(inactiveWorkerPollInterval)
+				intendedSendTime = time.Now()
+				continue
+			}
+			if interval > 0 {
+				intendedSendTime = intendedSendTime.Add(interval)
+				if wait := time.Until(intendedSendTime); wait > 0 {
+					time.Sleep(wait)
+				}
+				if !worker.sendRequest(intendedSendTime, false) {
+					return
+				}
+			} else if !worker.sendRequest(time.Time{}, false) {
+				return
+			}
+		}
+	}
+}
+
+// sendScheduledRequests sends a request for each slot received on worker.options.sendSlots.
+// Each slot carries the time at which the request was scheduled. The request is reported as late,
+// if it is sent more than worker.options.lateSendThreshold after its scheduled time.
+// A Worker that is not active (in the current stage of the LoadProfile), or whose connection is down, does not
+// receive the slots.
+// The worker stops once worker.options.sendSlots is closed, after all the total requests are scheduled.
+func (worker Worker) sendScheduledRequests() {
+	maxDuration := time.Tick(worker.options.maxDuration)
+	for {
+		if !worker.isActive() || !worker.connectionAvailable() {
+			select {
+			case <-worker.options.stopChannel:
+				return
+			case <-maxDuration:
+				return
+			case <-time.After(inactiveWorkerPollInterval):
+				continue
 			}
-			worker.sendRequest()
 		}
+		select {
+		case <-worker.options.stopChannel:
+			return
+		case <-maxDuration:
+			return
+		case scheduledTime, ok := <-worker.options.sendSlots:
+			if !ok {
+				return
+			}
+			if !worker.sendRequest(scheduledTime, time.Since(scheduledTime) > worker.options.lateSendThreshold) {
+				return
+			}
+		}
+	}
+}
+
+// isActive returns true if the Worker is one of the worker.options.activeWorkers, or if all the workers are active.
+func (worker Worker) isActive() bool {
+	if worker.options.activeWorkers == nil {
+		return true
+	}
+	activeWorkers := worker.options.activeWorkers.Load()
+	return activeWorkers == 0 || uint64(worker.index) < activeWorkers
+}
+
+// connectionAvailable returns false if the connection of the Worker is a reconnectingConnection that is down.
+func (worker Worker) connectionAvailable() bool {
+	if connection, ok := worker.connection.(*reconnectingConnection); ok {
+		return connection.available()
+	}
+	return true
+}
+
+// requestContext returns the payload.RequestContext of the request identified by the requestId.
+// The connection sequence is zero if the Worker does not number the requests of its connection.
+func (worker Worker) requestContext(requestId uint64) payload.RequestContext {
+	var connectionSequence uint64
+	if worker.connectionSequence != nil {
+		connectionSequence = worker.connectionSequence.Add(1)
+	}
+	var elapsed time.Duration
+	if !worker.options.startTime.IsZero() {
+		elapsed = time.Since(worker.options.startTime)
+	}
+	return payload.RequestContext{
+		RequestId:          requestId,
+		ConnectionId:       worker.connectionId,
+		WorkerId:           worker.index,
+		ConnectionSequence: connectionSequence,
+		Elapsed:            elapsed,
+		Session:            sessionOf(worker.connection),
 	}
 }
 
 // sendRequest sends a single request.
+// It returns false (without sending the request) if the total requests (worker.options.totalRequests) have
+// already been sent, or if the request could not be reported because blast is stopping.
 // The result of sending the request is sent on the channel identified by worker.options.loadGenerationResponse.
-func (worker Worker) sendRequest() {
+// If the worker is configured with report.InFlightRequests, the send time of the request is recorded
+// before the request is written, so that the response can be correlated with the request.
+// intendedSendTime is the time at which the request was supposed to be sent as per the rate schedule, it is
+// the same as the send time if the request is not scheduled (zero).
+// late marks the request that is sent later than its scheduled time (OpenLoadModel).
+func (worker Worker) sendRequest(intendedSendTime time.Time, late bool) (sent bool) {
 	defer func() {
-		_ = recover()
+		if recover() != nil {
+			sent = false
+		}
 	}()
+	requestId, ok := worker.requestId.NextWithinLimit(worker.options.totalRequests)
+	if !ok {
+		return false
+	}
+	target := worker.target
 	if worker.connection != nil {
-		payload := worker.options.payloadGenerator.Generate(worker.requestId.Next())
-		_, err := worker.connection.Write(payload)
+		payload := worker.options.payloadGenerator.GenerateWithContext(worker.requestContext(requestId))
+		connection, connectionId := worker.connection, worker.connectionId
+		if routing, ok := worker.connection.(*hashRoutingConnection); ok {
+			connection, connectionId, target = routing.route(payload)
+		}
+		if connection == nil {
+			worker.options.loadGenerationResponse <- report.LoadGenerationResponse{
+				Err:                ErrNilConnection,
+				LoadGenerationTime: time.Now(),
+				ConnectionId:       report.NilConnectionId,
+				RequestId:          requestId,
+				Late:               late,
+				Target:             target,
+			}
+			return true
+		}
+		if worker.options.inFlightRequests != nil {
+			sendTime := time.Now()
+			if intendedSendTime.IsZero() {
+				intendedSendTime = sendTime
+			}
+			worker.options.inFlightRequests.AddWithIntendedSendTime(requestId, sendTime, intendedSendTime)
+		}
+		_, err := connection.Write(payload)
+		if err != nil && worker.options.inFlightRequests != nil {
+			worker.options.inFlightRequests.Remove(requestId)
+		}
 
 		worker.options.loadGenerationResponse <- report.LoadGenerationResponse{
 			Err:                err,
 			PayloadLengthBytes: int64(len(payload)),
 			LoadGenerationTime: time.Now(),
-			ConnectionId:       worker.connectionId,
+			ConnectionId:       connectionId,
+			RequestId:          requestId,
+			Late:               late,
+			Target:             target,
 		}
-		return
+		return true
 	}
 	worker.options.loadGenerationResponse <- report.LoadGenerationResponse{
 		Err:                ErrNilConnection,
 		PayloadLengthBytes: 0,
 		LoadGenerationTime: time.Now(),
 		ConnectionId:       report.NilConnectionId,
+		Late:               late,
+		Target:             target,
 	}
+	return true
 }
@@ -1,23 +1,52 @@
 package workers
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
 	"github.com/SarthakMakhija/blast-core/report"
+	"io"
 	"net"
 	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// workersDoneTimeout is the maximum time CloseConnections waits for the workers to be done, before closing the
+// connections.
+const workersDoneTimeout = time.Second
+
 // WorkerGroup is a collection of workers that sends requestsPerRun to the server.
 // WorkerGroup creates a total of GroupOptions.concurrency Workers.
-// Each Worker sends WorkerOptions.requestsPerSecond requests per second.
+// Each Worker sends WorkerOptions.requestsPerSecond requests per second (ClosedLoadModel), or
+// the workers together send GroupOptions.arrivalRate requests per second (OpenLoadModel), or
+// the workers follow the stages of GroupOptions.loadProfile.
 // WorkerGroup also provides support for triggering response reading from the connection.
+// If GroupOptions.reconnectPolicy is enabled, WorkerGroup re-establishes the connections that fail, and if
+// GroupOptions.churnPolicy is enabled, WorkerGroup churns (closes and re-opens) the connections.
+// If GroupOptions.tlsConfig is set, WorkerGroup establishes TLS connections.
+// If GroupOptions.targets are set, WorkerGroup distributes the connections (or the requests, with the
+// ConsistentHashBalancing strategy) across the targets as per GroupOptions.loadBalancing.
+// WorkerGroup records the latency and the errors of all the dials (and of the TLS handshakes).
 type WorkerGroup struct {
-	options        GroupOptions
-	stopChannel    chan struct{}
-	doneChannel    chan struct{}
-	responseReader *report.ResponseReader
-	requestId      *RequestId
+	options                 GroupOptions
+	stopChannel             chan struct{}
+	doneChannel             chan struct{}
+	responseReader          *report.ResponseReader
+	requestId               *RequestId
+	connectionsAlive        atomic.Int64
+	connections             *connectionRegistry
+	connectionStopChannel   chan struct{}
+	reconnectingConnections []*reconnectingConnection
+	reconnectingLock        sync.Mutex
+	dialRecorder            *report.DialRecorder
+	targets                 []Target
+	targetErr               error
+	balancer                targetBalancer
+	ring                    hashRing
+	startTime               time.Time
 }
 
 // NewWorkerGroup returns a new instance of WorkerGroup without supporting reading from the
@@ -32,20 +61,33 @@ func NewWorkerGroupWithResponseReader(
 	options GroupOptions,
 	responseReader *report.ResponseReader,
 ) *WorkerGroup {
-	return &WorkerGroup{
-		options:        options,
-		stopChannel:    make(chan struct{}, options.concurrency),
-		doneChannel:    make(chan struct{}, 1),
-		responseReader: responseReader,
-		requestId:      NewRequestId(),
+	group := &WorkerGroup{
+		options:               options,
+		stopChannel:           make(chan struct{}, options.concurrency),
+		doneChannel:           make(chan struct{}),
+		responseReader:        responseReader,
+		requestId:             NewRequestId(),
+		connectionStopChannel: make(chan struct{}),
+		dialRecorder:          report.NewDialRecorder(),
+		connections:           newConnectionRegistry(),
+	}
+	group.targets, group.targetErr = options.targetList()
+	if group.targetErr == nil {
+		group.balancer = newTargetBalancer(group.targets, options.loadBalancing)
+		if group.routesByHash() {
+			group.ring = newHashRing(group.targets)
+		}
 	}
+	return group
 }
 
 // Run runs the WorkerGroup and returns a channel of type report.LoadGenerationResponse.
 // report.LoadGenerationResponse will contain each request sent by the Worker.
 // This method runs a separate goroutine that runs the workers and the goroutine waits until
 // all the workers are done.
+// The time of running the WorkerGroup is its StartTime.
 func (group *WorkerGroup) Run() chan report.LoadGenerationResponse {
+	group.startTime = time.Now()
 	loadGenerationResponseChannel := make(
 		chan report.LoadGenerationResponse,
 		group.options.ExpectedLoadInTotalDuration(),
@@ -59,6 +101,13 @@ func (group *WorkerGroup) Run() chan report.LoadGenerationResponse {
 	return loadGenerationResponseChannel
 }
 
+// StartTime returns the start time of the WorkerGroup, the requests (of the rate or the arrival schedule) are
+// scheduled from the StartTime. It is set by Run, and it is the start time that the report.Reporter is to be
+// configured with (report.ReporterOptions), so that the report stages line up with the schedule.
+func (group *WorkerGroup) StartTime() time.Time {
+	return group.startTime
+}
+
 // Close closes sends a stop signal to all the workers.
 func (group *WorkerGroup) Close() {
 	for count := 1; count <= int(group.options.concurrency); count++ {
@@ -74,32 +123,70 @@ func (group *WorkerGroup) Close() {
 // This configuration will end up sharing a single connection with four workers.
 // runWorkers also starts the report.ResponseReader to read from the connection,
 // if it is configured to do so.
+// If the connections are re-established (GroupOptions.reconnectPolicy) or churned (GroupOptions.churnPolicy),
+// each connection is a reconnectingConnection which is created even if the connection can not be established
+// at dial time, and runWorkers also runs a connectionChurner which churns the connections as per their lifetime
+// or the rate of new connections.
+// In the OpenLoadModel, runWorkers also runs an arrivalScheduler which hands the scheduled requests
+// to the workers.
 func (group *WorkerGroup) runWorkers(loadGenerationResponseChannel chan report.LoadGenerationResponse) {
+	var sendSlots chan time.Time
+	var activeWorkers *atomic.Uint64
+	if group.options.loadModel == OpenLoadModel {
+		sendSlots = make(chan time.Time)
+		activeWorkers = &atomic.Uint64{}
+	}
+
 	//creates new instance of Workers.
 	instantiateWorkers := func() []Worker {
 		connectionsSharedByWorker := group.options.concurrency / group.options.connections
 
-		var connection net.Conn
-		var err error
+		var connection io.WriteCloser
+		var connectionSequence *atomic.Uint64
+		var target string
 
 		var connectionId = -1
+		nextConnectionId := func() int {
+			connectionId = connectionId + 1
+			return connectionId
+		}
 		var workers []Worker
 		for count := 0; count < int(group.options.concurrency); count++ {
 			if count%int(connectionsSharedByWorker) == 0 || connection == nil {
-				connection, err = group.newConnection()
-				if err != nil {
-					_, _ = fmt.Fprintf(os.Stderr, "[WorkerGroup] %v\n", err.Error())
-				} else {
-					connectionId = connectionId + 1
-				}
-				if group.responseReader != nil && connection != nil {
-					group.responseReader.StartReading(connection)
-				}
+				connection, target = group.newTargetConnection(count/int(connectionsSharedByWorker), nextConnectionId)
+				connectionSequence = &atomic.Uint64{}
+			}
+			workers = append(workers, group.instantiateWorker(uint(count), connection, connectionId, connectionSequence, target, sendSlots, activeWorkers, loadGenerationResponseChannel))
+		}
+		return workers
+	}
+
+	//creates new instance of Workers that share the reconnectingConnections.
+	instantiateWorkersWithReconnectingConnections := func() []Worker {
+		connectionsSharedByWorker := group.options.concurrency / group.options.connections
+
+		var connection io.WriteCloser
+		var connectionSequence *atomic.Uint64
+		var target string
+
+		var connectionId = -1
+		nextConnectionId := func() int {
+			connectionId = connectionId + 1
+			return connectionId
+		}
+		var workers []Worker
+		for count := 0; count < int(group.options.concurrency); count++ {
+			if count%int(connectionsSharedByWorker) == 0 {
+				connection, target = group.newReconnectingTargetConnection(count/int(connectionsSharedByWorker), nextConnectionId)
+				connectionSequence = &atomic.Uint64{}
 			}
-			workers = append(workers, group.instantiateWorker(connection, connectionId, loadGenerationResponseChannel))
+			workers = append(workers, group.instantiateWorker(uint(count), connection, connectionId, connectionSequence, target, sendSlots, activeWorkers, loadGenerationResponseChannel))
 		}
 		return workers
 	}
+	if group.options.reconnectPolicy.enabled() || group.options.churnPolicy.enabled() {
+		instantiateWorkers = instantiateWorkersWithReconnectingConnections
+	}
 
 	//runs all the workers.
 	//runWorkersAndWait will wait till all the workers are done.
@@ -112,39 +199,336 @@ func (group *WorkerGroup) runWorkers(loadGenerationResponseChannel chan report.L
 		}
 		wg.Wait()
 	}
-	runWorkersAndWait(instantiateWorkers())
-	group.doneChannel <- struct{}{}
+
+	//runs the arrivalScheduler (OpenLoadModel) till all the workers are done.
+	runSchedulerAndWorkersAndWait := func(workers []Worker) {
+		var schedulerWg sync.WaitGroup
+		schedulerWg.Add(1)
+
+		schedulerStopChannel := make(chan struct{})
+		arrivalScheduler{
+			startTime:              group.startTime,
+			schedule:               group.options.arrivalSchedule(),
+			maxDuration:            group.options.maxDuration,
+			sendSlots:              sendSlots,
+			activeWorkers:          activeWorkers,
+			stopChannel:            schedulerStopChannel,
+			loadGenerationResponse: loadGenerationResponseChannel,
+			totalRequests:          uint64(group.options.totalRequests),
+		}.run(&schedulerWg)
+
+		runWorkersAndWait(workers)
+		close(schedulerStopChannel)
+		schedulerWg.Wait()
+	}
+
+	workers := instantiateWorkers()
+
+	var churnerWg sync.WaitGroup
+	churnerWg.Add(1)
+	connectionChurner{
+		policy:      group.options.churnPolicy,
+		connections: group.reconnectingConnections,
+		stopChannel: group.connectionStopChannel,
+	}.run(&churnerWg)
+
+	if sendSlots != nil {
+		runSchedulerAndWorkersAndWait(workers)
+	} else {
+		runWorkersAndWait(workers)
+	}
+	group.stopReconnecting()
+	churnerWg.Wait()
+	close(group.doneChannel)
+}
+
+// CloseConnections closes all the connections (created by the WorkerGroup) that are alive, once all the workers
+// are done. A worker that is blocked on its connection (for example, a write to a server that does not read) may
+// not be done after the stop signal, the connections are closed after workersDoneTimeout in that case, which
+// unblocks such a worker.
+// CloseConnections is invoked once the load completes, so that a WorkerGroup does not leave its connections open
+// (see TotalConnectionsAlive).
+func (group *WorkerGroup) CloseConnections() {
+	select {
+	case <-group.doneChannel:
+	case <-time.After(workersDoneTimeout):
+	}
+	group.connections.closeAll()
 }
 
 // WaitTillDone waits till all the workers are done.
+// The doneChannel is closed once all the workers are done, so WaitTillDone can be called more than once.
 func (group *WorkerGroup) WaitTillDone() {
 	<-group.doneChannel
 }
 
-// DoneChannel returns the doneChannel.
+// DoneChannel returns the doneChannel, which is closed once all the workers are done.
 func (group *WorkerGroup) DoneChannel() chan struct{} {
 	return group.doneChannel
 }
 
-// newConnection creates a new TCP connection.
-func (group *WorkerGroup) newConnection() (net.Conn, error) {
+// TotalConnectionsAlive returns the total number of connections that are established and not closed yet.
+func (group *WorkerGroup) TotalConnectionsAlive() uint {
+	return uint(group.connectionsAlive.Load())
+}
+
+// ConnectionMetrics returns the report.ConnectionMetrics of the connections that were re-established or were
+// down during the run. It returns nil if the connections are not re-established (GroupOptions.reconnectPolicy).
+func (group *WorkerGroup) ConnectionMetrics() []report.ConnectionMetrics {
+	group.reconnectingLock.Lock()
+	defer group.reconnectingLock.Unlock()
+
+	var connections []report.ConnectionMetrics
+	for _, connection := range group.reconnectingConnections {
+		metrics := connection.metrics()
+		if metrics.Reconnects > 0 || metrics.Downtime > 0 {
+			connections = append(connections, metrics)
+		}
+	}
+	return connections
+}
+
+// newTargetConnection creates the connection with the given index (starting at 0), and returns it along with
+// the name of its target (see targetName).
+// The connection is a hashRoutingConnection with a connection to each of the targets, if the requests are routed
+// by the hash of their keys. It is nil if the connection can not be established.
+// nextConnectionId is invoked for each connection that is established.
+func (group *WorkerGroup) newTargetConnection(
+	connectionIndex int,
+	nextConnectionId func() int,
+) (io.WriteCloser, string) {
+	dial := func(target Target) net.Conn {
+		connection, err := group.newConnection(target)
+		if err != nil {
+			_, _ = fmt.Fprintf(os.Stderr, "[WorkerGroup] %v\n", err.Error())
+			return nil
+		}
+		group.startReading(connection, target)
+		return connection
+	}
+	if group.routesByHash() {
+		routing := newHashRoutingConnection(group.ring, group.options.hashKey)
+		for _, target := range group.targets {
+			if connection := dial(target); connection != nil {
+				routing.add(connection, nextConnectionId(), group.targetName(target))
+			} else {
+				routing.add(nil, report.NilConnectionId, group.targetName(target))
+			}
+		}
+		return routing, ""
+	}
+	target := group.targetOf(connectionIndex)
+	connection := dial(target)
+	if connection == nil {
+		return nil, group.targetName(target)
+	}
+	nextConnectionId()
+	return connection, group.targetName(target)
+}
+
+// newReconnectingTargetConnection creates the reconnectingConnection with the given index (starting at 0), and
+// returns it along with the name of its target (see targetName).
+// The connection is a hashRoutingConnection with a reconnectingConnection to each of the targets, if the requests
+// are routed by the hash of their keys.
+// nextConnectionId is invoked for each reconnectingConnection.
+func (group *WorkerGroup) newReconnectingTargetConnection(
+	connectionIndex int,
+	nextConnectionId func() int,
+) (io.WriteCloser, string) {
+	if group.routesByHash() {
+		routing := newHashRoutingConnection(group.ring, group.options.hashKey)
+		for _, target := range group.targets {
+			connectionId := nextConnectionId()
+			routing.add(group.newReconnectingConnection(connectionId, target), connectionId, group.targetName(target))
+		}
+		return routing, ""
+	}
+	target := group.targetOf(connectionIndex)
+	return group.newReconnectingConnection(nextConnectionId(), target), group.targetName(target)
+}
+
+// newReconnectingConnection creates a new reconnectingConnection with the given id and target.
+// The report.ResponseReader (if configured) starts reading from the connection each time it is established.
+func (group *WorkerGroup) newReconnectingConnection(connectionId int, target Target) *reconnectingConnection {
+	startReading := func(connection net.Conn) {
+		group.startReading(connection, target)
+	}
+	dial := func() (net.Conn, error) {
+		return group.newConnection(target)
+	}
+	connection, err := dial()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "[WorkerGroup] %v\n", err.Error())
+	} else {
+		startReading(connection)
+	}
+	reconnecting := newReconnectingConnection(
+		connectionId,
+		connection,
+		group.options.reconnectPolicy,
+		group.options.churnPolicy,
+		dial,
+		startReading,
+		group.connectionStopChannel,
+	)
+
+	group.reconnectingLock.Lock()
+	defer group.reconnectingLock.Unlock()
+	group.reconnectingConnections = append(group.reconnectingConnections, reconnecting)
+	return reconnecting
+}
+
+// DialMetrics returns the report.DialMetrics of all the dials, along with the network of the targets.
+// The distinct networks are separated by a comma, if the targets are on different networks.
+func (group *WorkerGroup) DialMetrics() report.DialMetrics {
+	metrics := group.dialRecorder.Metrics()
+	var networks []string
+	uniqueNetworks := make(map[string]bool)
+	for _, target := range group.targets {
+		if !uniqueNetworks[target.Network] {
+			uniqueNetworks[target.Network] = true
+			networks = append(networks, target.Network)
+		}
+	}
+	metrics.Network = strings.Join(networks, ",")
+	return metrics
+}
+
+// routesByHash returns true if each request is routed to one of the multiple targets by the hash of its key
+// (ConsistentHashBalancing).
+func (group *WorkerGroup) routesByHash() bool {
+	return group.options.loadBalancing == ConsistentHashBalancing && len(group.targets) > 1
+}
+
+// targetOf returns the target of the connection with the given index (starting at 0).
+// It returns an empty Target if the targets could not be parsed, the dial of such a target fails.
+func (group *WorkerGroup) targetOf(connectionIndex int) Target {
+	if len(group.targets) == 0 {
+		return Target{}
+	}
+	return group.balancer.targetOf(connectionIndex)
+}
+
+// targetName returns the name of the target that is reported with the load and the responses.
+// It is empty if there is a single target, so that the report does not include the per-target metrics.
+func (group *WorkerGroup) targetName(target Target) string {
+	if len(group.targets) <= 1 {
+		return ""
+	}
+	return target.String()
+}
+
+// startReading starts reading the responses from the connection with the target, if the report.ResponseReader
+// is configured.
+func (group *WorkerGroup) startReading(connection net.Conn, target Target) {
+	if group.responseReader != nil {
+		group.responseReader.StartReadingFromTarget(connection, group.targetName(target))
+	}
+}
+
+// stopReconnecting stops re-establishing (and churning) the connections, once all the workers are done.
+func (group *WorkerGroup) stopReconnecting() {
+	close(group.connectionStopChannel)
+
+	group.reconnectingLock.Lock()
+	defer group.reconnectingLock.Unlock()
+	for _, connection := range group.reconnectingConnections {
+		connection.stop()
+	}
+}
+
+// newConnection creates a new connection with the target on its network (tcp, unix or udp), and records the latency
+// and the error (if any) of the dial.
+// The connection is initialized by the ConnectionInitializer (if configured) before it is returned, so that the
+// workers and the report.ResponseReader only use the initialized connections.
+// It returns the error of parsing the targets, if they could not be parsed.
+func (group *WorkerGroup) newConnection(target Target) (net.Conn, error) {
+	if group.targetErr != nil {
+		return nil, group.targetErr
+	}
+	dialStartTime := time.Now()
 	connection, err := net.DialTimeout(
-		"tcp",
-		group.options.targetAddress,
+		target.Network,
+		target.Address,
 		group.options.dialTimeout,
 	)
+	group.dialRecorder.Record(time.Since(dialStartTime), err)
+	if err != nil {
+		return nil, err
+	}
+	if group.options.tlsConfig != nil {
+		if connection, err = group.handshake(connection, target); err != nil {
+			return nil, err
+		}
+	}
+	var session any
+	if group.options.connectionInitializer != nil {
+		timeout := group.options.initializationTimeout
+		if timeout == 0 {
+			timeout = group.options.dialTimeout
+		}
+		if session, err = initialize(connection, group.options.connectionInitializer, timeout, group.dialRecorder); err != nil {
+			return nil, err
+		}
+	}
+	tracked := newTrackedConnection(connection, &group.connectionsAlive)
+	tracked.session = session
+	group.connections.add(tracked)
+	return tracked, nil
+}
+
+// handshake performs the TLS handshake on the (TCP) connection within the dial timeout, and records the time
+// taken by the handshake separately from the time taken by the connect.
+// The host of the target is the server name indication (SNI), if the tls.Config does not specify the ServerName.
+// The connection is closed if the handshake fails.
+func (group *WorkerGroup) handshake(connection net.Conn, target Target) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), group.options.dialTimeout)
+	defer cancel()
+
+	config := group.options.tlsConfig
+	if config.ServerName == "" && target.Network == "tcp" {
+		if host, _, err := net.SplitHostPort(target.Address); err == nil {
+			config = config.Clone()
+			config.ServerName = host
+		}
+	}
+	tlsConnection := tls.Client(connection, config)
+	handshakeStartTime := time.Now()
+	err := tlsConnection.HandshakeContext(ctx)
+	group.dialRecorder.RecordHandshake(
+		time.Since(handshakeStartTime),
+		err == nil && tlsConnection.ConnectionState().DidResume,
+		err,
+	)
 	if err != nil {
+		_ = connection.Close()
 		return nil, err
 	}
-	return connection, nil
+	return tlsConnection, nil
 }
 
 // instantiateWorker creates a new Worker.
-func (group *WorkerGroup) instantiateWorker(connection net.Conn, connectionId int, loadGenerationResponseChannel chan report.LoadGenerationResponse) Worker {
+func (group *WorkerGroup) instantiateWorker(
+	index uint,
+	connection io.WriteCloser,
+	connectionId int,
+	connectionSequence *atomic.Uint64,
+	target string,
+	sendSlots chan time.Time,
+	activeWorkers *atomic.Uint64,
+	loadGenerationResponseChannel chan report.LoadGenerationResponse,
+) Worker {
+	var inFlightRequests *report.InFlightRequests
+	if group.responseReader != nil {
+		inFlightRequests = group.responseReader.InFlightRequests()
+	}
 	return Worker{
-		connection:   connection,
-		connectionId: connectionId,
-		requestId:    group.requestId,
+		index:              index,
+		connection:         connection,
+		connectionId:       connectionId,
+		connectionSequence: connectionSequence,
+		target:             target,
+		requestId:          group.requestId,
 		options: WorkerOptions{
 			maxDuration:            group.options.maxDuration,
 			payloadGenerator:       group.options.payloadGenerator,
@@ -152,6 +536,12 @@ func (group *WorkerGroup) instantiateWorker(connection net.Conn, connectionId in
 			requestsPerSecond:      group.options.requestsPerSecond,
 			stopChannel:            group.stopChannel,
 			loadGenerationResponse: loadGenerationResponseChannel,
+			inFlightRequests:       inFlightRequests,
+			sendSlots:              sendSlots,
+			lateSendThreshold:      lateSendThreshold,
+			activeWorkers:          activeWorkers,
+			totalRequests:          uint64(group.options.totalRequests),
+			startTime:              group.startTime,
 		},
 	}
 }
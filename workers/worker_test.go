@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"github.com/SarthakMakhija/blast-core/payload"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -21,6 +22,23 @@ func (writeCloser *BytesWriteCloser) Close() error {
 	return nil
 }
 
+type StallingWriteCloser struct {
+	stall  time.Duration
+	writes int
+}
+
+func (writeCloser *StallingWriteCloser) Write(payload []byte) (int, error) {
+	writeCloser.writes++
+	if writeCloser.writes == 1 {
+		time.Sleep(writeCloser.stall)
+	}
+	return len(payload), nil
+}
+
+func (writeCloser *StallingWriteCloser) Close() error {
+	return nil
+}
+
 func TestWritesPayloadByWorker(t *testing.T) {
 	loadGenerationResponse := make(chan report.LoadGenerationResponse)
 
@@ -30,7 +48,7 @@ func TestWritesPayloadByWorker(t *testing.T) {
 		requestId:  NewRequestId(),
 		options: WorkerOptions{
 			maxDuration:            2 * time.Millisecond,
-			payloadGenerator:       payload.NewConstantPayloadGenerator([]byte("payload")),
+			payloadGenerator:       payload.NewPayloadGeneratorAdapter(payload.NewConstantPayloadGenerator([]byte("payload"))),
 			loadGenerationResponse: loadGenerationResponse,
 		},
 	}
@@ -60,7 +78,7 @@ func TestWritesMultiplePayloadsByWorker(t *testing.T) {
 		requestId:  NewRequestId(),
 		options: WorkerOptions{
 			maxDuration:            2 * time.Millisecond,
-			payloadGenerator:       payload.NewConstantPayloadGenerator([]byte("payload")),
+			payloadGenerator:       payload.NewPayloadGeneratorAdapter(payload.NewConstantPayloadGenerator([]byte("payload"))),
 			loadGenerationResponse: loadGenerationResponse,
 		},
 	}
@@ -89,7 +107,7 @@ func TestWritesMultiplePayloadsByWorkerWithThrottle(t *testing.T) {
 		requestId:  NewRequestId(),
 		options: WorkerOptions{
 			maxDuration:            2 * time.Millisecond,
-			payloadGenerator:       payload.NewConstantPayloadGenerator([]byte("payload")),
+			payloadGenerator:       payload.NewPayloadGeneratorAdapter(payload.NewConstantPayloadGenerator([]byte("payload"))),
 			loadGenerationResponse: loadGenerationResponse,
 			requestsPerSecond:      float64(3),
 		},
@@ -118,7 +136,7 @@ func TestWritesOnANilConnectionWithConnectionId(t *testing.T) {
 		requestId:  NewRequestId(),
 		options: WorkerOptions{
 			maxDuration:            2 * time.Millisecond,
-			payloadGenerator:       payload.NewConstantPayloadGenerator([]byte("payload")),
+			payloadGenerator:       payload.NewPayloadGeneratorAdapter(payload.NewConstantPayloadGenerator([]byte("payload"))),
 			loadGenerationResponse: loadGenerationResponse,
 		},
 	}
@@ -149,7 +167,7 @@ func TestWritesPayloadByWorkerWithConnectionId(t *testing.T) {
 		connectionId: 10,
 		options: WorkerOptions{
 			maxDuration:            2 * time.Millisecond,
-			payloadGenerator:       payload.NewConstantPayloadGenerator([]byte("payload")),
+			payloadGenerator:       payload.NewPayloadGeneratorAdapter(payload.NewConstantPayloadGenerator([]byte("payload"))),
 			loadGenerationResponse: loadGenerationResponse,
 		},
 	}
@@ -168,3 +186,244 @@ func TestWritesPayloadByWorkerWithConnectionId(t *testing.T) {
 
 	close(loadGenerationResponse)
 }
+
+func TestRecordsTheSendTimeOfInFlightRequests(t *testing.T) {
+	loadGenerationResponse := make(chan report.LoadGenerationResponse)
+	inFlightRequests := report.NewInFlightRequests()
+
+	var buffer bytes.Buffer
+	worker := Worker{
+		connection: &BytesWriteCloser{bufio.NewWriter(&buffer)},
+		requestId:  NewRequestId(),
+		options: WorkerOptions{
+			maxDuration:            2 * time.Millisecond,
+			payloadGenerator:       payload.NewPayloadGeneratorAdapter(payload.NewConstantPayloadGenerator([]byte("payload"))),
+			loadGenerationResponse: loadGenerationResponse,
+			inFlightRequests:       inFlightRequests,
+		},
+	}
+
+	totalRequests := 0
+	doneChannel := make(chan struct{})
+	go func() {
+		for response := range loadGenerationResponse {
+			assert.Nil(t, response.Err)
+			totalRequests++
+		}
+		close(doneChannel)
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	worker.run(&wg)
+	wg.Wait()
+
+	close(loadGenerationResponse)
+	<-doneChannel
+
+	assert.Equal(t, totalRequests, inFlightRequests.Size())
+	_, ok := inFlightRequests.Remove(1)
+	assert.True(t, ok)
+}
+
+func TestWritesScheduledPayloadsByWorkerAndReportsLateRequests(t *testing.T) {
+	loadGenerationResponse := make(chan report.LoadGenerationResponse, 2)
+	sendSlots := make(chan time.Time, 2)
+
+	var buffer bytes.Buffer
+	worker := Worker{
+		connection: &BytesWriteCloser{bufio.NewWriter(&buffer)},
+		requestId:  NewRequestId(),
+		options: WorkerOptions{
+			maxDuration:            5 * time.Millisecond,
+			payloadGenerator:       payload.NewPayloadGeneratorAdapter(payload.NewConstantPayloadGenerator([]byte("payload"))),
+			loadGenerationResponse: loadGenerationResponse,
+			sendSlots:              sendSlots,
+			lateSendThreshold:      time.Second,
+		},
+	}
+	sendSlots <- time.Now()
+	sendSlots <- time.Now().Add(-2 * time.Second)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	worker.run(&wg)
+	wg.Wait()
+
+	close(loadGenerationResponse)
+
+	var lateRequests []bool
+	for response := range loadGenerationResponse {
+		assert.Nil(t, response.Err)
+		lateRequests = append(lateRequests, response.Late)
+	}
+	assert.Equal(t, []bool{false, true}, lateRequests)
+}
+
+func TestRecordsTheIntendedSendTimeOfInFlightRequestsAsPerTheRateSchedule(t *testing.T) {
+	loadGenerationResponse := make(chan report.LoadGenerationResponse, 100)
+	inFlightRequests := report.NewInFlightRequests()
+
+	worker := Worker{
+		connection: &StallingWriteCloser{stall: 20 * time.Millisecond},
+		requestId:  NewRequestId(),
+		options: WorkerOptions{
+			maxDuration:            30 * time.Millisecond,
+			payloadGenerator:       payload.NewPayloadGeneratorAdapter(payload.NewConstantPayloadGenerator([]byte("payload"))),
+			loadGenerationResponse: loadGenerationResponse,
+			inFlightRequests:       inFlightRequests,
+			requestsPerSecond:      float64(1000),
+		},
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	worker.run(&wg)
+	wg.Wait()
+
+	close(loadGenerationResponse)
+
+	firstSendTime, firstIntendedSendTime, ok := inFlightRequests.RemoveWithIntendedSendTime(1)
+	assert.True(t, ok)
+	secondSendTime, secondIntendedSendTime, ok := inFlightRequests.RemoveWithIntendedSendTime(2)
+	assert.True(t, ok)
+
+	assert.Equal(t, time.Millisecond, secondIntendedSendTime.Sub(firstIntendedSendTime))
+	assert.True(t, secondSendTime.Sub(firstSendTime) >= 20*time.Millisecond)
+	assert.True(t, secondSendTime.Sub(secondIntendedSendTime) >= 15*time.Millisecond)
+}
+
+func TestDoesNotSendScheduledPayloadsByAnInactiveWorker(t *testing.T) {
+	loadGenerationResponse := make(chan report.LoadGenerationResponse, 1)
+	sendSlots := make(chan time.Time, 1)
+	activeWorkers := &atomic.Uint64{}
+	activeWorkers.Store(2)
+
+	var buffer bytes.Buffer
+	worker := Worker{
+		index:      2,
+		connection: &BytesWriteCloser{bufio.NewWriter(&buffer)},
+		requestId:  NewRequestId(),
+		options: WorkerOptions{
+			maxDuration:            30 * time.Millisecond,
+			payloadGenerator:       payload.NewPayloadGeneratorAdapter(payload.NewConstantPayloadGenerator([]byte("payload"))),
+			loadGenerationResponse: loadGenerationResponse,
+			sendSlots:              sendSlots,
+			activeWorkers:          activeWorkers,
+		},
+	}
+	sendSlots <- time.Now()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	worker.run(&wg)
+	wg.Wait()
+
+	close(loadGenerationResponse)
+	assert.Equal(t, 1, len(sendSlots))
+	assert.Equal(t, 0, len(loadGenerationResponse))
+}
+
+func TestStopsTheWorkerAfterSendingTheTotalRequests(t *testing.T) {
+	loadGenerationResponse := make(chan report.LoadGenerationResponse, 10)
+	requestId := NewRequestId()
+
+	var buffer bytes.Buffer
+	worker := Worker{
+		connection: &BytesWriteCloser{bufio.NewWriter(&buffer)},
+		requestId:  requestId,
+		options: WorkerOptions{
+			maxDuration:            time.Minute,
+			payloadGenerator:       payload.NewPayloadGeneratorAdapter(payload.NewConstantPayloadGenerator([]byte("payload"))),
+			loadGenerationResponse: loadGenerationResponse,
+			totalRequests:          5,
+		},
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	worker.run(&wg)
+	wg.Wait()
+
+	close(loadGenerationResponse)
+	assert.Equal(t, 5, len(loadGenerationResponse))
+	_, ok := requestId.NextWithinLimit(5)
+	assert.False(t, ok)
+}
+
+func TestStopsTheWorkerWhenTheSendSlotsAreClosed(t *testing.T) {
+	loadGenerationResponse := make(chan report.LoadGenerationResponse, 2)
+	sendSlots := make(chan time.Time, 2)
+
+	var buffer bytes.Buffer
+	worker := Worker{
+		connection: &BytesWriteCloser{bufio.NewWriter(&buffer)},
+		requestId:  NewRequestId(),
+		options: WorkerOptions{
+			maxDuration:            time.Minute,
+			payloadGenerator:       payload.NewPayloadGeneratorAdapter(payload.NewConstantPayloadGenerator([]byte("payload"))),
+			loadGenerationResponse: loadGenerationResponse,
+			sendSlots:              sendSlots,
+			lateSendThreshold:      time.Second,
+		},
+	}
+	sendSlots <- time.Now()
+	sendSlots <- time.Now()
+	close(sendSlots)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	worker.run(&wg)
+	wg.Wait()
+
+	close(loadGenerationResponse)
+	assert.Equal(t, 2, len(loadGenerationResponse))
+}
+
+type recordingPayloadGenerator struct {
+	lock     sync.Mutex
+	contexts []payload.RequestContext
+}
+
+func (generator *recordingPayloadGenerator) GenerateWithContext(requestContext payload.RequestContext) []byte {
+	generator.lock.Lock()
+	defer generator.lock.Unlock()
+	generator.contexts = append(generator.contexts, requestContext)
+	return []byte("payload")
+}
+
+func TestGeneratesThePayloadWithTheRequestContext(t *testing.T) {
+	loadGenerationResponse := make(chan report.LoadGenerationResponse, 4)
+	generator := &recordingPayloadGenerator{}
+	connectionSequence := &atomic.Uint64{}
+	requestId := NewRequestId()
+
+	var buffer bytes.Buffer
+	newWorker := func(index uint) Worker {
+		return Worker{
+			index:              index,
+			connection:         &BytesWriteCloser{bufio.NewWriter(&buffer)},
+			connectionId:       3,
+			connectionSequence: connectionSequence,
+			requestId:          requestId,
+			options: WorkerOptions{
+				maxDuration:            time.Minute,
+				payloadGenerator:       generator,
+				loadGenerationResponse: loadGenerationResponse,
+				startTime:              time.Now().Add(-time.Second),
+			},
+		}
+	}
+
+	assert.True(t, newWorker(0).sendRequest(time.Time{}, false))
+	assert.True(t, newWorker(0).sendRequest(time.Time{}, false))
+	assert.True(t, newWorker(1).sendRequest(time.Time{}, false))
+	assert.Equal(t, 3, len(generator.contexts))
+
+	lastContext := generator.contexts[2]
+	assert.Equal(t, uint64(3), lastContext.RequestId)
+	assert.Equal(t, 3, lastContext.ConnectionId)
+	assert.Equal(t, uint(1), lastContext.WorkerId)
+	assert.Equal(t, uint64(3), lastContext.ConnectionSequence)
+	assert.True(t, lastContext.Elapsed >= time.Second)
+}